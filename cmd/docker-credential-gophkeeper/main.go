@@ -0,0 +1,19 @@
+// Command docker-credential-gophkeeper — помощник учётных данных Docker,
+// хранящий пароли реестров в GophKeeper.
+//
+// Docker находит его по имени при "credsStore": "gophkeeper" в
+// ~/.docker/config.json и передаёт действие (get, store, erase, list) первым
+// аргументом. Настройки, профиль и локальная база — те же, что у gophkeeper.
+package main
+
+import (
+	"os"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+)
+
+func main() {
+	defer logger.Log.Sync()
+	cmd.ExecuteDockerCredential(os.Args[1:])
+}
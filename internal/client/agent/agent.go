@@ -0,0 +1,341 @@
+// Package agent реализует gophkeeper agent — фоновый процесс, который держит
+// локальную BoltDB открытой, а разблокированные ключи и копию записей — в
+// памяти, и обслуживает команды CLI через Unix-сокет.
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// SocketFile — имя сокета агента в каталоге времени выполнения клиента.
+const SocketFile = "agent.sock"
+
+// SyncFunc загружает записи пользователя с сервера.
+type SyncFunc func(ctx context.Context) ([]model.Record, error)
+
+// Options — параметры агента.
+type Options struct {
+	// TTL — время бездействия, после которого агент забывает ключи и
+	// копию записей. Разблокировка через агента задаёт свой таймаут.
+	TTL time.Duration
+	// SyncInterval — период синхронизации записей с сервером (0 — отключена).
+	SyncInterval time.Duration
+	// Sync загружает записи с сервера.
+	Sync SyncFunc
+}
+
+// Agent держит ключи и копию записей в памяти. Ключи попадают в агента при
+// разблокировке (или при запуске, если хранилище уже разблокировано), после
+// чего сессия Keyring закрывается: пока агент работает, ключи существуют
+// только в его памяти.
+type Agent struct {
+	store   service.Repository
+	keyring *service.Keyring
+	opts    Options
+	now     func() time.Time
+
+	mu         sync.Mutex
+	userKey    []byte
+	privateKey []byte
+	timeout    time.Duration
+	lastUsed   time.Time
+	records    map[int64]model.Record
+}
+
+func New(store service.Repository, keyring *service.Keyring, opts Options) *Agent {
+	return &Agent{store: store, keyring: keyring, opts: opts, now: time.Now}
+}
+
+// Run забирает уже открытую сессию Keyring и выполняет фоновые задачи
+// агента — блокировку по таймауту и периодическую синхронизацию — до
+// отмены ctx. При остановке ключи стираются.
+func (a *Agent) Run(ctx context.Context) error {
+	a.mu.Lock()
+	if err := a.adopt(a.opts.TTL); err != nil && !isLockedErr(err) {
+		logger.Log.Warn("agent: unlocked session not adopted", zap.Error(err))
+	}
+	a.mu.Unlock()
+
+	expire := time.NewTicker(time.Second)
+	defer expire.Stop()
+
+	var syncC <-chan time.Time
+	if a.opts.SyncInterval > 0 && a.opts.Sync != nil {
+		ticker := time.NewTicker(a.opts.SyncInterval)
+		defer ticker.Stop()
+		syncC = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.mu.Lock()
+			a.forget()
+			a.mu.Unlock()
+			return nil
+		case <-expire.C:
+			a.mu.Lock()
+			a.expire()
+			// файл сессии, открытой в обход агента, удаляется по истечении
+			// таймаута, а не при следующем обращении к ключам
+			if err := a.keyring.Expire(); err != nil {
+				logger.Log.Debug("agent: expire keyring session", zap.Error(err))
+			}
+			a.mu.Unlock()
+		case <-syncC:
+			if err := a.Sync(ctx); err != nil {
+				logger.Log.Warn("agent: sync failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Sync загружает записи с сервера и сохраняет их в BoltDB и в память.
+func (a *Agent) Sync(ctx context.Context) error {
+	if a.opts.Sync == nil {
+		return errors.New("sync is not configured")
+	}
+	records, err := a.opts.Sync(ctx)
+	if err != nil {
+		return err
+	}
+	if err := a.SaveRecords(records); err != nil {
+		return err
+	}
+	logger.Log.Debug("agent: synced records", zap.Int("count", len(records)))
+	return nil
+}
+
+func (a *Agent) All() ([]model.Record, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.loadRecords(); err != nil {
+		return nil, err
+	}
+	records := make([]model.Record, 0, len(a.records))
+	for _, r := range a.records {
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func (a *Agent) Get(id int64) (model.Record, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.loadRecords(); err != nil {
+		return model.Record{}, err
+	}
+	r, ok := a.records[id]
+	if !ok {
+		return model.Record{}, model.ErrRecordNotFound
+	}
+	return r, nil
+}
+
+func (a *Agent) SaveRecords(records []model.Record) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.store.SaveRecords(records); err != nil {
+		return err
+	}
+	// копия перечитывается целиком: SaveRecords дополняет, а не заменяет записи
+	a.records = nil
+	return nil
+}
+
+func (a *Agent) Delete(id int64) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.store.Delete(id); err != nil {
+		return err
+	}
+	delete(a.records, id)
+	return nil
+}
+
+func (a *Agent) Clear() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.forget()
+	return a.store.Clear()
+}
+
+func (a *Agent) Protect(passphrase []byte, userKey, privateKey string, timeout time.Duration) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.keyring.Protect(passphrase, userKey, privateKey, 0); err != nil {
+		return err
+	}
+	return a.adopt(timeout)
+}
+
+func (a *Agent) SetPassphrase(passphrase []byte, timeout time.Duration) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.keyring.SetPassphrase(passphrase, 0); err != nil {
+		return err
+	}
+	return a.adopt(timeout)
+}
+
+func (a *Agent) Unlock(passphrase []byte, timeout time.Duration) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.keyring.Unlock(passphrase, 0); err != nil {
+		return err
+	}
+	return a.adopt(timeout)
+}
+
+func (a *Agent) Lock() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.forget()
+	return a.keyring.Lock()
+}
+
+func (a *Agent) Status() (service.KeyringStatus, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.expire()
+	status, err := a.keyring.Status()
+	if err != nil {
+		return status, err
+	}
+	status.Unlocked = a.userKey != nil
+	status.ExpiresAt = time.Time{}
+	status.SessionDir = ""
+	if status.Unlocked && a.timeout > 0 {
+		status.ExpiresAt = a.lastUsed.Add(a.timeout)
+	}
+	return status, nil
+}
+
+func (a *Agent) UserKey() ([]byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.touch(); err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), a.userKey...), nil
+}
+
+func (a *Agent) PrivateKey() ([]byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.touch(); err != nil {
+		return nil, err
+	}
+	if a.privateKey == nil {
+		return nil, errors.New("private key not found")
+	}
+	return append([]byte(nil), a.privateKey...), nil
+}
+
+// adopt переносит ключи открытой сессии Keyring в память и закрывает сессию.
+func (a *Agent) adopt(timeout time.Duration) error {
+	userKey, err := a.keyring.UserKey()
+	if err != nil {
+		return err
+	}
+	privateKey, err := a.keyring.PrivateKey()
+	if err != nil {
+		// у пользователей без пары ключей закрытого ключа нет
+		privateKey = nil
+	}
+	if err := a.keyring.Lock(); err != nil {
+		return fmt.Errorf("close keyring session: %w", err)
+	}
+
+	a.forget()
+	a.userKey = userKey
+	a.privateKey = privateKey
+	a.timeout = timeout
+	a.lastUsed = a.now()
+	logger.Log.Info("agent: vault unlocked", zap.Duration("timeout", timeout))
+	return nil
+}
+
+// touch проверяет, что ключи в памяти, и продлевает их таймаут.
+func (a *Agent) touch() error {
+	a.expire()
+	if a.userKey == nil {
+		status, err := a.keyring.Status()
+		if err != nil {
+			return err
+		}
+		if !status.HasKeys {
+			return service.ErrNoKeys
+		}
+		if !status.Protected {
+			return service.ErrNotProtected
+		}
+		return service.ErrLocked
+	}
+	a.lastUsed = a.now()
+	return nil
+}
+
+// expire стирает ключи после таймаута разблокировки, а копию записей —
+// после TTL бездействия.
+func (a *Agent) expire() {
+	idle := a.now().Sub(a.lastUsed)
+	if a.userKey != nil && a.timeout > 0 && idle >= a.timeout {
+		a.forget()
+		logger.Log.Info("agent: vault auto-locked", zap.Duration("timeout", a.timeout))
+		return
+	}
+	if a.records != nil && a.opts.TTL > 0 && idle >= a.opts.TTL {
+		a.records = nil
+	}
+}
+
+// forget стирает ключи и копию записей из памяти.
+func (a *Agent) forget() {
+	clear(a.userKey)
+	clear(a.privateKey)
+	a.userKey = nil
+	a.privateKey = nil
+	a.records = nil
+}
+
+func (a *Agent) loadRecords() error {
+	a.lastUsed = a.now()
+	if a.records != nil {
+		return nil
+	}
+	records, err := a.store.All()
+	if err != nil {
+		return err
+	}
+	a.records = make(map[int64]model.Record, len(records))
+	for _, r := range records {
+		a.records[r.ID] = r
+	}
+	return nil
+}
+
+func isLockedErr(err error) bool {
+	return errors.Is(err, service.ErrLocked) || errors.Is(err, service.ErrNoKeys) || errors.Is(err, service.ErrNotProtected)
+}
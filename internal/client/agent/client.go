@@ -0,0 +1,154 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// Client обращается к агенту через Unix-сокет. Реализует service.Repository
+// и service.KeyStore, поэтому команды CLI при запущенном агенте работают
+// без открытия BoltDB.
+type Client struct {
+	http *http.Client
+}
+
+// Dial подключается к агенту и проверяет, что он отвечает.
+func Dial(path string) (*Client, error) {
+	c := &Client{http: &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		},
+		Timeout: time.Minute,
+	}}
+	if _, err := c.Status(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// remoteError — ошибка агента с исходным текстом; известные ошибки
+// сервиса доступны через errors.Is.
+type remoteError struct {
+	msg string
+	err error
+}
+
+func (e *remoteError) Error() string { return e.msg }
+func (e *remoteError) Unwrap() error { return e.err }
+
+func (c *Client) do(method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		raw, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequest(method, "http://agent"+path, body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("agent: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var e errorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&e); err != nil {
+			return fmt.Errorf("agent: %s", resp.Status)
+		}
+		return &remoteError{msg: e.Error, err: codeErrors[e.Code]}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *Client) All() ([]model.Record, error) {
+	var records []model.Record
+	err := c.do(http.MethodGet, "/records", nil, &records)
+	return records, err
+}
+
+func (c *Client) Get(id int64) (model.Record, error) {
+	var record model.Record
+	err := c.do(http.MethodGet, "/records/"+strconv.FormatInt(id, 10), nil, &record)
+	return record, err
+}
+
+func (c *Client) SaveRecords(records []model.Record) error {
+	return c.do(http.MethodPut, "/records", records, nil)
+}
+
+func (c *Client) Delete(id int64) error {
+	return c.do(http.MethodDelete, "/records/"+strconv.FormatInt(id, 10), nil, nil)
+}
+
+func (c *Client) Clear() error {
+	return c.do(http.MethodPost, "/clear", nil, nil)
+}
+
+// Sync просит агента синхронизировать записи с сервером.
+func (c *Client) Sync() error {
+	return c.do(http.MethodPost, "/sync", nil, nil)
+}
+
+// Stop останавливает агента.
+func (c *Client) Stop() error {
+	return c.do(http.MethodPost, "/stop", nil, nil)
+}
+
+func (c *Client) Protect(passphrase []byte, userKey, privateKey string, timeout time.Duration) error {
+	return c.do(http.MethodPost, "/protect", keysRequest{Passphrase: passphrase, UserKey: userKey, PrivateKey: privateKey, Timeout: timeout}, nil)
+}
+
+func (c *Client) SetPassphrase(passphrase []byte, timeout time.Duration) error {
+	return c.do(http.MethodPost, "/set-passphrase", keysRequest{Passphrase: passphrase, Timeout: timeout}, nil)
+}
+
+func (c *Client) Unlock(passphrase []byte, timeout time.Duration) error {
+	return c.do(http.MethodPost, "/unlock", keysRequest{Passphrase: passphrase, Timeout: timeout}, nil)
+}
+
+func (c *Client) Lock() error {
+	return c.do(http.MethodPost, "/lock", nil, nil)
+}
+
+func (c *Client) Status() (service.KeyringStatus, error) {
+	var status service.KeyringStatus
+	err := c.do(http.MethodGet, "/status", nil, &status)
+	return status, err
+}
+
+func (c *Client) UserKey() ([]byte, error) {
+	var resp keyResponse
+	err := c.do(http.MethodGet, "/keys/user", nil, &resp)
+	return resp.Key, err
+}
+
+func (c *Client) PrivateKey() ([]byte, error) {
+	var resp keyResponse
+	err := c.do(http.MethodGet, "/keys/private", nil, &resp)
+	return resp.Key, err
+}
+
+var _ service.Repository = (*Client)(nil)
+var _ service.KeyStore = (*Client)(nil)
+var _ service.KeyStore = (*Agent)(nil)
@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+var ErrAlreadyRunning = errors.New("agent is already running")
+
+// Listen создаёт Unix-сокет агента с правами 0600. Сокет, оставшийся от
+// завершившегося агента, удаляется. Принятые соединения проверяются по
+// учётным данным процесса-клиента: обслуживаются только процессы того же
+// пользователя.
+func Listen(path string) (net.Listener, error) {
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return nil, ErrAlreadyRunning
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("remove stale socket: %w", err)
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return &peerListener{Listener: l, uid: os.Getuid()}, nil
+}
+
+// peerListener отклоняет соединения процессов другого пользователя.
+type peerListener struct {
+	net.Listener
+	uid int
+}
+
+func (l *peerListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		uid, err := peerUID(conn)
+		if err != nil {
+			logger.Log.Warn("agent: peer credentials unavailable", zap.Error(err))
+			conn.Close()
+			continue
+		}
+		if uid != l.uid {
+			logger.Log.Warn("agent: connection from another user rejected", zap.Int("uid", uid))
+			conn.Close()
+			continue
+		}
+		return conn, nil
+	}
+}
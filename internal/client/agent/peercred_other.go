@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+
+package agent
+
+import (
+	"net"
+	"os"
+)
+
+// peerUID на платформах без SO_PEERCRED полагается на права сокета (0600)
+// и каталога времени выполнения (0700): подключиться к сокету может только
+// его владелец.
+func peerUID(conn net.Conn) (int, error) {
+	return os.Getuid(), nil
+}
@@ -0,0 +1,167 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Коды ошибок ответа агента: по ним клиент восстанавливает ошибки сервиса.
+const (
+	codeLocked          = "locked"
+	codeNotProtected    = "not_protected"
+	codeNoKeys          = "no_keys"
+	codeWrongPassphrase = "wrong_passphrase"
+	codeNotFound        = "not_found"
+)
+
+var codeErrors = map[string]error{
+	codeLocked:          service.ErrLocked,
+	codeNotProtected:    service.ErrNotProtected,
+	codeNoKeys:          service.ErrNoKeys,
+	codeWrongPassphrase: service.ErrWrongPassphrase,
+	codeNotFound:        model.ErrRecordNotFound,
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
+}
+
+// keysRequest — тело запросов protect, set-passphrase и unlock.
+type keysRequest struct {
+	Passphrase []byte        `json:"passphrase"`
+	UserKey    string        `json:"user_key,omitempty"`
+	PrivateKey string        `json:"private_key,omitempty"`
+	Timeout    time.Duration `json:"timeout"`
+}
+
+type keyResponse struct {
+	Key []byte `json:"key"`
+}
+
+// Handler возвращает HTTP-обработчик API агента. stop вызывается по
+// запросу POST /stop.
+func Handler(a *Agent, stop func()) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /records", func(w http.ResponseWriter, r *http.Request) {
+		records, err := a.All()
+		respond(w, records, err)
+	})
+	mux.HandleFunc("GET /records/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		record, err := a.Get(id)
+		respond(w, record, err)
+	})
+	mux.HandleFunc("DELETE /records/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		respond(w, nil, a.Delete(id))
+	})
+	mux.HandleFunc("PUT /records", func(w http.ResponseWriter, r *http.Request) {
+		var records []model.Record
+		if err := json.NewDecoder(r.Body).Decode(&records); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		respond(w, nil, a.SaveRecords(records))
+	})
+	mux.HandleFunc("POST /clear", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, nil, a.Clear())
+	})
+	mux.HandleFunc("POST /sync", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, nil, a.Sync(r.Context()))
+	})
+
+	mux.HandleFunc("GET /keys/user", func(w http.ResponseWriter, r *http.Request) {
+		key, err := a.UserKey()
+		respond(w, keyResponse{Key: key}, err)
+	})
+	mux.HandleFunc("GET /keys/private", func(w http.ResponseWriter, r *http.Request) {
+		key, err := a.PrivateKey()
+		respond(w, keyResponse{Key: key}, err)
+	})
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		status, err := a.Status()
+		respond(w, status, err)
+	})
+	mux.HandleFunc("POST /protect", withKeysRequest(func(req keysRequest) error {
+		return a.Protect(req.Passphrase, req.UserKey, req.PrivateKey, req.Timeout)
+	}))
+	mux.HandleFunc("POST /set-passphrase", withKeysRequest(func(req keysRequest) error {
+		return a.SetPassphrase(req.Passphrase, req.Timeout)
+	}))
+	mux.HandleFunc("POST /unlock", withKeysRequest(func(req keysRequest) error {
+		return a.Unlock(req.Passphrase, req.Timeout)
+	}))
+	mux.HandleFunc("POST /lock", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, nil, a.Lock())
+	})
+
+	mux.HandleFunc("POST /stop", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, nil, nil)
+		stop()
+	})
+
+	return mux
+}
+
+func withKeysRequest(fn func(req keysRequest) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req keysRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		err := fn(req)
+		clear(req.Passphrase)
+		respond(w, nil, err)
+	}
+}
+
+func respond(w http.ResponseWriter, body any, err error) {
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if body == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Log.Error("agent: write response", zap.Error(err))
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	resp := errorResponse{Error: err.Error()}
+	for code, target := range codeErrors {
+		if errors.Is(err, target) {
+			resp.Code = code
+			status = http.StatusConflict
+			if code == codeNotFound {
+				status = http.StatusNotFound
+			}
+			break
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -1,23 +1,43 @@
 // Package apiclient предоставляет обёртку над http.Client
 // для выполнения HTTP-запросов из клиентского CLI приложения.
 // Он стандартизирует таймауты, обработку ответа и формат возвращаемой структуры.
+//
+// Каждый запрос выполняется в клиентском спане OpenTelemetry, контекст которого
+// передаётся серверу в заголовке traceparent (W3C Trace Context).
 package apiclient
 
 import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/fatkulllin/gophkeeper/internal/client/models"
+	"github.com/fatkulllin/gophkeeper/pkg/cryptoutil"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
+const tracerName = "github.com/fatkulllin/gophkeeper/internal/client/apiclient"
+
 // APIClient инкапсулирует http.Client и обеспечивает единый
 // способ отправки запросов и получения ответов в формате models.Response.
 //
 // Клиент используется всеми сервисами CLI для общения с сервером.
 type ApiClient struct {
 	httpClient *http.Client
+	tlsConfig  *tls.Config
 }
 
 // NewAPIClient создаёт новый HTTP-клиент с заданным таймаутом.
@@ -28,6 +48,102 @@ func NewApiClient(waitTime time.Duration) *ApiClient {
 	}
 }
 
+// SetTimeout задаёт таймаут запроса к серверу; 0 оставляет прежний.
+func (client *ApiClient) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		client.httpClient.Timeout = timeout
+	}
+}
+
+// ConfigureTLS настраивает проверку сертификата сервера.
+// caFile — PEM-файл с доверенными корневыми сертификатами вместо системных.
+// pin — base64 SHA-256 от SubjectPublicKeyInfo сертификата сервера
+// (выводится сервером при загрузке сертификата). Если задан только pin,
+// цепочка сертификатов не проверяется: доверие основано на совпадении ключа,
+// что позволяет работать с самоподписанным сертификатом.
+func (client *ApiClient) ConfigureTLS(caFile string, pin string) error {
+	if caFile == "" && pin == "" {
+		return nil
+	}
+
+	tlsConfig := client.clientTLSConfig()
+
+	if caFile != "" {
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return fmt.Errorf("read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return fmt.Errorf("no certificates found in %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if pin != "" {
+		if raw, err := base64.StdEncoding.DecodeString(pin); err != nil || len(raw) != sha256.Size {
+			return fmt.Errorf("invalid pin-sha256: expected base64 SHA-256 digest")
+		}
+		// без CA цепочку проверяет только VerifyConnection по закреплённому ключу
+		tlsConfig.InsecureSkipVerify = caFile == ""
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			if !pinMatches(state, pin, caFile != "") {
+				return errors.New("server certificate does not match pinned key")
+			}
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// pinMatches сообщает, совпадает ли закреплённый ключ с сертификатом сервера.
+// Без проверки цепочки сравнивается только конечный сертификат: рукопожатие
+// доказывает владение лишь его ключом, а остальные сертификаты сервер может
+// прислать любые. Если цепочка проверена (verified), ключ может принадлежать
+// любому сертификату проверенной цепочки, например промежуточному CA.
+func pinMatches(state tls.ConnectionState, pin string, verified bool) bool {
+	if !verified {
+		return len(state.PeerCertificates) > 0 && cryptoutil.SPKIPin(state.PeerCertificates[0]) == pin
+	}
+	for _, chain := range state.VerifiedChains {
+		for _, cert := range chain {
+			if cryptoutil.SPKIPin(cert) == pin {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UseClientCertificate предъявляет серверу сертификат устройства из certFile
+// и keyFile, если сервер его запрашивает. Файлы читаются при каждом
+// TLS-рукопожатии; если их нет, соединение устанавливается без сертификата.
+func (client *ApiClient) UseClientCertificate(certFile, keyFile string) {
+	client.clientTLSConfig().GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return &tls.Certificate{}, nil
+			}
+			return nil, fmt.Errorf("load device certificate: %w", err)
+		}
+		return &cert, nil
+	}
+}
+
+// clientTLSConfig возвращает TLS-конфигурацию клиента, при первом вызове
+// устанавливая транспорт, который её использует.
+func (client *ApiClient) clientTLSConfig() *tls.Config {
+	if client.tlsConfig == nil {
+		client.tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = client.tlsConfig
+		client.httpClient.Transport = transport
+	}
+	return client.tlsConfig
+}
+
 // Do выполняет HTTP-запрос и возвращает структуру Response,
 // содержащую статус, заголовки, тело ответа и cookies.
 //
@@ -36,10 +152,29 @@ func NewApiClient(waitTime time.Duration) *ApiClient {
 //
 // В случае сетевой ошибки или ошибки чтения тела возвращает error.
 func (client *ApiClient) Do(req *http.Request) (*models.Response, error) {
+	ctx, span := otel.Tracer(tracerName).Start(req.Context(), req.Method+" "+req.URL.Path,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", req.URL.String()),
+		),
+	)
+	defer span.End()
+
+	req = req.WithContext(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	logger.Log.Debug("http request",
+		zap.String("method", req.Method),
+		zap.String("url", req.URL.String()),
+		zap.String("trace_id", span.SpanContext().TraceID().String()),
+	)
+
 	resp, err := client.httpClient.Do(req)
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return nil, fmt.Errorf("error sending request: %w", err)
 	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
 	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -2,37 +2,69 @@ package app
 
 import (
 	"fmt"
+	"path/filepath"
 
+	"github.com/fatkulllin/gophkeeper/internal/client/agent"
 	"github.com/fatkulllin/gophkeeper/internal/client/apiclient"
 	"github.com/fatkulllin/gophkeeper/internal/client/filemanager"
 	"github.com/fatkulllin/gophkeeper/internal/client/fs"
 	"github.com/fatkulllin/gophkeeper/internal/client/service"
 	"github.com/fatkulllin/gophkeeper/internal/client/store"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.uber.org/zap"
 )
 
-// InitApp создаёт и инициализирует все зависимости клиентского приложения.
+// InitApp создаёт и инициализирует все зависимости клиентского приложения
+// для каталога профиля appDir.
 // После успешного выполнения функция формирует экземпляр CliService,
-// через который CLI-команды взаимодействуют с API и локальным хранилищем.
-func InitApp() (*service.Service, error) {
+// через который CLI-команды взаимодействуют с API и локальным хранилищем,
+// и возвращает API-клиент для настройки TLS после разбора флагов.
+func InitApp(appDir string) (*service.Service, *apiclient.ApiClient, error) {
+	logger.Log.Debug("config dir", zap.String("dir", appDir))
 
-	appDir, err := fs.PrepareAppDir()
+	// Спаны клиента не экспортируются: провайдер нужен, чтобы у запросов
+	// появился trace id, который передаётся серверу в заголовке traceparent.
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	otel.SetTextMapPropagator(propagation.TraceContext{})
 
+	apiClient := apiclient.NewApiClient(10)
+	apiClient.UseClientCertificate(filepath.Join(appDir, service.DeviceCertFile), filepath.Join(appDir, service.DeviceKeyFile))
+	fm := filemanager.NewFileManager(appDir)
+	runtimeDir, err := fs.PrepareRuntimeDir(appDir)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	logger.Log.Debug("config dir", zap.String("dir", appDir))
+	// При запущенном агенте локальная копия и ключи берутся у него:
+	// BoltDB открыта агентом.
+	if client, err := agent.Dial(filepath.Join(runtimeDir, agent.SocketFile)); err == nil {
+		logger.Log.Debug("using agent", zap.String("dir", runtimeDir))
+		svc := service.NewService(apiClient, fm, client, client)
+		return svc, apiClient, nil
+	}
 
-	apiClient := apiclient.NewApiClient(10)
-	fm := filemanager.NewFileManager(appDir)
 	boltDB, err := store.NewBoltDB(appDir)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize local storage: %v", err)
+		return nil, nil, fmt.Errorf("failed to initialize local storage: %v", err)
+	}
+
+	keyring := service.NewKeyring(boltDB, filemanager.NewFileManager(runtimeDir))
+	if fs.RuntimeDirOnDisk() {
+		keyring.SessionOnDisk(runtimeDir)
 	}
+	svc := service.NewService(apiClient, fm, boltDB, keyring)
+	return svc, apiClient, nil
+}
 
-	svc := service.NewService(apiClient, fm, boltDB)
-	return svc, nil
+// AgentSocket возвращает путь к сокету агента профиля с каталогом appDir.
+func AgentSocket(appDir string) (string, error) {
+	runtimeDir, err := fs.PrepareRuntimeDir(appDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(runtimeDir, agent.SocketFile), nil
 }
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/agent"
+	"github.com/fatkulllin/gophkeeper/internal/client/app"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/profile"
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// NewCmdAgent возвращает команду запуска агента, который держит локальное
+// хранилище открытым и обслуживает остальные команды CLI.
+func NewCmdAgent(svc *service.Service) *cobra.Command {
+	var (
+		ttl          time.Duration
+		syncInterval time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Run the background agent that keeps the vault unlocked in memory",
+		Long: `Run the agent in the foreground. The agent opens the local database once,
+keeps the unlocked keys and a copy of the records in memory and serves the
+other commands over a Unix socket in $XDG_RUNTIME_DIR, so commands do not
+wait for the database lock and do not ask for the passphrase again.
+
+Only processes of the same user may connect (peer credentials are checked).
+The keys are forgotten after the unlock timeout of inactivity, on
+"gophkeeper lock" and when the agent stops; the record copy is dropped after
+--ttl of inactivity. With --sync-interval the agent periodically downloads
+records from the server.`,
+		Example: `  gophkeeper agent &
+  gophkeeper unlock
+  gophkeeper agent --sync-interval 5m --ttl 1h
+  gophkeeper agent stop`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keyring, ok := svc.Keyring.(*service.Keyring)
+			if !ok {
+				return agent.ErrAlreadyRunning
+			}
+			if ttl < 0 || syncInterval < 0 {
+				return errors.New("durations must not be negative")
+			}
+
+			p, err := profile.Current()
+			if err != nil {
+				return err
+			}
+			socket, err := app.AgentSocket(p.Dir)
+			if err != nil {
+				return err
+			}
+			l, err := agent.Listen(socket)
+			if err != nil {
+				return err
+			}
+			defer os.Remove(socket)
+
+			server := viper.GetString("server")
+			a := agent.New(svc.Store, keyring, agent.Options{
+				TTL:          ttl,
+				SyncInterval: syncInterval,
+				Sync: func(ctx context.Context) ([]model.Record, error) {
+					return fetchRecords(ctx, svc, server+"/api/records")
+				},
+			})
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			srv := &http.Server{Handler: agent.Handler(a, stop), ReadHeaderTimeout: 5 * time.Second}
+			group, ctx := errgroup.WithContext(ctx)
+			group.Go(func() error {
+				return a.Run(ctx)
+			})
+			group.Go(func() error {
+				if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					return err
+				}
+				return nil
+			})
+			group.Go(func() error {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				return srv.Shutdown(shutdownCtx)
+			})
+
+			logger.Log.Info("agent started", zap.String("socket", socket))
+			err = group.Wait()
+			logger.Log.Info("agent stopped")
+			return err
+		},
+	}
+	cmd.Flags().DurationVar(&ttl, "ttl", service.DefaultAutoLock, "drop the in-memory record copy and adopted keys after this period of inactivity (0 keeps them)")
+	cmd.Flags().DurationVar(&syncInterval, "sync-interval", 0, "download records from the server with this period (0 disables)")
+	cmd.AddCommand(newCmdAgentStop(svc))
+	return cmd
+}
+
+func newCmdAgentStop(svc *service.Service) *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the running agent",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, ok := svc.Store.(*agent.Client)
+			if !ok {
+				return errors.New("agent is not running")
+			}
+			return client.Stop()
+		},
+	}
+}
+
+// fetchRecords загружает личные и общие записи пользователя с сервера.
+func fetchRecords(ctx context.Context, svc *service.Service, url string) ([]model.Record, error) {
+	resp, err := svc.Record.Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	if err := exitcode.FromResponse(resp, "get records"); err != nil {
+		return nil, err
+	}
+	var records []model.Record
+	if err := json.Unmarshal(resp.Body, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// NewCmdAudit возвращает команду просмотра журнала аудита: действий пользователя
+// и обращений других пользователей к его записям.
+func NewCmdAudit(svc *service.Service) *cobra.Command {
+	var (
+		action   string
+		result   string
+		recordID int64
+		since    time.Duration
+		limit    int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Show audit log of logins and record access",
+		Example: `  gophkeeper audit --since 24h
+  gophkeeper audit --record 42 --action record.read`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := url.Values{}
+			if action != "" {
+				query.Set("action", action)
+			}
+			if result != "" {
+				query.Set("result", result)
+			}
+			if recordID != 0 {
+				query.Set("record_id", strconv.FormatInt(recordID, 10))
+			}
+			if since != 0 {
+				query.Set("since", time.Now().Add(-since).UTC().Format(time.RFC3339))
+			}
+			if limit != 0 {
+				query.Set("limit", strconv.Itoa(limit))
+			}
+
+			resp, err := svc.Audit.Get(cmd.Context(), viper.GetString("server")+"/api/audit?"+query.Encode())
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+			if err := exitcode.FromResponse(resp, "get audit log"); err != nil {
+				return err
+			}
+
+			var events []model.AuditEvent
+			if err := json.Unmarshal(resp.Body, &events); err != nil {
+				return fmt.Errorf("decode audit log: %w", err)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TIME\tACTION\tRESULT\tLOGIN\tRECORD\tIP\tUSER AGENT")
+			for _, e := range events {
+				record := ""
+				if e.RecordID != 0 {
+					record = strconv.FormatInt(e.RecordID, 10)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					e.CreatedAt.Local().Format(time.DateTime), e.Action, e.Result, e.Login, record, e.IP, e.UserAgent)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&action, "action", "", "filter by action (user.login, record.read, record.update, ...)")
+	cmd.Flags().StringVar(&result, "result", "", "filter by result (success, failure, denied)")
+	cmd.Flags().Int64Var(&recordID, "record", 0, "filter by record id")
+	cmd.Flags().DurationVar(&since, "since", 0, "show events newer than this duration (e.g. 24h)")
+	cmd.Flags().IntVar(&limit, "limit", 0, "maximum number of events")
+	return cmd
+}
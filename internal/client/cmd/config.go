@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/config"
+	"github.com/fatkulllin/gophkeeper/internal/client/profile"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdConfig возвращает команду просмотра и изменения файла настроек.
+func NewCmdConfig() *cobra.Command {
+	var global bool
+
+	cmds := &cobra.Command{
+		Use:   "config",
+		Short: "Show and change client settings",
+		Long: `Settings are read from ~/.config/gophkeeper/config.yaml (or --config) and,
+for a named profile, from the config.yaml of the profile on top of it.
+Command line flags and GOPHKEEPER_* environment variables take precedence.
+
+"set", "unset" and "edit" change the file of the current named profile, or
+the global file for the default profile, with --global or with --config.
+
+Keys:
+` + keysHelp(),
+	}
+	// файл настроек не читается и не проверяется: команда должна работать и
+	// с файлом, который не проходит проверку
+	cmds.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := bindConfig(cmd); err != nil {
+			return err
+		}
+		return initializeLogger()
+	}
+	cmds.PersistentFlags().BoolVar(&global, "global", false, "change the global config file instead of the profile one")
+
+	cmds.AddCommand(&cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the effective value of a setting",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			k, err := config.Lookup(args[0])
+			if err != nil {
+				return err
+			}
+			value, _, err := effectiveValue(cmd, k)
+			if err != nil {
+				return err
+			}
+			fmt.Println(value)
+			return nil
+		},
+	})
+
+	cmds.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List settings with their effective values and sources",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+			for _, k := range config.Keys {
+				value, source, err := effectiveValue(cmd, k)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", k.Name, value, source)
+			}
+			return w.Flush()
+		},
+	})
+
+	cmds.AddCommand(&cobra.Command{
+		Use:     "set <key> <value>",
+		Short:   "Validate and save a setting",
+		Example: "  gophkeeper config set server https://keeper.example.com\n  gophkeeper config set request-timeout 30s\n  gophkeeper config set auto-lock 1h --global",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := configTarget(global)
+			if err != nil {
+				return err
+			}
+			return config.Set(path, args[0], args[1])
+		},
+	})
+
+	cmds.AddCommand(&cobra.Command{
+		Use:   "unset <key>",
+		Short: "Remove a setting from the config file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := configTarget(global)
+			if err != nil {
+				return err
+			}
+			return config.Unset(path, args[0])
+		},
+	})
+
+	cmds.AddCommand(&cobra.Command{
+		Use:   "edit",
+		Short: "Edit the config file in $VISUAL or $EDITOR",
+		Long: `Open a copy of the config file in $VISUAL, $EDITOR or vi. The file is
+replaced only if the edited copy passes validation.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := configTarget(global)
+			if err != nil {
+				return err
+			}
+			return editConfig(path)
+		},
+	})
+
+	return cmds
+}
+
+// configTarget возвращает файл, который изменяют set, unset и edit.
+func configTarget(global bool) (string, error) {
+	if global || cfgFile != "" {
+		return globalConfigPath()
+	}
+	p, err := profile.Current()
+	if err != nil {
+		return "", err
+	}
+	if p.Name == profile.Default {
+		return globalConfigPath()
+	}
+	return p.SettingsPath(), nil
+}
+
+// effectiveValue возвращает действующее значение ключа и его источник.
+func effectiveValue(cmd *cobra.Command, k config.Key) (string, string, error) {
+	if f := cmd.Flags().Lookup(k.Name); f != nil && f.Changed {
+		return f.Value.String(), "flag", nil
+	}
+	env := "GOPHKEEPER_" + strings.ToUpper(strings.ReplaceAll(k.Name, "-", "_"))
+	if v, ok := os.LookupEnv(env); ok {
+		return v, env, nil
+	}
+
+	p, err := profile.Current()
+	if err != nil {
+		return "", "", err
+	}
+	if p.Name != profile.Default {
+		settings, err := config.Read(p.SettingsPath())
+		if err != nil {
+			return "", "", err
+		}
+		if v, ok := settings[k.Name]; ok {
+			return fmt.Sprint(v), p.SettingsPath(), nil
+		}
+	}
+
+	path, err := globalConfigPath()
+	if err != nil {
+		return "", "", err
+	}
+	settings, err := config.Read(path)
+	if err != nil {
+		return "", "", err
+	}
+	if v, ok := settings[k.Name]; ok {
+		return fmt.Sprint(v), path, nil
+	}
+	if f := cmd.Flags().Lookup(k.Name); f != nil {
+		return f.DefValue, "default", nil
+	}
+	return k.Default, "default", nil
+}
+
+func editConfig(path string) error {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		raw = []byte(config.Template())
+	} else if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	argv := append(strings.Fields(editor), tmp.Name())
+	c := exec.Command(argv[0], argv[1:]...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("editor: %w", err)
+	}
+
+	if _, err := config.ReadValid(tmp.Name()); err != nil {
+		return fmt.Errorf("changes not saved: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func keysHelp() string {
+	var b strings.Builder
+	for _, k := range config.Keys {
+		fmt.Fprintf(&b, "  %-16s %s\n", k.Name, k.Description)
+	}
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package device
+
+import (
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/output"
+	"github.com/fatkulllin/gophkeeper/internal/client/models"
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdDevice(svc *service.Service) *cobra.Command {
+	cmds := &cobra.Command{
+		Use:   "device",
+		Short: "Manage device certificates for mutual TLS authentication",
+	}
+	cmds.AddCommand(NewCmdEnroll(svc))
+	cmds.AddCommand(NewCmdList(svc))
+	cmds.AddCommand(NewCmdRevoke(svc))
+	cmds.AddCommand(NewCmdRequire(svc))
+	return cmds
+}
+
+// checkResponse преобразует ответ сервера с ошибкой в error.
+func checkResponse(resp *models.Response, action string) error {
+	return exitcode.FromResponse(resp, action)
+}
+
+// printJSON выводит ответ сервера в формате --output.
+func printJSON(body []byte) error {
+	return output.Print(body)
+}
@@ -0,0 +1,42 @@
+package device
+
+import (
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+func NewCmdEnroll(svc *service.Service) *cobra.Command {
+	enrollCmd := &cobra.Command{
+		Use:   "enroll",
+		Short: "Enroll this device and obtain a client certificate",
+		Long: `Generate a device key, send a certificate signing request to the server
+and store the issued certificate next to the local database.
+
+After enrollment log in again: the new session token is bound to the device
+certificate and is accepted only over connections presenting it.
+
+Examples:
+  gophkeeper device enroll --name laptop`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := viper.GetString("server") + "/api/devices/enroll"
+			resp, err := svc.Device.Enroll(cmd.Context(), url, viper.GetString("name"))
+
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+			if err := checkResponse(resp, "enroll device"); err != nil {
+				return err
+			}
+			logger.Log.Info("device enrolled, log in again to bind the session to it", zap.String("name", viper.GetString("name")))
+			return nil
+		},
+	}
+	enrollCmd.Flags().String("name", "", "device name")
+	enrollCmd.MarkFlagRequired("name")
+	return enrollCmd
+}
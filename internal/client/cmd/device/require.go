@@ -0,0 +1,41 @@
+package device
+
+import (
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+func NewCmdRequire(svc *service.Service) *cobra.Command {
+	requireCmd := &cobra.Command{
+		Use:   "require",
+		Short: "Require an enrolled device certificate to log in",
+		Long: `Enable or disable mandatory device certificates for your account.
+Enabling is only allowed from an enrolled device.
+
+Examples:
+  gophkeeper device require
+  gophkeeper device require --off`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy := model.DevicePolicy{RequireDevice: !viper.GetBool("off")}
+			url := viper.GetString("server") + "/api/devices/policy"
+			resp, err := svc.Device.SetPolicy(cmd.Context(), url, policy)
+
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+			if err := checkResponse(resp, "set device policy"); err != nil {
+				return err
+			}
+			logger.Log.Info("device policy updated", zap.Bool("require_device", policy.RequireDevice))
+			return nil
+		},
+	}
+	requireCmd.Flags().Bool("off", false, "allow logging in without a device certificate")
+	return requireCmd
+}
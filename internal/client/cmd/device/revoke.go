@@ -0,0 +1,40 @@
+package device
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+func NewCmdRevoke(svc *service.Service) *cobra.Command {
+	revokeCmd := &cobra.Command{
+		Use:   "revoke",
+		Short: "Revoke device certificate",
+		Long: `Add the device certificate to the server revocation list.
+Tokens bound to the device stop being accepted immediately.
+
+Examples:
+  gophkeeper device revoke --id 2`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := viper.GetString("server") + "/api/devices/" + strconv.Itoa(viper.GetInt("id"))
+			resp, err := svc.Device.Revoke(cmd.Context(), url)
+
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+			if err := checkResponse(resp, "revoke device"); err != nil {
+				return err
+			}
+			logger.Log.Info("device revoked", zap.Int("id", viper.GetInt("id")))
+			return nil
+		},
+	}
+	revokeCmd.Flags().Int("id", 0, "device id")
+	revokeCmd.MarkFlagRequired("id")
+	return revokeCmd
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/spf13/cobra"
+)
+
+// dockerScheme — схема метаданных записей с учётными данными реестров:
+// docker://registry.example.com.
+const dockerScheme = "docker"
+
+// errDockerCredentialsNotFound — сообщение, по которому Docker отличает
+// отсутствие учётных данных от ошибки помощника.
+var errDockerCredentialsNotFound = errors.New("credentials not found in native keychain")
+
+// dockerCredentials — учётные данные в протоколе помощника Docker.
+type dockerCredentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// NewCmdDockerCredential возвращает помощник учётных данных Docker. Он же
+// запускается как docker-credential-gophkeeper.
+func NewCmdDockerCredential(svc *service.Service) *cobra.Command {
+	return &cobra.Command{
+		Use:   "docker-credential <get|store|erase|list>",
+		Short: "Docker credential helper backed by the vault",
+		Long: `Implement the Docker credential helper protocol on stdin/stdout. Docker runs
+it as docker-credential-gophkeeper with "credsStore": "gophkeeper" in
+~/.docker/config.json.
+
+Credentials are login_password records with metadata docker://<registry host>,
+for example docker://ghcr.io or docker://index.docker.io for Docker Hub. "get"
+and "list" answer from the local vault; "store" creates or updates a record
+on the server only if the credentials changed; "erase" only forgets the local
+copy until the next sync and never deletes the record on the server.`,
+		Example: `  echo '{"credsStore": "gophkeeper"}' > ~/.docker/config.json
+  echo ghcr.io | docker-credential-gophkeeper get`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			err := dockerCredential(cmd, svc, args[0], cmd.InOrStdin(), out)
+			if err == nil {
+				return nil
+			}
+			// Docker читает сообщение об ошибке из stdout
+			fmt.Fprintln(out, err)
+			cmd.SilenceErrors = true
+			if errors.Is(err, errDockerCredentialsNotFound) {
+				return exitcode.New(exitcode.NotFound, err)
+			}
+			return err
+		},
+	}
+}
+
+func dockerCredential(cmd *cobra.Command, svc *service.Service, action string, in io.Reader, out io.Writer) error {
+	switch action {
+	case "get":
+		serverURL, err := readServerURL(in)
+		if err != nil {
+			return err
+		}
+		credentials, err := svc.Record.Credentials()
+		if err != nil {
+			return err
+		}
+		c, ok := service.MatchCredential(credentials, registryTarget(serverURL), "")
+		if !ok {
+			return errDockerCredentialsNotFound
+		}
+		return json.NewEncoder(out).Encode(dockerCredentials{ServerURL: serverURL, Username: c.Login, Secret: c.Password})
+	case "store":
+		var creds dockerCredentials
+		if err := json.NewDecoder(in).Decode(&creds); err != nil {
+			return fmt.Errorf("decode credentials: %w", err)
+		}
+		if creds.ServerURL == "" {
+			return errors.New("no credentials server URL")
+		}
+		return storeCredential(cmd.Context(), svc, registryTarget(creds.ServerURL), creds.Username, creds.Secret,
+			map[string]any{"server_url": creds.ServerURL})
+	case "erase":
+		serverURL, err := readServerURL(in)
+		if err != nil {
+			return err
+		}
+		return eraseCredential(svc, registryTarget(serverURL), "", "")
+	case "list":
+		credentials, err := svc.Record.Credentials()
+		if err != nil {
+			return err
+		}
+		list := make(map[string]string)
+		for _, c := range credentials {
+			if c.URL.Scheme != dockerScheme {
+				continue
+			}
+			serverURL, _ := c.Data["server_url"].(string)
+			if serverURL == "" {
+				serverURL = c.URL.Host
+			}
+			list[serverURL] = c.Login
+		}
+		return json.NewEncoder(out).Encode(list)
+	default:
+		return exitcode.New(exitcode.Usage, fmt.Errorf("unknown action %q: use get, store, erase or list", action))
+	}
+}
+
+// readServerURL читает адрес реестра, который Docker передаёт строкой.
+func readServerURL(in io.Reader) (string, error) {
+	raw, err := io.ReadAll(in)
+	if err != nil {
+		return "", err
+	}
+	serverURL := strings.TrimSpace(string(raw))
+	if serverURL == "" {
+		return "", errors.New("no credentials server URL")
+	}
+	return serverURL, nil
+}
+
+// registryTarget возвращает адрес docker://<хост> для адреса реестра:
+// https://index.docker.io/v1/, ghcr.io или registry.example.com:5000.
+func registryTarget(serverURL string) *url.URL {
+	host := serverURL
+	if u, err := url.Parse(serverURL); err == nil && u.Host != "" {
+		host = u.Host
+	} else if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+	return &url.URL{Scheme: dockerScheme, Host: strings.ToLower(host)}
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/passphrase"
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/pkg/redact"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// commandNotFound — код завершения, если команда не найдена, как в shell.
+const commandNotFound = 127
+
+// forwardedSignals передаются запущенному процессу.
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+
+// NewCmdExec возвращает команду запуска процесса с секретами в переменных
+// окружения.
+func NewCmdExec(svc *service.Service) *cobra.Command {
+	var envs []string
+	var remote, noMask bool
+
+	cmd := &cobra.Command{
+		Use:   "exec --env NAME=gk://<record>/<field> ... -- <command> [args...]",
+		Short: "Run a command with secrets in its environment",
+		Long: `Run a command with secrets from the vault injected into its environment.
+Secrets are set only in the environment of the child process, never in the
+shell. A reference gk://<record>/<field> names a record by id or by its
+metadata and a field of its data; values that are not references are passed
+as is.
+
+Secret values printed by the command to stdout or stderr are replaced with
+*****, values shorter than 4 characters are not masked. Masking connects the
+command's output to a pipe instead of the terminal, --no-mask keeps the
+terminal.
+
+Signals are forwarded to the command and gophkeeper exits with its exit code.
+Records are resolved from the local vault, --remote fetches them from the
+server.`,
+		Example: `  gophkeeper exec --env DB_PASSWORD=gk://123/password -- ./migrate
+  gophkeeper exec --env PGPASSWORD=gk://prod-db/password --env PGUSER=gk://prod-db/login -- psql -h db`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			values, secrets, err := resolveEnv(cmd, svc, envs, remote)
+			if err != nil {
+				return err
+			}
+			// BoltDB заблокирована, пока открыта: освобождаем её для вызовов
+			// gophkeeper из запущенного процесса.
+			if closer, ok := svc.Store.(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					return err
+				}
+			}
+
+			child := exec.Command(args[0], args[1:]...)
+			child.Env = childEnv(values)
+			child.Stdin = os.Stdin
+			child.Stdout = os.Stdout
+			child.Stderr = os.Stderr
+			if !noMask {
+				stdout := redact.NewWriter(os.Stdout, secrets)
+				stderr := redact.NewWriter(os.Stderr, secrets)
+				defer stdout.Flush()
+				defer stderr.Flush()
+				child.Stdout = stdout
+				child.Stderr = stderr
+			}
+
+			return runChild(cmd, child)
+		},
+	}
+	// аргументы после имени команды принадлежат ей, а не gophkeeper
+	cmd.Flags().SetInterspersed(false)
+	cmd.Flags().StringArrayVarP(&envs, "env", "e", nil, "environment variable NAME=VALUE, VALUE may be a gk://<record>/<field> reference (repeatable)")
+	cmd.Flags().BoolVar(&remote, "remote", false, "resolve references from records fetched from the server instead of local bbolt")
+	cmd.Flags().BoolVar(&noMask, "no-mask", false, "do not mask secret values in the command output")
+	return cmd
+}
+
+// resolveEnv разбирает пары NAME=VALUE и разрешает ссылки на секреты.
+// Возвращает переменные и значения секретов, которые нужно скрывать.
+func resolveEnv(cmd *cobra.Command, svc *service.Service, envs []string, remote bool) (map[string]string, []string, error) {
+	values := make(map[string]string, len(envs))
+	refs := false
+	for _, env := range envs {
+		name, value, ok := strings.Cut(env, "=")
+		if !ok || name == "" {
+			return nil, nil, exitcode.New(exitcode.Usage, fmt.Errorf("invalid --env %q: want NAME=VALUE", env))
+		}
+		values[name] = value
+		refs = refs || service.IsRef(value)
+	}
+	if !refs {
+		return values, nil, nil
+	}
+
+	secrets, err := loadSecrets(cmd, svc, remote)
+	if err != nil {
+		return nil, nil, err
+	}
+	var resolved []string
+	for name, value := range values {
+		if !service.IsRef(value) {
+			continue
+		}
+		secret, err := secrets.Resolve(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		values[name] = secret
+		resolved = append(resolved, secret)
+	}
+	return values, resolved, nil
+}
+
+// loadSecrets расшифровывает локальные записи или, при remote, записи,
+// загруженные с сервера.
+func loadSecrets(cmd *cobra.Command, svc *service.Service, remote bool) (*service.Secrets, error) {
+	if !remote {
+		return svc.Record.LocalSecrets()
+	}
+	records, err := fetchRecords(cmd.Context(), svc, viper.GetString("server")+"/api/records")
+	if err != nil {
+		return nil, err
+	}
+	return svc.Record.Secrets(records)
+}
+
+// childEnv возвращает окружение gophkeeper с переменными values. Парольная
+// фраза хранилища процессу не передаётся.
+func childEnv(values map[string]string) []string {
+	env := make([]string, 0, len(os.Environ())+len(values))
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := values[name]; ok || name == passphrase.EnvVar {
+			continue
+		}
+		env = append(env, kv)
+	}
+	for name, value := range values {
+		env = append(env, name+"="+value)
+	}
+	return env
+}
+
+// runChild запускает процесс, передаёт ему сигналы и возвращает ошибку с его
+// кодом завершения. Процесс, завершённый сигналом, даёт код 128+номер
+// сигнала, как в shell.
+func runChild(cmd *cobra.Command, child *exec.Cmd) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	if err := child.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return exitcode.New(commandNotFound, err)
+		}
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = child.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := child.Wait()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	// о завершении процесса сообщил он сам: ошибку не печатаем
+	cmd.SilenceErrors = true
+	code := exitErr.ExitCode()
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		code = 128 + int(status.Signal())
+	}
+	return exitcode.New(code, err)
+}
@@ -0,0 +1,161 @@
+// Package exitcode сопоставляет ошибки команд CLI кодам завершения
+// процесса, на которые могут опираться скрипты.
+package exitcode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/models"
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/spf13/cobra"
+)
+
+// Коды завершения CLI.
+const (
+	OK           = 0
+	Failure      = 1 // прочие ошибки
+	Usage        = 2 // неверные флаги и аргументы, ошибка проверки данных
+	NotFound     = 3 // запись, поле или другой объект не найден
+	Unauthorized = 4 // нет входа, токен истёк или нет прав (401, 403)
+	Network      = 5 // сервер недоступен или не ответил вовремя
+	Locked       = 6 // локальное хранилище заблокировано
+)
+
+// Error — ошибка с кодом завершения.
+type Error struct {
+	Code int
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+func (e *Error) Unwrap() error { return e.Err }
+
+// New возвращает ошибку err с кодом завершения code.
+func New(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// FromResponse возвращает ошибку для ответа сервера со статусом 4xx/5xx
+// (nil для успешного ответа). action описывает действие: "login",
+// "get record" и т.д. Ошибка в формате model.ErrorResponse превращается в
+// понятное сообщение, код завершения выбирается по коду ошибки; для
+// прочих ответов (прокси, старые версии сервера) — по статусу HTTP.
+func FromResponse(resp *models.Response, action string) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+
+	var body model.ErrorResponse
+	if json.Unmarshal(resp.Body, &body) == nil && body.Error.Code != "" {
+		return fromAPIError(body.Error, action)
+	}
+
+	err := fmt.Errorf("%s failed: %d %s: %s", action, resp.StatusCode, http.StatusText(resp.StatusCode), strings.TrimSpace(string(resp.Body)))
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return New(Unauthorized, err)
+	case http.StatusNotFound:
+		return New(NotFound, err)
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return New(Usage, err)
+	default:
+		return New(Failure, err)
+	}
+}
+
+// apiCodes — коды завершения для кодов ошибок API.
+var apiCodes = map[model.ErrorCode]int{
+	model.CodeNotFound:     NotFound,
+	model.CodeValidation:   Usage,
+	model.CodeUnauthorized: Unauthorized,
+	model.CodeForbidden:    Unauthorized,
+}
+
+func fromAPIError(e model.ErrorBody, action string) error {
+	message := e.Message
+	switch {
+	case e.Code == model.CodeUnauthorized && message != model.ErrIncorrectPassword.Error():
+		message += `; log in again with "gophkeeper user login"`
+	case e.Code == model.CodeInternal:
+		message += "; try again later or check the server log"
+	}
+	err := fmt.Errorf("%s failed: %s", action, message)
+
+	if code, ok := apiCodes[e.Code]; ok {
+		return New(code, err)
+	}
+	return New(Failure, err)
+}
+
+// Classify присваивает код завершения ошибке, у которой его ещё нет.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	var coded *Error
+	if errors.As(err, &coded) {
+		return err
+	}
+
+	var urlErr *url.Error
+	var netErr net.Error
+	switch {
+	case errors.Is(err, service.ErrLocked), errors.Is(err, service.ErrNotProtected), errors.Is(err, service.ErrWrongPassphrase):
+		return New(Locked, err)
+	case errors.Is(err, service.ErrNoKeys):
+		return New(Unauthorized, err)
+	case errors.Is(err, model.ErrRecordNotFound):
+		return New(NotFound, err)
+	case errors.As(err, &urlErr), errors.As(err, &netErr), errors.Is(err, context.DeadlineExceeded):
+		return New(Network, err)
+	default:
+		return New(Failure, err)
+	}
+}
+
+// Of возвращает код завершения для ошибки команды. Ошибки без кода
+// возвращает сам cobra при разборе флагов и аргументов — это Usage.
+func Of(err error) int {
+	if err == nil {
+		return OK
+	}
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return Usage
+}
+
+// Wrap классифицирует ошибки RunE и PersistentPreRunE команды cmd и всех
+// её подкоманд, чтобы их можно было отличить от ошибок разбора флагов.
+func Wrap(cmd *cobra.Command) {
+	if run := cmd.RunE; run != nil {
+		cmd.RunE = func(c *cobra.Command, args []string) error {
+			// флаги уже разобраны: справка по использованию нужна только
+			// при ошибках в аргументах
+			c.SilenceUsage = true
+			return Classify(run(c, args))
+		}
+	}
+	if preRun := cmd.PersistentPreRunE; preRun != nil {
+		cmd.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+			c.SilenceUsage = true
+			return Classify(preRun(c, args))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		Wrap(sub)
+	}
+}
@@ -0,0 +1,84 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatkulllin/gophkeeper/pkg/passgen"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Flags — флаги генерации пароля, общие для `generate` и `record add --generate`.
+type Flags struct {
+	password   passgen.Options
+	passphrase passgen.PassphraseOptions
+	diceware   bool
+}
+
+// AddFlags регистрирует флаги генерации в наборе флагов команды.
+func AddFlags(flags *pflag.FlagSet) *Flags {
+	f := &Flags{password: passgen.DefaultOptions, passphrase: passgen.DefaultPassphraseOptions}
+	flags.IntVar(&f.password.Length, "length", f.password.Length, "password length")
+	flags.BoolVar(&f.password.Lower, "lower", f.password.Lower, "use lowercase letters")
+	flags.BoolVar(&f.password.Upper, "upper", f.password.Upper, "use uppercase letters")
+	flags.BoolVar(&f.password.Digits, "digits", f.password.Digits, "use digits")
+	flags.BoolVar(&f.password.Symbols, "symbols", f.password.Symbols, "use symbols")
+	flags.StringVar(&f.password.Exclude, "exclude", "", "characters to exclude from the password")
+	flags.BoolVar(&f.password.ExcludeAmbiguous, "no-ambiguous", false, "exclude look-alike characters ("+passgen.Ambiguous+")")
+	flags.BoolVar(&f.diceware, "passphrase", false, "generate a diceware passphrase instead of a password")
+	flags.IntVar(&f.passphrase.Words, "words", f.passphrase.Words, "number of passphrase words")
+	flags.StringVar(&f.passphrase.Separator, "separator", f.passphrase.Separator, "passphrase word separator")
+	flags.BoolVar(&f.passphrase.Capitalize, "capitalize", false, "capitalize passphrase words")
+	flags.BoolVar(&f.passphrase.Number, "number", false, "append a digit to a random passphrase word")
+	return f
+}
+
+// Generate генерирует пароль или парольную фразу по значениям флагов.
+func (f *Flags) Generate() (string, error) {
+	if f.diceware {
+		return passgen.Passphrase(f.passphrase)
+	}
+	return passgen.Password(f.password)
+}
+
+// Entropy возвращает оценку энтропии генерируемого значения в битах.
+func (f *Flags) Entropy() float64 {
+	if f.diceware {
+		return f.passphrase.Entropy()
+	}
+	return f.password.Entropy()
+}
+
+// NewCmdGenerate возвращает команду генерации паролей и парольных фраз.
+func NewCmdGenerate() *cobra.Command {
+	var count int
+	var showEntropy bool
+
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate a password or a diceware passphrase",
+		Example: `  gophkeeper generate --length 32 --symbols=false
+  gophkeeper generate --no-ambiguous --exclude '"\'
+  gophkeeper generate --passphrase --words 7 --separator ' ' --capitalize`,
+		Args: cobra.NoArgs,
+	}
+	flags := AddFlags(cmd.Flags())
+	cmd.Flags().IntVarP(&count, "count", "n", 1, "number of values to generate")
+	cmd.Flags().BoolVar(&showEntropy, "entropy", false, "print estimated entropy to stderr")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		for range count {
+			value, err := flags.Generate()
+			if err != nil {
+				return err
+			}
+			fmt.Println(value)
+		}
+		if showEntropy {
+			fmt.Fprintf(os.Stderr, "entropy: %.0f bits\n", flags.Entropy())
+		}
+		return nil
+	}
+	return cmd
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// NewCmdGitCredential возвращает помощник учётных данных git.
+func NewCmdGitCredential(svc *service.Service) *cobra.Command {
+	return &cobra.Command{
+		Use:   "git-credential <get|store|erase>",
+		Short: "Git credential helper backed by the vault",
+		Long: `Implement the git credential helper protocol on stdin/stdout.
+
+Credentials are login_password records whose metadata is the URL of the
+service: https://github.com or, with credential.useHttpPath, a repository
+https://git.example.com/team/repo.git. "get" answers from the local vault
+without contacting the server; the record with the longest matching path
+wins. "store" creates or updates a record on the server only if the
+credentials changed. "erase" only forgets the local copy of the record with
+exactly this URL until the next sync; records are never deleted on the server.`,
+		Example: `  git config --global credential.helper '!gophkeeper git-credential'
+  printf 'protocol=https\nhost=github.com\n\n' | gophkeeper git-credential get`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action := args[0]
+			if action != "get" && action != "store" && action != "erase" {
+				// неизвестные действия помощник должен игнорировать
+				return nil
+			}
+			attrs, err := readCredentialAttrs(cmd.InOrStdin())
+			if err != nil {
+				return exitcode.New(exitcode.Usage, err)
+			}
+			target, err := attrs.target()
+			if err != nil {
+				return exitcode.New(exitcode.Usage, err)
+			}
+
+			switch action {
+			case "get":
+				return gitCredentialGet(cmd.OutOrStdout(), svc, target, attrs["username"])
+			case "store":
+				return storeCredential(cmd.Context(), svc, target, attrs["username"], attrs["password"], nil)
+			default:
+				return eraseCredential(svc, target, attrs["username"], attrs["password"])
+			}
+		},
+	}
+}
+
+// credentialAttrs — атрибуты протокола git credential.
+type credentialAttrs map[string]string
+
+// readCredentialAttrs читает строки key=value до пустой строки или конца
+// ввода.
+func readCredentialAttrs(r io.Reader) (credentialAttrs, error) {
+	attrs := credentialAttrs{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid credential line %q", line)
+		}
+		attrs[key] = value
+	}
+	return attrs, scanner.Err()
+}
+
+// target возвращает адрес, для которого запрошены учётные данные.
+func (a credentialAttrs) target() (*url.URL, error) {
+	if raw, ok := a["url"]; ok {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid url: %w", err)
+		}
+		u.User = nil
+		return u, nil
+	}
+	if a["protocol"] == "" || a["host"] == "" {
+		return nil, errors.New("protocol and host are required")
+	}
+	return &url.URL{Scheme: a["protocol"], Host: a["host"], Path: "/" + strings.TrimPrefix(a["path"], "/")}, nil
+}
+
+func gitCredentialGet(w io.Writer, svc *service.Service, target *url.URL, login string) error {
+	credentials, err := svc.Record.Credentials()
+	if err != nil {
+		return err
+	}
+	c, ok := service.MatchCredential(credentials, target, login)
+	if !ok {
+		// пустой ответ: git спросит следующий помощник или пользователя
+		return nil
+	}
+	logger.Log.Debug("credential found", zap.Int64("record", c.RecordID))
+	for _, attr := range [][2]string{{"username", c.Login}, {"password", c.Password}} {
+		if strings.ContainsAny(attr[1], "\n\x00") {
+			// как и git, не пишем значение, которое разорвёт протокол строк key=value
+			return fmt.Errorf("credential value for %s contains newline or NUL", attr[0])
+		}
+	}
+	_, err = fmt.Fprintf(w, "username=%s\npassword=%s\n", c.Login, c.Password)
+	return err
+}
+
+// storeCredential сохраняет учётные данные для target: обновляет пароль
+// подходящей записи с тем же логином или создаёт запись с адресом target в
+// метаданных и дополнительными полями данных extra. Если данные не
+// изменились, сервер не вызывается.
+func storeCredential(ctx context.Context, svc *service.Service, target *url.URL, login, password string, extra map[string]any) error {
+	if login == "" || password == "" {
+		return nil
+	}
+	credentials, err := svc.Record.Credentials()
+	if err != nil {
+		return err
+	}
+
+	server := viper.GetString("server")
+	c, ok := service.MatchCredential(credentials, target, login)
+	switch {
+	case ok && c.Password == password:
+		return nil
+	case ok:
+		c.Data["password"] = password
+		data, err := json.Marshal(c.Data)
+		if err != nil {
+			return err
+		}
+		raw := json.RawMessage(data)
+		resp, err := svc.Record.Update(ctx, server+"/api/records/"+strconv.FormatInt(c.RecordID, 10), model.RecordUpdateInput{Data: &raw})
+		if err != nil {
+			return fmt.Errorf("internal error: %w", err)
+		}
+		if err := exitcode.FromResponse(resp, "update record"); err != nil {
+			return err
+		}
+		logger.Log.Debug("credential updated", zap.Int64("record", c.RecordID))
+	default:
+		fields := map[string]any{"login": login, "password": password}
+		for k, v := range extra {
+			fields[k] = v
+		}
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		record := model.RecordInput{Type: model.TypeLoginPassword, Metadata: credentialMetadata(target), Data: data}
+		resp, err := svc.Record.Add(ctx, record, server+"/api/record")
+		if err != nil {
+			return fmt.Errorf("internal error: %w", err)
+		}
+		if err := exitcode.FromResponse(resp, "add record"); err != nil {
+			return err
+		}
+		logger.Log.Debug("credential added", zap.String("url", record.Metadata))
+	}
+
+	// сервер не возвращает созданную запись: обновляем локальную копию
+	records, err := fetchRecords(ctx, svc, server+"/api/records")
+	if err != nil {
+		return err
+	}
+	return svc.Record.SaveRecords(records)
+}
+
+// eraseCredential забывает отвергнутые учётные данные: удаляет локальную
+// копию записи с тем же адресом (без подбора по префиксу), логином и, если он
+// передан, паролем. Запись на сервере не удаляется: git и Docker вызывают
+// erase при любом отказе в доступе, а запись может хранить и другие поля.
+// Локальная копия вернётся при следующей синхронизации.
+func eraseCredential(svc *service.Service, target *url.URL, login, password string) error {
+	credentials, err := svc.Record.Credentials()
+	if err != nil {
+		return err
+	}
+	for _, c := range credentials {
+		if !sameCredentialURL(c.URL, target) || (login != "" && c.Login != login) || (password != "" && c.Password != password) {
+			continue
+		}
+		logger.Log.Debug("credential erased locally", zap.Int64("record", c.RecordID))
+		return svc.Record.DeleteLocal(c.RecordID)
+	}
+	return nil
+}
+
+// sameCredentialURL сообщает, что адреса совпадают с точностью до регистра
+// схемы и хоста и завершающего "/".
+func sameCredentialURL(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Host, b.Host) &&
+		strings.Trim(a.Path, "/") == strings.Trim(b.Path, "/")
+}
+
+// credentialMetadata возвращает адрес для метаданных новой записи: путь
+// сохраняется, только если git его передал.
+func credentialMetadata(target *url.URL) string {
+	u := url.URL{Scheme: target.Scheme, Host: target.Host, Path: strings.TrimSuffix(target.Path, "/")}
+	return u.String()
+}
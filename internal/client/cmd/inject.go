@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdInject возвращает команду подстановки секретов в шаблон файла.
+func NewCmdInject(svc *service.Service) *cobra.Command {
+	var in, out string
+	var remote bool
+
+	cmd := &cobra.Command{
+		Use:   "inject -i <template> [-o <file>]",
+		Short: "Render a template with secrets from the vault",
+		Long: `Render a Go text/template replacing references like {{ gk "prod-db" "password" }}
+with decrypted values. The first argument of gk is the record id or metadata,
+the second is a field of its data. Nothing is written if any reference cannot
+be resolved.
+
+The result is written to --out with 0600 permissions (replacing the file
+atomically) or to stdout. "-" reads the template from stdin. Records are
+resolved from the local vault, --remote fetches them from the server.`,
+		Example: `  gophkeeper inject -i app.env.tmpl -o app.env
+  echo 'DB_PASSWORD={{ gk "prod-db" "password" }}' | gophkeeper inject -i -`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			text, err := readTemplate(in)
+			if err != nil {
+				return err
+			}
+
+			// секреты расшифровываются только если шаблон на них ссылается
+			var secrets *service.Secrets
+			// запись можно указать и числом: {{ gk 123 "password" }}
+			gk := func(record any, field string) (string, error) {
+				if secrets == nil {
+					if secrets, err = loadSecrets(cmd, svc, remote); err != nil {
+						return "", err
+					}
+				}
+				return secrets.Lookup(fmt.Sprint(record), field)
+			}
+
+			tmpl, err := template.New(filepath.Base(in)).
+				Funcs(template.FuncMap{"gk": gk}).
+				Option("missingkey=error").
+				Parse(string(text))
+			if err != nil {
+				return exitcode.New(exitcode.Usage, err)
+			}
+			var rendered bytes.Buffer
+			// ошибка gk остаётся в цепочке ошибки шаблона и определяет код
+			// завершения
+			if err := tmpl.Execute(&rendered, nil); err != nil {
+				return err
+			}
+
+			if out == "" || out == "-" {
+				_, err := os.Stdout.Write(rendered.Bytes())
+				return err
+			}
+			return writeSecretFile(out, rendered.Bytes())
+		},
+	}
+	cmd.Flags().StringVarP(&in, "in", "i", "", `template file ("-" for stdin)`)
+	cmd.Flags().StringVarP(&out, "out", "o", "", "output file (default stdout)")
+	cmd.Flags().BoolVar(&remote, "remote", false, "resolve references from records fetched from the server instead of local bbolt")
+	_ = cmd.MarkFlagRequired("in")
+	return cmd
+}
+
+// readTemplate читает шаблон из файла path или из stdin при "-".
+func readTemplate(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// writeSecretFile записывает data в path с правами 0600. Файл заменяется
+// атомарно, поэтому при ошибке прежнее содержимое сохраняется.
+func writeSecretFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
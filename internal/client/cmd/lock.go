@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/passphrase"
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// NewCmdUnlock возвращает команду разблокировки локального хранилища
+// парольной фразой. Хранилище, сохранённое без парольной фразы, команда
+// защищает новой фразой.
+func NewCmdUnlock(svc *service.Service) *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "unlock",
+		Short: "Unlock the local vault with the passphrase",
+		Long: `Unlock the local vault: the user key and the private key stored in the
+local database are encrypted with a key derived from your local passphrase
+(scrypt). The vault locks again after --timeout of inactivity (0 disables
+auto-lock), on "gophkeeper lock", logout or reboot.
+
+The passphrase is read from the terminal or from GOPHKEEPER_VAULT_PASSPHRASE.`,
+		Example: `  gophkeeper unlock
+  gophkeeper unlock --timeout 1h`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("timeout") && viper.IsSet("auto-lock") {
+				timeout = viper.GetDuration("auto-lock")
+			}
+			if timeout < 0 {
+				return fmt.Errorf("timeout must not be negative")
+			}
+
+			status, err := svc.Keyring.Status()
+			if err != nil {
+				return err
+			}
+			if !status.HasKeys {
+				return service.ErrNoKeys
+			}
+
+			if !status.Protected {
+				fmt.Fprintln(cmd.ErrOrStderr(), "The local vault is not protected yet, choose a passphrase.")
+				secret, err := passphrase.Read("New passphrase: ", true)
+				if err != nil {
+					return err
+				}
+				if err := svc.Keyring.SetPassphrase(secret, timeout); err != nil {
+					return err
+				}
+			} else {
+				secret, err := passphrase.Read("Passphrase: ", false)
+				if err != nil {
+					return err
+				}
+				if err := svc.Keyring.Unlock(secret, timeout); err != nil {
+					if errors.Is(err, service.ErrWrongPassphrase) {
+						return err
+					}
+					return fmt.Errorf("unlock: %w", err)
+				}
+			}
+
+			passphrase.WarnSessionOnDisk(cmd.ErrOrStderr(), status.SessionDir)
+			if timeout > 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Unlocked, auto-lock after %s of inactivity\n", timeout)
+			} else {
+				fmt.Fprintln(cmd.OutOrStdout(), "Unlocked")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().DurationVar(&timeout, "timeout", service.DefaultAutoLock, "lock after this period of inactivity (0 disables auto-lock; default: auto-lock setting)")
+	return cmd
+}
+
+// NewCmdLock возвращает команду блокировки локального хранилища.
+func NewCmdLock(svc *service.Service) *cobra.Command {
+	var showStatus bool
+
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Lock the local vault",
+		Long: `Forget the unlocked session: local records cannot be decrypted until
+"gophkeeper unlock". With --status only print whether the vault is locked.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if showStatus {
+				status, err := svc.Keyring.Status()
+				if err != nil {
+					return err
+				}
+				switch {
+				case !status.HasKeys:
+					fmt.Fprintln(cmd.OutOrStdout(), "no local keys")
+				case !status.Protected:
+					fmt.Fprintln(cmd.OutOrStdout(), "not protected by a passphrase")
+				case !status.Unlocked:
+					fmt.Fprintln(cmd.OutOrStdout(), "locked")
+				case status.ExpiresAt.IsZero():
+					fmt.Fprintln(cmd.OutOrStdout(), "unlocked")
+				default:
+					fmt.Fprintf(cmd.OutOrStdout(), "unlocked until %s\n", status.ExpiresAt.Local().Format(time.DateTime))
+				}
+				return nil
+			}
+
+			if err := svc.Keyring.Lock(); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Locked")
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&showStatus, "status", false, "print the lock state instead of locking")
+	return cmd
+}
@@ -0,0 +1,62 @@
+package org
+
+import (
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewCmdCollections(svc *service.Service) *cobra.Command {
+	collectionsCmd := &cobra.Command{
+		Use:   "collections",
+		Short: "List organization collections",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resp, err := svc.Org.Get(cmd.Context(), orgURL("collections"))
+
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+			if err := checkResponse(resp, "list collections"); err != nil {
+				return err
+			}
+			return printJSON(resp.Body)
+		},
+	}
+	collectionsCmd.Flags().Int("org", 0, "organization id")
+	collectionsCmd.MarkFlagRequired("org")
+	return collectionsCmd
+}
+
+func NewCmdAddCollection(svc *service.Service) *cobra.Command {
+	addCollectionCmd := &cobra.Command{
+		Use:   "add-collection",
+		Short: "Create organization collection",
+		Long: `Create a shared collection in the organization. Its key is
+distributed to every member of the organization.
+
+Examples:
+  gophkeeper org add-collection --org 1 --name oncall`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			input := model.CollectionInput{Name: viper.GetString("name")}
+			resp, err := svc.Org.CreateCollection(cmd.Context(), orgURL("collections"), input)
+
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+			if err := checkResponse(resp, "create collection"); err != nil {
+				return err
+			}
+			logger.Log.Info("collection created successfully")
+			return printJSON(resp.Body)
+		},
+	}
+	addCollectionCmd.Flags().Int("org", 0, "organization id")
+	addCollectionCmd.Flags().String("name", "", "collection name")
+	addCollectionCmd.MarkFlagRequired("org")
+	addCollectionCmd.MarkFlagRequired("name")
+	return addCollectionCmd
+}
@@ -0,0 +1,40 @@
+package org
+
+import (
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewCmdCreate(svc *service.Service) *cobra.Command {
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create organization",
+		Long: `Create a new organization with a default shared collection.
+You become the owner of the organization.
+
+Examples:
+  gophkeeper org create --name ops`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			input := model.OrgInput{Name: viper.GetString("name")}
+			url := viper.GetString("server") + "/api/orgs"
+			resp, err := svc.Org.Create(cmd.Context(), url, input)
+
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+			if err := checkResponse(resp, "create organization"); err != nil {
+				return err
+			}
+			logger.Log.Info("organization created successfully")
+			return printJSON(resp.Body)
+		},
+	}
+	createCmd.Flags().String("name", "", "organization name")
+	createCmd.MarkFlagRequired("name")
+	return createCmd
+}
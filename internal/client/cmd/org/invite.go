@@ -0,0 +1,47 @@
+package org
+
+import (
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+func NewCmdInvite(svc *service.Service) *cobra.Command {
+	inviteCmd := &cobra.Command{
+		Use:   "invite",
+		Short: "Invite user to organization",
+		Long: `Add a user to the organization and grant access to its collections.
+
+Roles: owner, admin, member, read-only.
+
+Examples:
+  gophkeeper org invite --org 1 --username bob --role member`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			input := model.InviteInput{
+				Username: viper.GetString("username"),
+				Role:     model.OrgRole(viper.GetString("role")),
+			}
+			resp, err := svc.Org.Invite(cmd.Context(), orgURL("members"), input)
+
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+			if err := checkResponse(resp, "invite"); err != nil {
+				return err
+			}
+			logger.Log.Info("user invited successfully", zap.String("username", input.Username))
+			return nil
+		},
+	}
+	inviteCmd.Flags().Int("org", 0, "organization id")
+	inviteCmd.Flags().String("username", "", "username to invite")
+	inviteCmd.Flags().String("role", string(model.RoleMember), "role: owner, admin, member, read-only")
+	inviteCmd.MarkFlagRequired("org")
+	inviteCmd.MarkFlagRequired("username")
+	return inviteCmd
+}
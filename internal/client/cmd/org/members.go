@@ -0,0 +1,29 @@
+package org
+
+import (
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdMembers(svc *service.Service) *cobra.Command {
+	membersCmd := &cobra.Command{
+		Use:   "members",
+		Short: "List organization members",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resp, err := svc.Org.Get(cmd.Context(), orgURL("members"))
+
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+			if err := checkResponse(resp, "list members"); err != nil {
+				return err
+			}
+			return printJSON(resp.Body)
+		},
+	}
+	membersCmd.Flags().Int("org", 0, "organization id")
+	membersCmd.MarkFlagRequired("org")
+	return membersCmd
+}
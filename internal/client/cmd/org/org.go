@@ -0,0 +1,41 @@
+package org
+
+import (
+	"strconv"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/output"
+	"github.com/fatkulllin/gophkeeper/internal/client/models"
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewCmdOrg(svc *service.Service) *cobra.Command {
+	cmds := &cobra.Command{
+		Use:   "org",
+		Short: "Manage organizations and shared collections",
+	}
+	cmds.AddCommand(NewCmdCreate(svc))
+	cmds.AddCommand(NewCmdList(svc))
+	cmds.AddCommand(NewCmdInvite(svc))
+	cmds.AddCommand(NewCmdMembers(svc))
+	cmds.AddCommand(NewCmdCollections(svc))
+	cmds.AddCommand(NewCmdAddCollection(svc))
+	return cmds
+}
+
+// orgURL возвращает адрес ресурса организации, заданной флагом --org.
+func orgURL(resource string) string {
+	return viper.GetString("server") + "/api/orgs/" + strconv.Itoa(viper.GetInt("org")) + "/" + resource
+}
+
+// checkResponse преобразует ответ сервера с ошибкой в error.
+func checkResponse(resp *models.Response, action string) error {
+	return exitcode.FromResponse(resp, action)
+}
+
+// printJSON выводит ответ сервера в формате --output.
+func printJSON(body []byte) error {
+	return output.Print(body)
+}
@@ -0,0 +1,382 @@
+// Package output выводит результаты команд CLI в формате, выбранном флагом
+// --output, и извлекает из них поля по точечному пути (--field).
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// Форматы вывода.
+const (
+	JSON  = "json"
+	YAML  = "yaml"
+	Table = "table"
+	Env   = "env"
+	Raw   = "raw"
+)
+
+// Formats — допустимые значения --output.
+var Formats = []string{JSON, YAML, Table, Env, Raw}
+
+var ErrFieldNotFound = errors.New("field not found")
+
+// Validate проверяет формат вывода.
+func Validate(format string) error {
+	for _, f := range Formats {
+		if format == f {
+			return nil
+		}
+	}
+	return exitcode.New(exitcode.Usage, fmt.Errorf("unknown output format %q: use one of %s", format, strings.Join(Formats, ", ")))
+}
+
+// Print выводит value в stdout в формате --output (настройка output, по
+// умолчанию JSON) с учётом --field. Пустой ответ сервера не выводится.
+func Print(value any) error {
+	if raw, ok := value.([]byte); ok && len(bytes.TrimSpace(raw)) == 0 {
+		return nil
+	}
+	format := viper.GetString("output")
+	if format == "" {
+		format = JSON
+	}
+	return Write(os.Stdout, value, format, viper.GetString("field"))
+}
+
+// AddFieldFlag добавляет команде флаг --field.
+func AddFieldFlag(cmd *cobra.Command) {
+	cmd.Flags().String("field", "", "print only the value at a dotted path, e.g. data.password or 0.id")
+}
+
+// Write выводит value в формате format. value — любое значение,
+// сериализуемое в JSON, или сам JSON ([]byte, json.RawMessage). Если field
+// не пуст, выводится только значение по точечному пути: "data.password",
+// "0.id". Путь к массиву без индекса применяется к каждому элементу.
+func Write(w io.Writer, value any, format, field string) error {
+	if err := Validate(format); err != nil {
+		return err
+	}
+	v, err := normalize(value)
+	if err != nil {
+		return err
+	}
+	if field != "" {
+		v, err = Extract(v, field)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch format {
+	case YAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(numbers(v)); err != nil {
+			return err
+		}
+		return enc.Close()
+	case Table:
+		return writeTable(w, v)
+	case Env:
+		return writeEnv(w, v, field)
+	case Raw:
+		return writeRaw(w, v)
+	default:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(v)
+	}
+}
+
+// normalize приводит значение к дереву map[string]any, []any и скаляров.
+// Числа сохраняются как json.Number, чтобы идентификаторы не превращались
+// в 1.2e+06.
+func normalize(value any) (any, error) {
+	var raw []byte
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case json.RawMessage:
+		raw = v
+	default:
+		var err error
+		raw, err = json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		// не JSON: выводится как строка
+		return string(raw), nil
+	}
+	return v, nil
+}
+
+// Extract возвращает значение по точечному пути path. Путь продолжается
+// внутри данных записи, даже если они хранятся строкой (см. recordData).
+func Extract(v any, path string) (any, error) {
+	segments := strings.Split(path, ".")
+	for i, seg := range segments {
+		switch node := v.(type) {
+		case map[string]any:
+			next, ok := node[seg]
+			if !ok {
+				return nil, notFound(path)
+			}
+			v = recordData(seg, next)
+		case []any:
+			if idx, err := strconv.Atoi(seg); err == nil {
+				if idx < 0 || idx >= len(node) {
+					return nil, notFound(path)
+				}
+				v = node[idx]
+				continue
+			}
+			rest := strings.Join(segments[i:], ".")
+			values := make([]any, 0, len(node))
+			for _, item := range node {
+				value, err := Extract(item, rest)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, value)
+			}
+			return values, nil
+		default:
+			return nil, notFound(path)
+		}
+	}
+	return v, nil
+}
+
+func notFound(path string) error {
+	return exitcode.New(exitcode.NotFound, fmt.Errorf("%w: %s", ErrFieldNotFound, path))
+}
+
+// writeRaw выводит скаляр без кавычек, элементы массива — по одному в
+// строке, объекты — компактным JSON.
+func writeRaw(w io.Writer, v any) error {
+	if items, ok := v.([]any); ok {
+		for _, item := range items {
+			if _, err := fmt.Fprintln(w, scalar(item)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	_, err := fmt.Fprintln(w, scalar(v))
+	return err
+}
+
+var envUnsafe = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// writeEnv выводит значения в виде KEY='value' для eval или файлов .env.
+// Имена составляются из пути к значению: data.password → DATA_PASSWORD.
+func writeEnv(w io.Writer, v any, field string) error {
+	prefix := ""
+	if field != "" {
+		prefix = field
+	}
+	pairs := map[string]string{}
+	flatten(prefix, v, pairs)
+
+	keys := make([]string, 0, len(pairs))
+	for k := range pairs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		name := envUnsafe.ReplaceAllString(strings.ToUpper(k), "_")
+		if name == "" {
+			name = "VALUE"
+		}
+		if name[0] >= '0' && name[0] <= '9' {
+			name = "_" + name
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", name, shellQuote(pairs[k])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTable выводит массив объектов таблицей с колонками из скалярных
+// полей, объект — парами KEY VALUE, скаляр — как есть.
+func writeTable(w io.Writer, v any) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	switch node := v.(type) {
+	case []any:
+		columns := tableColumns(node)
+		if len(columns) == 0 {
+			for _, item := range node {
+				fmt.Fprintln(tw, scalar(item))
+			}
+			return tw.Flush()
+		}
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, item := range node {
+			row, _ := item.(map[string]any)
+			cells := make([]string, len(columns))
+			for i, c := range columns {
+				if value, ok := row[c]; ok {
+					cells[i] = scalar(value)
+				}
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+	case map[string]any:
+		pairs := map[string]string{}
+		flatten("", node, pairs)
+		keys := make([]string, 0, len(pairs))
+		for k := range pairs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Fprintln(tw, "KEY\tVALUE")
+		for _, k := range keys {
+			fmt.Fprintf(tw, "%s\t%s\n", k, pairs[k])
+		}
+	default:
+		fmt.Fprintln(tw, scalar(v))
+	}
+	return tw.Flush()
+}
+
+// tableColumns возвращает имена полей объектов массива; id идёт первым.
+func tableColumns(items []any) []string {
+	seen := map[string]bool{}
+	var columns []string
+	for _, item := range items {
+		row, ok := item.(map[string]any)
+		if !ok {
+			return nil
+		}
+		for k := range row {
+			if !seen[k] {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		if columns[i] == "id" || columns[j] == "id" {
+			return columns[i] == "id"
+		}
+		return columns[i] < columns[j]
+	})
+	return columns
+}
+
+// flatten раскладывает дерево в пары "путь.к.значению" → значение.
+// Данные записи, хранящиеся строкой, раскладываются тоже (см. recordData).
+func flatten(prefix string, v any, out map[string]string) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch node := v.(type) {
+	case map[string]any:
+		for k, value := range node {
+			flatten(join(k), recordData(k, value), out)
+		}
+	case []any:
+		for i, value := range node {
+			flatten(join(strconv.Itoa(i)), value, out)
+		}
+	default:
+		out[prefix] = scalar(v)
+	}
+}
+
+// recordData разбирает значение поля data, если это строка с JSON-объектом:
+// так хранятся данные записей старых версий. Другие строки, в том числе
+// значения внутри данных ("null", "{}", "[1,2]" в пароле), выводятся как есть.
+func recordData(key string, v any) any {
+	s, ok := v.(string)
+	if !ok || key != "data" {
+		return v
+	}
+	if parsed, err := normalize([]byte(s)); err == nil {
+		if object, ok := parsed.(map[string]any); ok {
+			return object
+		}
+	}
+	return v
+}
+
+// numbers заменяет json.Number на числа, иначе YAML выводит их строками.
+func numbers(v any) any {
+	switch value := v.(type) {
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		if f, err := value.Float64(); err == nil {
+			return f
+		}
+		return value.String()
+	case map[string]any:
+		out := make(map[string]any, len(value))
+		for k, item := range value {
+			out[k] = numbers(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(value))
+		for i, item := range value {
+			out[i] = numbers(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// scalar возвращает значение без кавычек; объекты и массивы — компактным JSON.
+func scalar(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case bool:
+		return strconv.FormatBool(value)
+	default:
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(raw)
+	}
+}
+
+// shellQuote заключает значение в одинарные кавычки для POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
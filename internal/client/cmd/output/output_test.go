@@ -0,0 +1,167 @@
+package output_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/output"
+)
+
+const record = `{"id":5,"metadata":"mail","data":{"login":"bob","password":"null","pin":"[1,2]","note":"{}"}}`
+
+// legacyRecord — запись, данные которой хранятся строкой с JSON.
+const legacyRecord = `{"id":6,"data":"{\"login\":\"alice\",\"password\":\"{}\"}"}`
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		path  string
+		want  any
+		err   error
+	}{
+		{name: "field of data", input: record, path: "data.login", want: "bob"},
+		{name: "json-like password is kept", input: record, path: "data.password", want: "null"},
+		{name: "json-like pin is kept", input: record, path: "data.pin", want: "[1,2]"},
+		{name: "number", input: record, path: "id", want: json.Number("5")},
+		{name: "index", input: `[` + record + `]`, path: "0.metadata", want: "mail"},
+		{name: "array without index", input: `[{"id":1},{"id":2}]`, path: "id", want: []any{json.Number("1"), json.Number("2")}},
+		{name: "legacy string data", input: legacyRecord, path: "data.login", want: "alice"},
+		{name: "legacy string data value", input: legacyRecord, path: "data.password", want: "{}"},
+		{name: "no descent into leaf values", input: record, path: "data.pin.0", err: output.ErrFieldNotFound},
+		{name: "missing field", input: record, path: "data.totp", err: output.ErrFieldNotFound},
+		{name: "index out of range", input: `[1]`, path: "1", err: output.ErrFieldNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := json.NewDecoder(bytes.NewReader([]byte(tt.input)))
+			dec.UseNumber()
+			var v any
+			if err := dec.Decode(&v); err != nil {
+				t.Fatalf("decode input: %v", err)
+			}
+
+			got, err := output.Extract(v, tt.path)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Extract(%q) error = %v, want %v", tt.path, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Extract(%q): %v", tt.path, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Extract(%q) = %#v, want %#v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		format string
+		field  string
+		want   string
+	}{
+		{
+			name:   "env keeps json-like values",
+			input:  record,
+			format: output.Env,
+			field:  "data",
+			want:   "DATA_LOGIN='bob'\nDATA_NOTE='{}'\nDATA_PASSWORD='null'\nDATA_PIN='[1,2]'\n",
+		},
+		{
+			name:   "env quotes single quotes",
+			input:  `{"data":{"password":"it's"}}`,
+			format: output.Env,
+			field:  "data.password",
+			want:   `DATA_PASSWORD='it'\''s'` + "\n",
+		},
+		{
+			name:   "env expands legacy string data",
+			input:  legacyRecord,
+			format: output.Env,
+			want:   "DATA_LOGIN='alice'\nDATA_PASSWORD='{}'\nID='6'\n",
+		},
+		{
+			name:   "table of an object",
+			input:  record,
+			format: output.Table,
+			want: "KEY            VALUE\n" +
+				"data.login     bob\n" +
+				"data.note      {}\n" +
+				"data.password  null\n" +
+				"data.pin       [1,2]\n" +
+				"id             5\n" +
+				"metadata       mail\n",
+		},
+		{
+			name:   "table of an array",
+			input:  `[{"metadata":"b","id":2},{"id":1}]`,
+			format: output.Table,
+			want:   "ID  METADATA\n2   b\n1   \n",
+		},
+		{
+			name:   "raw scalar",
+			input:  record,
+			format: output.Raw,
+			field:  "data.password",
+			want:   "null\n",
+		},
+		{
+			name:   "raw array",
+			input:  `[{"id":1},{"id":2}]`,
+			format: output.Raw,
+			field:  "id",
+			want:   "1\n2\n",
+		},
+		{
+			name:   "json",
+			input:  `{"id":1234567,"text":"<a>"}`,
+			format: output.JSON,
+			want:   "{\n  \"id\": 1234567,\n  \"text\": \"<a>\"\n}\n",
+		},
+		{
+			name:   "yaml",
+			input:  `{"id":1234567,"pin":"0042"}`,
+			format: output.YAML,
+			want:   "id: 1234567\npin: \"0042\"\n",
+		},
+		{
+			name:   "not json",
+			input:  "plain text",
+			format: output.Raw,
+			want:   "plain text\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := output.Write(&buf, []byte(tt.input), tt.format, tt.field); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Write output:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := output.Write(&buf, []byte(record), "xml", ""); err == nil {
+		t.Error("Write with unknown format succeeded")
+	}
+	if err := output.Write(&buf, []byte(record), output.JSON, "data.totp"); !errors.Is(err, output.ErrFieldNotFound) {
+		t.Errorf("Write with missing field: error = %v, want %v", err, output.ErrFieldNotFound)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Write wrote %q on error", buf.String())
+	}
+}
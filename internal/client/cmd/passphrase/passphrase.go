@@ -0,0 +1,84 @@
+// Package passphrase читает локальную парольную фразу хранилища.
+package passphrase
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// EnvVar — переменная окружения с парольной фразой для неинтерактивного
+// использования (скрипты, CI). Если она задана, терминал не опрашивается.
+const EnvVar = "GOPHKEEPER_VAULT_PASSPHRASE"
+
+// Read возвращает парольную фразу из EnvVar или запрашивает её на
+// управляющем терминале без эха. При confirm фраза запрашивается дважды.
+func Read(prompt string, confirm bool) ([]byte, error) {
+	if v, ok := os.LookupEnv(EnvVar); ok {
+		if v == "" {
+			return nil, fmt.Errorf("%s is empty", EnvVar)
+		}
+		return []byte(v), nil
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("no terminal to read passphrase, set %s: %w", EnvVar, err)
+	}
+	defer tty.Close()
+
+	first, err := readLine(tty, prompt)
+	if err != nil {
+		return nil, err
+	}
+	if len(first) == 0 {
+		return nil, errors.New("passphrase must not be empty")
+	}
+	if !confirm {
+		return first, nil
+	}
+
+	second, err := readLine(tty, "Repeat passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if string(first) != string(second) {
+		return nil, errors.New("passphrases do not match")
+	}
+	return first, nil
+}
+
+// ReadTerminal запрашивает секрет на управляющем терминале без эха, не
+// обращаясь к EnvVar. Используется для чужих парольных фраз, например ключей
+// SSH.
+func ReadTerminal(prompt string) ([]byte, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("no terminal to read passphrase: %w", err)
+	}
+	defer tty.Close()
+	return readLine(tty, prompt)
+}
+
+// WarnSessionOnDisk предупреждает, что ключ сессии разблокировки хранится в
+// каталоге dir вне tmpfs. При пустом dir ничего не выводит.
+func WarnSessionOnDisk(w io.Writer, dir string) {
+	if dir == "" {
+		return
+	}
+	fmt.Fprintf(w, "Warning: XDG_RUNTIME_DIR is not set, the session key is stored on disk in %s until the vault locks.\n", dir)
+	fmt.Fprintln(w, "Run \"gophkeeper agent\" to keep the keys in memory only.")
+}
+
+func readLine(tty *os.File, prompt string) ([]byte, error) {
+	fmt.Fprint(tty, prompt)
+	line, err := term.ReadPassword(int(tty.Fd()))
+	fmt.Fprintln(tty)
+	if err != nil {
+		return nil, fmt.Errorf("read passphrase: %w", err)
+	}
+	return line, nil
+}
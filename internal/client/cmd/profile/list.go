@@ -0,0 +1,46 @@
+package profilemanager
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/profile"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdList() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List profiles",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles, err := profile.List()
+			if err != nil {
+				return err
+			}
+			current, err := profile.Current()
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "\tNAME\tSERVER\tLOGGED IN")
+			for _, p := range profiles {
+				mark := ""
+				if p.Name == current.Name {
+					mark = "*"
+				}
+				server := "-"
+				if settings, err := p.Settings(); err == nil && settings["server"] != nil {
+					server = fmt.Sprint(settings["server"])
+				}
+				loggedIn := "no"
+				if p.LoggedIn() {
+					loggedIn = "yes"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", mark, p.Name, server, loggedIn)
+			}
+			return w.Flush()
+		},
+	}
+}
@@ -0,0 +1,20 @@
+package profilemanager
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewCmdProfile() *cobra.Command {
+	cmds := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage profiles: separate accounts, servers and local vaults",
+		Long: `Each profile has its own server address, token, local database, device
+certificate and settings. Select a profile for one command with --profile
+(or GOPHKEEPER_PROFILE) or make it the default with "profile use".
+A profile is created on first use, e.g. by "gophkeeper --profile work user login".`,
+	}
+	cmds.AddCommand(NewCmdList())
+	cmds.AddCommand(NewCmdUse())
+	cmds.AddCommand(NewCmdRemove())
+	return cmds
+}
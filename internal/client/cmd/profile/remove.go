@@ -0,0 +1,26 @@
+package profilemanager
+
+import (
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/profile"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdRemove() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove the profile with its token, local database and settings",
+		Long: `Remove the profile directory with its token, local database, device
+certificate and settings. Records on the server are not affected.
+Stop the agent of the profile first if it is running.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := profile.Remove(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Removed profile %s\n", args[0])
+			return nil
+		},
+	}
+}
@@ -0,0 +1,35 @@
+package profilemanager
+
+import (
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/profile"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdUse() *cobra.Command {
+	var create bool
+
+	cmd := &cobra.Command{
+		Use:   "use <name>",
+		Short: "Make the profile the default one",
+		Example: `  gophkeeper profile use work
+  gophkeeper profile use --create personal`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if create {
+				if _, err := profile.Open(name); err != nil {
+					return err
+				}
+			}
+			if err := profile.SetActive(name); err != nil {
+				return err
+			}
+			fmt.Printf("Using profile %s\n", name)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&create, "create", false, "create the profile if it does not exist")
+	return cmd
+}
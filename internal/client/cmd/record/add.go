@@ -3,46 +3,166 @@ package record
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/generate"
 	"github.com/fatkulllin/gophkeeper/internal/client/service"
 	"github.com/fatkulllin/gophkeeper/model"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/fatkulllin/gophkeeper/pkg/totp"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 func NewCmdAdd(svc *service.Service) *cobra.Command {
+	var generatePassword bool
+	var totpSecret string
+	var expiresAt string
+	var ephemeral bool
+
 	addCmd := &cobra.Command{
 		Use:   "add",
 		Short: "Create new record",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			record := model.RecordInput{
-				Type:     model.RecordType(viper.GetString("type")),
-				Metadata: viper.GetString("metadata"),
-				Data:     json.RawMessage(viper.GetString("data")),
-			}
-			url := viper.GetString("server") + "/api/record"
-			resp, err := svc.Record.Add(cmd.Context(), record, url)
+		Example: `  gophkeeper record add --type text --data '{"text":"..."}'
+  gophkeeper record add --type login_password --metadata github --data '{"login":"bob"}' --generate --length 24
+  gophkeeper record add --type text --metadata ci-token --data '{"text":"..."}' --expires-at 90d
+  gophkeeper record add --type login_password --metadata github --data '{"login":"bob","password":"..."}' --totp 'otpauth://totp/GitHub:bob?secret=JBSWY3DPEHPK3PXP'`,
+	}
+	generator := generate.AddFlags(addCmd.Flags())
 
+	addCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		recordType := model.RecordType(viper.GetString("type"))
+		data := json.RawMessage(viper.GetString("data"))
+		if generatePassword {
+			var err error
+			data, err = withGeneratedPassword(recordType, data, generator)
+			if err != nil {
+				return err
+			}
+		} else if len(data) == 0 && totpSecret == "" {
+			return fmt.Errorf(`required flag "data" not set`)
+		}
+		if totpSecret != "" {
+			var err error
+			data, err = withTOTP(recordType, data, totpSecret)
+			if err != nil {
+				return err
+			}
+		}
+		if recordType == model.TypeSSHKey {
+			var err error
+			data, err = withSSHKey(data)
 			if err != nil {
-				return fmt.Errorf("internal error: %v", err.Error())
+				return err
 			}
-			if resp.StatusCode >= 400 && resp.StatusCode <= 500 {
-				if resp.StatusCode == 401 {
-					return fmt.Errorf("unauthorized: %s", resp.Body)
-				}
-				return fmt.Errorf("registration failed: %s", string(resp.Body))
+		}
+
+		record := model.RecordInput{
+			CollectionID: viper.GetInt("collection"),
+			Type:         recordType,
+			Metadata:     viper.GetString("metadata"),
+			Data:         data,
+			Ephemeral:    ephemeral,
+		}
+		if expiresAt != "" {
+			t, err := parseExpiry(expiresAt, time.Now())
+			if err != nil {
+				return err
 			}
-			logger.Log.Info("record add successfully")
+			record.ExpiresAt = &t
+		} else if ephemeral {
+			return fmt.Errorf("--ephemeral requires --expires-at")
+		}
+		url := withOrg(viper.GetString("server") + "/api/record")
+		resp, err := svc.Record.Add(cmd.Context(), record, url)
+
+		if err != nil {
+			return fmt.Errorf("internal error: %w", err)
+		}
+		if err := exitcode.FromResponse(resp, "add record"); err != nil {
+			return err
+		}
+		logger.Log.Info("record add successfully")
 
-			return nil
-		},
+		return nil
 	}
 
 	addCmd.Flags().String("type", "", "record type")
 	addCmd.Flags().String("metadata", "", "record metadata")
 	addCmd.Flags().String("data", "", "json with data")
+	addCmd.Flags().Int("collection", 0, "organization collection id (default collection of --org if omitted)")
+	addCmd.Flags().BoolVar(&generatePassword, "generate", false, "generate the password of a login_password record (see gophkeeper generate)")
+	addCmd.Flags().StringVar(&expiresAt, "expires-at", "", "expiry of the secret: RFC 3339 time, YYYY-MM-DD or duration like 90d")
+	addCmd.Flags().BoolVar(&ephemeral, "ephemeral", false, "delete the record on the server when it expires")
+	addCmd.Flags().StringVar(&totpSecret, "totp", "", "otpauth:// URI or base32 TOTP secret of a login_password record")
 	addCmd.MarkFlagRequired("type")
-	addCmd.MarkFlagRequired("data")
 	return addCmd
 }
+
+// withGeneratedPassword записывает сгенерированный пароль в поле password
+// данных записи login_password. Остальные поля --data сохраняются.
+func withGeneratedPassword(recordType model.RecordType, data json.RawMessage, generator *generate.Flags) (json.RawMessage, error) {
+	if recordType != model.TypeLoginPassword {
+		return nil, fmt.Errorf("--generate is supported only for %s records", model.TypeLoginPassword)
+	}
+
+	fields := map[string]any{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, fmt.Errorf("--data must be a JSON object with --generate: %w", err)
+		}
+	}
+	if _, ok := fields["password"]; ok {
+		return nil, fmt.Errorf("--data already contains a password, remove it or --generate")
+	}
+
+	password, err := generator.Generate()
+	if err != nil {
+		return nil, err
+	}
+	fields["password"] = password
+	return json.Marshal(fields)
+}
+
+// withTOTP записывает секрет TOTP в поле totp данных записи login_password,
+// предварительно проверив, что по нему можно вычислить код.
+func withTOTP(recordType model.RecordType, data json.RawMessage, secret string) (json.RawMessage, error) {
+	if recordType != model.TypeLoginPassword {
+		return nil, fmt.Errorf("--totp is supported only for %s records", model.TypeLoginPassword)
+	}
+	if _, err := totp.Parse(secret); err != nil {
+		return nil, fmt.Errorf("--totp: %w", err)
+	}
+
+	fields := map[string]any{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, fmt.Errorf("--data must be a JSON object with --totp: %w", err)
+		}
+	}
+	if _, ok := fields["totp"]; ok {
+		return nil, fmt.Errorf("--data already contains totp, remove it or --totp")
+	}
+
+	fields["totp"] = strings.TrimSpace(secret)
+	return json.Marshal(fields)
+}
+
+// withSSHKey проверяет закрытый ключ записи ssh_key и заполняет по нему
+// открытый ключ и отпечаток.
+func withSSHKey(data json.RawMessage) (json.RawMessage, error) {
+	var key model.SSHKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("--data of %s record must be a JSON object: %w", model.TypeSSHKey, err)
+	}
+	if key.PrivateKey == "" {
+		return nil, fmt.Errorf("--data of %s record must contain private_key (or use gophkeeper ssh-key)", model.TypeSSHKey)
+	}
+	key, err := service.NewSSHKeyData([]byte(key.PrivateKey), key.Comment, key.Confirm)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(key)
+}
@@ -0,0 +1,76 @@
+package record
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/pkg/osc52"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdCopy(svc *service.Service) *cobra.Command {
+	var field string
+	var clearAfter time.Duration
+
+	copyCmd := &cobra.Command{
+		Use:   "copy <id>",
+		Short: "Copy a record field to the terminal clipboard (OSC 52)",
+		Long: `Copy a single field of a local record to the clipboard of the terminal
+emulator using the OSC 52 escape sequence, which also works over SSH. The
+value is never printed. The clipboard is cleared after --clear-after
+(0 keeps the value); the command waits until then, Ctrl+C clears it at once.`,
+		Example: `  gophkeeper record copy 42
+  gophkeeper record copy 42 --field login --clear-after 0`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid record id %q", args[0])
+			}
+
+			value, err := svc.Record.Field(cmd.Context(), id, field)
+			if err != nil {
+				return err
+			}
+
+			// Последовательность пишется в управляющий терминал, а не в stdout,
+			// чтобы значение не попало в перенаправленный вывод.
+			tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+			if err != nil {
+				return fmt.Errorf("open terminal: %w", err)
+			}
+			defer tty.Close()
+
+			mux := osc52.DetectMultiplexer()
+			if err := osc52.Copy(tty, value, mux); err != nil {
+				return err
+			}
+			if clearAfter <= 0 {
+				fmt.Fprintf(os.Stderr, "copied %s of record %d to clipboard\n", field, id)
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "copied %s of record %d to clipboard, clearing in %s\n", field, id, clearAfter)
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			ctx, cancel := context.WithTimeout(ctx, clearAfter)
+			defer cancel()
+			<-ctx.Done()
+
+			if err := osc52.Clear(tty, mux); err != nil {
+				return err
+			}
+			fmt.Fprintln(os.Stderr, "clipboard cleared")
+			return nil
+		},
+	}
+	copyCmd.Flags().StringVar(&field, "field", "password", "data field to copy")
+	copyCmd.Flags().DurationVar(&clearAfter, "clear-after", 45*time.Second, "clear the clipboard after this timeout (0 to keep)")
+	return copyCmd
+}
@@ -1,10 +1,10 @@
 package record
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/output"
 	"github.com/fatkulllin/gophkeeper/internal/client/service"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
 	"github.com/spf13/cobra"
@@ -22,24 +22,13 @@ func NewCmdDelete(svc *service.Service) *cobra.Command {
 			resp, err := svc.Record.Delete(cmd.Context(), url)
 
 			if err != nil {
-				return fmt.Errorf("internal error: %v", err.Error())
+				return fmt.Errorf("internal error: %w", err)
 			}
-			if resp.StatusCode >= 400 && resp.StatusCode <= 500 {
-				if resp.StatusCode == 401 {
-					return fmt.Errorf("unauthorized: %s", resp.Body)
-				}
-				return fmt.Errorf("registration failed: %s", string(resp.Body))
+			if err := exitcode.FromResponse(resp, "delete record"); err != nil {
+				return err
 			}
 			logger.Log.Info("delete record successfully", zap.String("record id", idRecord))
-			var pretty bytes.Buffer
-			err = json.Indent(&pretty, resp.Body, "", "  ")
-			if err != nil {
-				fmt.Println(string(resp.Body))
-			} else {
-				fmt.Println(pretty.String())
-			}
-
-			return nil
+			return output.Print(resp.Body)
 		},
 	}
 	addCmd.Flags().String("id", "", "id record")
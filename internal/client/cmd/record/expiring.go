@@ -0,0 +1,82 @@
+package record
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/output"
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/pkg/duration"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewCmdExpiring(svc *service.Service) *cobra.Command {
+	var within string
+	var remote bool
+
+	expiringCmd := &cobra.Command{
+		Use:   "expiring",
+		Short: "List records that expire soon or have expired",
+		Example: `  gophkeeper record expiring
+  gophkeeper record expiring --within 7d
+  gophkeeper record expiring --within 30d --remote`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			window, err := duration.Parse(within)
+			if err != nil || window <= 0 {
+				return fmt.Errorf("invalid --within %q: want a positive duration like 30d", within)
+			}
+
+			if remote {
+				query := url.Values{"expiring_within": {within}}
+				if org := viper.GetInt("org"); org != 0 {
+					query.Set("org", strconv.Itoa(org))
+				}
+				resp, err := svc.Record.Get(cmd.Context(), viper.GetString("server")+"/api/records?"+query.Encode())
+				if err != nil {
+					return fmt.Errorf("internal error: %w", err)
+				}
+				if err := exitcode.FromResponse(resp, "get records"); err != nil {
+					return err
+				}
+				return output.Print(resp.Body)
+			}
+
+			now := time.Now()
+			records, err := svc.Record.Expiring(viper.GetInt("org"), window, now)
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tTYPE\tMETADATA\tEXPIRES\tSTATUS")
+			for _, r := range records {
+				status := "expires in " + humanDays(r.ExpiresAt.Sub(now))
+				if !r.ExpiresAt.After(now) {
+					status = "expired " + humanDays(now.Sub(*r.ExpiresAt)) + " ago"
+				}
+				if r.Ephemeral {
+					status += ", ephemeral"
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", r.ID, r.Type, r.Metadata, r.ExpiresAt.Local().Format(time.DateTime), status)
+			}
+			return w.Flush()
+		},
+	}
+	expiringCmd.Flags().StringVar(&within, "within", "30d", "time window: 30d, 2w, 12h")
+	expiringCmd.Flags().BoolVar(&remote, "remote", false, "ask the server instead of local bbolt")
+	return expiringCmd
+}
+
+// humanDays округляет интервал до дней, а меньше суток — до часов.
+func humanDays(d time.Duration) string {
+	if d < duration.Day {
+		return fmt.Sprintf("%dh", int(d.Round(time.Hour)/time.Hour))
+	}
+	return fmt.Sprintf("%dd", int(d.Round(duration.Day)/duration.Day))
+}
@@ -0,0 +1,25 @@
+package record
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/pkg/duration"
+)
+
+// parseExpiry разбирает срок действия записи: момент RFC 3339, дату
+// YYYY-MM-DD (начало дня по местному времени) или интервал от now (30d, 12h).
+func parseExpiry(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t.UTC(), nil
+	}
+	if d, err := duration.Parse(strings.TrimPrefix(s, "+")); err == nil && d > 0 {
+		return now.Add(d).UTC().Truncate(time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid expiry %q: want RFC 3339 time, YYYY-MM-DD or duration like 30d", s)
+}
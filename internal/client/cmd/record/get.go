@@ -1,10 +1,10 @@
 package record
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/output"
 	"github.com/fatkulllin/gophkeeper/internal/client/service"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
 	"github.com/spf13/cobra"
@@ -16,6 +16,9 @@ func NewCmdGet(svc *service.Service) *cobra.Command {
 	getCmd := &cobra.Command{
 		Use:   "get",
 		Short: "Get record",
+		Example: `  gophkeeper record get --id 5
+  gophkeeper record get --id 5 --field data.password --output raw
+  gophkeeper record get --id 5 --output env --field data`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			remote := viper.GetBool("remote")
 
@@ -24,60 +27,31 @@ func NewCmdGet(svc *service.Service) *cobra.Command {
 				resp, err := svc.Record.Get(cmd.Context(), url)
 
 				if err != nil {
-					return fmt.Errorf("internal error: %v", err.Error())
+					return fmt.Errorf("internal error: %w", err)
 				}
 
-				if resp.StatusCode >= 400 {
-					if resp.StatusCode == 401 {
-						return fmt.Errorf("unauthorized: %s", resp.Body)
-					}
-
-					return fmt.Errorf(
-						"failed to fetch records: status=%d body=%s",
-						resp.StatusCode,
-						resp.Body,
-					)
+				if err := exitcode.FromResponse(resp, "get record"); err != nil {
+					return err
 				}
 
 				logger.Log.Info("get record successfully")
 
-				var pretty bytes.Buffer
-				err = json.Indent(&pretty, resp.Body, "", "  ")
-				if err != nil {
-					fmt.Println(string(resp.Body))
-				} else {
-					fmt.Println(pretty.String())
-				}
-
-				return nil
+				return output.Print(resp.Body)
 			}
 			id := viper.GetInt64("id")
 			record, err := svc.Record.GetLocal(cmd.Context(), id)
 
 			if err != nil {
 				logger.Log.Error("", zap.Error(err))
-				return fmt.Errorf("internal error: %v", err.Error())
-			}
-
-			rawJSON, err := json.Marshal(record)
-			if err != nil {
-				logger.Log.Error("", zap.Error(err))
-				return fmt.Errorf("internal error: %v", err.Error())
-			}
-
-			var prettyJSON bytes.Buffer
-			err = json.Indent(&prettyJSON, rawJSON, "", "  ")
-			if err != nil {
-				fmt.Println(record)
-			} else {
-				fmt.Println(prettyJSON.String())
+				return fmt.Errorf("record %d: %w", id, err)
 			}
 
-			return nil
+			return output.Print(record)
 		},
 	}
 	getCmd.Flags().String("id", "", "id record")
 	getCmd.MarkFlagRequired("id")
 	getCmd.Flags().Bool("remote", false, "fetch records from server instead of local bbolt")
+	output.AddFieldFlag(getCmd)
 	return getCmd
 }
@@ -1,10 +1,10 @@
 package record
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/output"
 	"github.com/fatkulllin/gophkeeper/internal/client/service"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
 	"github.com/spf13/cobra"
@@ -16,60 +16,35 @@ func NewCmdGetAll(svc *service.Service) *cobra.Command {
 	getCmd := &cobra.Command{
 		Use:   "getall",
 		Short: "Get all records",
+		Example: `  gophkeeper record getall --output table
+  gophkeeper record getall --field metadata --output raw`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			remote := viper.GetBool("remote")
 
 			if remote {
-				url := viper.GetString("server") + "/api/records"
+				url := withOrg(viper.GetString("server") + "/api/records")
 				resp, err := svc.Record.Get(cmd.Context(), url)
 
 				if err != nil {
 					return fmt.Errorf("internal error: %w", err)
 				}
 
-				if resp.StatusCode >= 400 {
-					if resp.StatusCode == 401 {
-						return fmt.Errorf("unauthorized: %s", resp.Body)
-					}
-
-					return fmt.Errorf(
-						"failed to fetch records: status=%d body=%s",
-						resp.StatusCode,
-						resp.Body,
-					)
+				if err := exitcode.FromResponse(resp, "get records"); err != nil {
+					return err
 				}
 
 				logger.Log.Info("get all successfully")
-				var pretty bytes.Buffer
-				err = json.Indent(&pretty, resp.Body, "", "  ")
-				if err != nil {
-					fmt.Println(string(resp.Body))
-				} else {
-					fmt.Println(pretty.String())
-				}
-				return nil
+				return output.Print(resp.Body)
 			}
-			records, err := svc.Record.GetAll()
+			records, err := svc.Record.GetAll(viper.GetInt("org"))
 			if err != nil {
 				logger.Log.Error("", zap.Error(err))
-				return fmt.Errorf("internal error: %v", err.Error())
-			}
-			rawJSON, err := json.Marshal(records)
-			if err != nil {
-				logger.Log.Error("", zap.Error(err))
-				return fmt.Errorf("internal error: %v", err.Error())
-			}
-
-			var prettyJSON bytes.Buffer
-			err = json.Indent(&prettyJSON, rawJSON, "", "  ")
-			if err != nil {
-				fmt.Println(records)
-			} else {
-				fmt.Println(prettyJSON.String())
+				return fmt.Errorf("internal error: %w", err)
 			}
-			return nil
+			return output.Print(records)
 		},
 	}
 	getCmd.Flags().Bool("remote", false, "fetch records from server instead of local bbolt")
+	output.AddFieldFlag(getCmd)
 	return getCmd
 }
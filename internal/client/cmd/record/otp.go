@@ -0,0 +1,32 @@
+package record
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdOTP(svc *service.Service) *cobra.Command {
+	return &cobra.Command{
+		Use:     "otp <id>",
+		Short:   "Print current TOTP code of a login_password record",
+		Example: `  gophkeeper record otp 42`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid record id %q", args[0])
+			}
+
+			code, remaining, err := svc.Record.OTP(cmd.Context(), id, time.Now())
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s (valid for %s)\n", code, remaining)
+			return nil
+		},
+	}
+}
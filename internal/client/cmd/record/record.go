@@ -1,8 +1,11 @@
 package record
 
 import (
+	"strconv"
+
 	"github.com/fatkulllin/gophkeeper/internal/client/service"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 func NewCmdRecord(svc *service.Service) *cobra.Command {
@@ -10,11 +13,26 @@ func NewCmdRecord(svc *service.Service) *cobra.Command {
 		Use:   "record",
 		Short: "Manager record",
 	}
+	cmds.PersistentFlags().Int("org", 0, "organization id: work with records of organization collections")
 	cmds.AddCommand(NewCmdAdd(svc))
 	cmds.AddCommand(NewCmdGetAll(svc))
 	cmds.AddCommand(NewCmdGet(svc))
+	cmds.AddCommand(NewCmdOTP(svc))
+	cmds.AddCommand(NewCmdCopy(svc))
+	cmds.AddCommand(NewCmdExpiring(svc))
 	cmds.AddCommand(NewCmdDelete(svc))
 	cmds.AddCommand(NewCmdUpdate(svc))
 	cmds.AddCommand(NewCmdSync(svc))
+	cmds.AddCommand(NewCmdShare(svc))
+	cmds.AddCommand(NewCmdUnshare(svc))
+	cmds.AddCommand(NewCmdShares(svc))
 	return cmds
 }
+
+// withOrg добавляет к адресу запроса параметр организации, если задан флаг --org.
+func withOrg(url string) string {
+	if org := viper.GetInt("org"); org != 0 {
+		return url + "?org=" + strconv.Itoa(org)
+	}
+	return url
+}
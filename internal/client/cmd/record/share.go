@@ -0,0 +1,104 @@
+package record
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/output"
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+func NewCmdShare(svc *service.Service) *cobra.Command {
+	shareCmd := &cobra.Command{
+		Use:   "share",
+		Short: "Share record with another user",
+		Long: `Share a record with another user.
+
+Examples:
+  gophkeeper record share --id 5 --with bob
+  gophkeeper record share --id 5 --with bob --permission write`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			idRecord := viper.GetString("id")
+			input := model.ShareInput{
+				Username:   viper.GetString("with"),
+				Permission: model.SharePermission(viper.GetString("permission")),
+			}
+			url := viper.GetString("server") + "/api/records/" + idRecord + "/shares"
+			resp, err := svc.Record.Share(cmd.Context(), url, input)
+
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+			if err := exitcode.FromResponse(resp, "share"); err != nil {
+				return err
+			}
+			logger.Log.Info("share record successfully", zap.String("record id", idRecord), zap.String("with", input.Username))
+
+			return nil
+		},
+	}
+	shareCmd.Flags().String("id", "", "id record")
+	shareCmd.Flags().String("with", "", "username to share record with")
+	shareCmd.Flags().String("permission", string(model.PermissionRead), "access level: read or write")
+	shareCmd.MarkFlagRequired("id")
+	shareCmd.MarkFlagRequired("with")
+	return shareCmd
+}
+
+func NewCmdUnshare(svc *service.Service) *cobra.Command {
+	unshareCmd := &cobra.Command{
+		Use:   "unshare",
+		Short: "Revoke user access to record",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			idRecord := viper.GetString("id")
+			username := viper.GetString("with")
+			escaped := url.PathEscape(username)
+			url := viper.GetString("server") + "/api/records/" + idRecord + "/shares/" + escaped
+			resp, err := svc.Record.Delete(cmd.Context(), url)
+
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+			if err := exitcode.FromResponse(resp, "revoke share"); err != nil {
+				return err
+			}
+			logger.Log.Info("revoke share successfully", zap.String("record id", idRecord), zap.String("with", username))
+
+			return nil
+		},
+	}
+	unshareCmd.Flags().String("id", "", "id record")
+	unshareCmd.Flags().String("with", "", "username to revoke access from")
+	unshareCmd.MarkFlagRequired("id")
+	unshareCmd.MarkFlagRequired("with")
+	return unshareCmd
+}
+
+func NewCmdShares(svc *service.Service) *cobra.Command {
+	sharesCmd := &cobra.Command{
+		Use:   "shares",
+		Short: "List users with access to record",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := viper.GetString("server") + "/api/records/" + viper.GetString("id") + "/shares"
+			resp, err := svc.Record.Get(cmd.Context(), url)
+
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+			if err := exitcode.FromResponse(resp, "get shares"); err != nil {
+				return err
+			}
+
+			return output.Print(resp.Body)
+		},
+	}
+	sharesCmd.Flags().String("id", "", "id record")
+	sharesCmd.MarkFlagRequired("id")
+	return sharesCmd
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
 	"github.com/fatkulllin/gophkeeper/internal/client/service"
 	"github.com/fatkulllin/gophkeeper/model"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
@@ -16,17 +17,14 @@ func NewCmdSync(svc *service.Service) *cobra.Command {
 		Use:   "sync",
 		Short: "sync all records",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			url := viper.GetString("server") + "/api/records"
+			url := withOrg(viper.GetString("server") + "/api/records")
 			resp, err := svc.Record.Get(cmd.Context(), url)
 
 			if err != nil {
-				return fmt.Errorf("internal error: %v", err.Error())
+				return fmt.Errorf("internal error: %w", err)
 			}
-			if resp.StatusCode >= 400 && resp.StatusCode <= 500 {
-				if resp.StatusCode == 401 {
-					return fmt.Errorf("unauthorized: %s", resp.Body)
-				}
-				return fmt.Errorf("registration failed: %s", string(resp.Body))
+			if err := exitcode.FromResponse(resp, "sync"); err != nil {
+				return err
 			}
 			logger.Log.Info("get all successfully")
 			var records []model.Record
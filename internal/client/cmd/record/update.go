@@ -1,10 +1,12 @@
 package record
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/output"
 	"github.com/fatkulllin/gophkeeper/internal/client/service"
 	"github.com/fatkulllin/gophkeeper/model"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
@@ -13,6 +15,9 @@ import (
 )
 
 func NewCmdUpdate(svc *service.Service) *cobra.Command {
+	var expiresAt string
+	var ephemeral, noExpiry bool
+
 	addCmd := &cobra.Command{
 		Use:   "update",
 		Short: "update record",
@@ -20,43 +25,47 @@ func NewCmdUpdate(svc *service.Service) *cobra.Command {
 			metadata := viper.GetString("metadata")
 			data := json.RawMessage(viper.GetString("data"))
 			record := model.RecordUpdateInput{}
-			if !cmd.Flags().Changed("metadata") && !cmd.Flags().Changed("data") {
-				return fmt.Errorf("metadata and data are required")
-			}
 			if cmd.Flags().Changed("metadata") {
 				record.Metadata = &metadata
 			}
 			if cmd.Flags().Changed("data") {
 				record.Data = &data
 			}
+			if cmd.Flags().Changed("expires-at") {
+				t, err := parseExpiry(expiresAt, time.Now())
+				if err != nil {
+					return err
+				}
+				record.ExpiresAt = &t
+			}
+			if cmd.Flags().Changed("ephemeral") {
+				record.Ephemeral = &ephemeral
+			}
+			record.ClearExpiry = noExpiry
+			if record.Empty() {
+				return fmt.Errorf("at least one of --metadata, --data, --expires-at, --ephemeral, --no-expiry is required")
+			}
 
 			url := viper.GetString("server") + "/api/records/" + viper.GetString("id")
 			resp, err := svc.Record.Update(cmd.Context(), url, record)
 
 			if err != nil {
-				return fmt.Errorf("internal error: %v", err.Error())
+				return fmt.Errorf("internal error: %w", err)
 			}
-			if resp.StatusCode >= 400 && resp.StatusCode <= 500 {
-				if resp.StatusCode == 401 {
-					return fmt.Errorf("unauthorized: %s", resp.Body)
-				}
-				return fmt.Errorf("registration failed: %s", string(resp.Body))
+			if err := exitcode.FromResponse(resp, "update record"); err != nil {
+				return err
 			}
 			logger.Log.Info("update record successfully")
-			var pretty bytes.Buffer
-			err = json.Indent(&pretty, resp.Body, "", "  ")
-			if err != nil {
-				fmt.Println(string(resp.Body))
-			} else {
-				fmt.Println(pretty.String())
-			}
-
-			return nil
+			return output.Print(resp.Body)
 		},
 	}
 	addCmd.Flags().String("metadata", "", "metadata record")
 	addCmd.Flags().String("data", "", "data record")
 	addCmd.Flags().String("id", "", "id record")
+	addCmd.Flags().StringVar(&expiresAt, "expires-at", "", "expiry of the secret: RFC 3339 time, YYYY-MM-DD or duration like 90d")
+	addCmd.Flags().BoolVar(&ephemeral, "ephemeral", false, "delete the record on the server when it expires")
+	addCmd.Flags().BoolVar(&noExpiry, "no-expiry", false, "remove the expiry and the ephemeral flag")
+	addCmd.MarkFlagsMutuallyExclusive("expires-at", "no-expiry")
 	addCmd.MarkFlagRequired("id")
 	return addCmd
 }
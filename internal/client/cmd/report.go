@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/pkg/breach"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdReport возвращает команду проверки гигиены локального хранилища:
+// слабых, повторяющихся, старых и утёкших паролей и карт с истекающим сроком.
+func NewCmdReport(svc *service.Service) *cobra.Command {
+	var (
+		orgID      int
+		minEntropy float64
+		maxAgeDays int
+		cardDays   int
+		breachPath string
+		asJSON     bool
+		failUnder  int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Analyze local records for weak, reused, old and breached passwords",
+		Long: `Decrypt local records and report weak passwords (entropy estimate),
+passwords reused across records, passwords unchanged for --max-age days,
+bank cards that expire within --card-warning days and passwords found in an
+offline Have I Been Pwned SHA-1 database (--breaches). Run "record sync" first.`,
+		Example: `  gophkeeper report
+  gophkeeper report --breaches ~/hibp/ranges --max-age 180
+  gophkeeper report --json --fail-under 80`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := service.ReportOptions{
+				OrgID:       orgID,
+				MinEntropy:  minEntropy,
+				MaxAge:      time.Duration(maxAgeDays) * 24 * time.Hour,
+				CardWarning: time.Duration(cardDays) * 24 * time.Hour,
+				Now:         time.Now(),
+			}
+			if breachPath != "" {
+				source, err := breach.Open(breachPath)
+				if err != nil {
+					return err
+				}
+				opts.Breach = source
+			}
+
+			report, err := svc.Report.Build(opts)
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(report); err != nil {
+					return err
+				}
+			} else if err := printReport(report); err != nil {
+				return err
+			}
+
+			if report.Score < failUnder {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("hygiene score %d is below %d", report.Score, failUnder)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&orgID, "org", 0, "analyze records of organization collections")
+	cmd.Flags().Float64Var(&minEntropy, "min-entropy", 60, "minimum estimated password entropy in bits")
+	cmd.Flags().IntVar(&maxAgeDays, "max-age", 365, "report passwords unchanged for more than this number of days (0 to disable)")
+	cmd.Flags().IntVar(&cardDays, "card-warning", 60, "report bank cards expiring within this number of days")
+	cmd.Flags().StringVar(&breachPath, "breaches", "", "directory of k-anonymity range files or file of HASH:COUNT lines (SHA-1)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print report as JSON")
+	cmd.Flags().IntVar(&failUnder, "fail-under", 0, "exit with an error if the hygiene score is below this value")
+	return cmd
+}
+
+func printReport(report service.Report) error {
+	fmt.Printf("hygiene score: %d/100 (%d records checked, %d issues)\n", report.Score, report.Checked, len(report.Findings))
+	if len(report.Findings) == 0 {
+		return nil
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RECORD\tTYPE\tMETADATA\tISSUE\tDETAIL")
+	for _, f := range report.Findings {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", f.RecordID, f.Type, f.Metadata, f.Issue, f.Detail)
+	}
+	return w.Flush()
+}
@@ -4,14 +4,26 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/fatkulllin/gophkeeper/internal/client/app"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/device"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/generate"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/org"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/output"
+	profilemanager "github.com/fatkulllin/gophkeeper/internal/client/cmd/profile"
 	"github.com/fatkulllin/gophkeeper/internal/client/cmd/record"
+	sshmanager "github.com/fatkulllin/gophkeeper/internal/client/cmd/ssh"
 	usermanager "github.com/fatkulllin/gophkeeper/internal/client/cmd/user"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/vaulttoken"
+	"github.com/fatkulllin/gophkeeper/internal/client/config"
+	"github.com/fatkulllin/gophkeeper/internal/client/fs"
+	"github.com/fatkulllin/gophkeeper/internal/client/profile"
 	"github.com/fatkulllin/gophkeeper/internal/client/service"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
 	"github.com/spf13/cobra"
@@ -20,7 +32,9 @@ import (
 )
 
 func NewRootCmd() *cobra.Command {
-	var svc *service.Service
+	// Команды получают указатель на сервис при сборке дерева, а сам сервис
+	// создаётся после разбора флагов, когда известен профиль.
+	svc := &service.Service{}
 
 	rootCmd := &cobra.Command{
 		Use:   "gophkeeper",
@@ -34,21 +48,51 @@ func NewRootCmd() *cobra.Command {
 			if err = initializeLogger(); err != nil {
 				return err
 			}
-			return nil
+			p, err := initializeProfile()
+			if err != nil {
+				return err
+			}
+			appSvc, apiClient, err := app.InitApp(p.Dir)
+			if err != nil {
+				return err
+			}
+			*svc = *appSvc
+			apiClient.SetTimeout(viper.GetDuration("request-timeout"))
+			return apiClient.ConfigureTLS(viper.GetString("ca-cert"), viper.GetString("pin-sha256"))
 		},
 	}
 
-	svc, err := app.InitApp()
-	if err != nil {
-		logger.Log.Error("", zap.Error(err))
-	}
 	rootCtx := rootCmd.Context()
 	rootCmd.PersistentFlags().String("log-level", "info", "logging level (debug, info, warn, error)")
 	rootCmd.PersistentFlags().Bool("develop-log", false, "enable development logging")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default ~/.config/gophkeeper/config.yaml)")
+	rootCmd.PersistentFlags().String("profile", "", "profile to use (default: the one selected by \"profile use\")")
 	rootCmd.PersistentFlags().StringP("server", "s", "http://localhost:8080", "server address")
+	rootCmd.PersistentFlags().String("ca-cert", "", "PEM file with CA certificates trusted for the server")
+	rootCmd.PersistentFlags().String("pin-sha256", "", "base64 SHA-256 pin of the server public key")
+	rootCmd.PersistentFlags().Duration("request-timeout", 10*time.Second, "timeout of a request to the server")
+	rootCmd.PersistentFlags().String("output", output.JSON, "output format: "+strings.Join(output.Formats, ", "))
 	rootCmd.AddCommand(usermanager.NewCmdUser(svc, rootCtx))
 	rootCmd.AddCommand(record.NewCmdRecord(svc))
+	rootCmd.AddCommand(org.NewCmdOrg(svc))
+	rootCmd.AddCommand(device.NewCmdDevice(svc))
+	rootCmd.AddCommand(vaulttoken.NewCmdVaultToken(svc))
+	rootCmd.AddCommand(NewCmdAudit(svc))
+	rootCmd.AddCommand(NewCmdReport(svc))
+	rootCmd.AddCommand(generate.NewCmdGenerate())
 	rootCmd.AddCommand(NewCmdLogout(svc))
+	rootCmd.AddCommand(NewCmdUnlock(svc))
+	rootCmd.AddCommand(NewCmdLock(svc))
+	rootCmd.AddCommand(NewCmdAgent(svc))
+	rootCmd.AddCommand(NewCmdExec(svc))
+	rootCmd.AddCommand(NewCmdInject(svc))
+	rootCmd.AddCommand(NewCmdGitCredential(svc))
+	rootCmd.AddCommand(NewCmdDockerCredential(svc))
+	rootCmd.AddCommand(sshmanager.NewCmdSSHKey(svc))
+	rootCmd.AddCommand(sshmanager.NewCmdSSHAgent(svc))
+	rootCmd.AddCommand(profilemanager.NewCmdProfile())
+	rootCmd.AddCommand(NewCmdConfig())
+	exitcode.Wrap(rootCmd)
 	return rootCmd
 }
 
@@ -58,10 +102,23 @@ var (
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Код завершения зависит от вида ошибки, см. пакет exitcode.
 func Execute() {
-	err := NewRootCmd().Execute()
-	if err != nil {
-		os.Exit(1)
+	execute(NewRootCmd())
+}
+
+// ExecuteDockerCredential выполняет команду docker-credential с аргументами
+// args. Вызывается из docker-credential-gophkeeper, которого Docker запускает
+// с действием в первом аргументе.
+func ExecuteDockerCredential(args []string) {
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs(append([]string{"docker-credential"}, args...))
+	execute(rootCmd)
+}
+
+func execute(rootCmd *cobra.Command) {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(exitcode.Of(err))
 	}
 }
 
@@ -74,33 +131,78 @@ func initializeLogger() error {
 	return nil
 }
 
+// globalConfigPath возвращает файл настроек из --config или файл по
+// умолчанию ~/.config/gophkeeper/config.yaml.
+func globalConfigPath() (string, error) {
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	root, err := fs.PrepareAppDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, profile.SettingsFile), nil
+}
+
+// initializeProfile определяет профиль и подмешивает его настройки в
+// конфигурацию: явно заданные флаги и переменные окружения важнее настроек
+// профиля.
+func initializeProfile() (profile.Profile, error) {
+	p, err := profile.Current()
+	if err != nil {
+		return profile.Profile{}, err
+	}
+	// настройки профиля по умолчанию — это глобальный файл, он уже прочитан
+	if p.Name != profile.Default {
+		settings, err := p.Settings()
+		if err != nil {
+			return profile.Profile{}, err
+		}
+		if err := viper.MergeConfigMap(settings); err != nil {
+			return profile.Profile{}, err
+		}
+	}
+	logger.Log.Debug("profile", zap.String("name", p.Name), zap.String("dir", p.Dir))
+	return p, nil
+}
+
 func initializeConfig(cmd *cobra.Command) error {
-	// 1. Set up Viper to use environment variables.
-	viper.SetEnvPrefix("GOPHKEEPER")
-	// Allow for nested keys in environment variables (e.g. `GOPHKEEPER_DATABASE_HOST`)
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "*", "-", "_"))
-	viper.AutomaticEnv()
+	if err := bindConfig(cmd); err != nil {
+		return err
+	}
 
-	// 2. Handle the configuration file.
+	// 3. Handle the configuration file: --config or the default
+	// ~/.config/gophkeeper/config.yaml. The settings of a named profile are
+	// merged later on top of it (see initializeProfile).
+	path, err := globalConfigPath()
+	if err != nil {
+		return err
+	}
 	if cfgFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
-		// This is an optional but useful step to debug your config.
-		fmt.Println("Configuration initialized. Using config file:", viper.ConfigFileUsed())
+		if _, err := os.Stat(cfgFile); err != nil {
+			return fmt.Errorf("config file: %w", err)
+		}
 	}
 
-	// 3. Read the configuration file.
-	// If a config file is found, read it in. We use a robust error check
-	// to ignore "file not found" errors, but panic on any other error.
-	if err := viper.ReadInConfig(); err != nil {
-		// It's okay if the config file doesn't exist.
-		var configFileNotFoundError viper.ConfigFileNotFoundError
-		if !errors.As(err, &configFileNotFoundError) {
-			return err
-		}
+	// 4. Read and validate the configuration file. A missing default file is
+	// not an error; unknown keys and invalid values are.
+	settings, err := config.ReadValid(path)
+	if err != nil {
+		return err
 	}
+	return viper.MergeConfigMap(settings)
+}
+
+// bindConfig связывает Viper с переменными окружения и флагами команды без
+// чтения файла настроек.
+func bindConfig(cmd *cobra.Command) error {
+	// 1. Set up Viper to use environment variables.
+	viper.SetEnvPrefix("GOPHKEEPER")
+	// Allow for nested keys in environment variables (e.g. `GOPHKEEPER_DATABASE_HOST`)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "*", "-", "_"))
+	viper.AutomaticEnv()
 
-	// 4. Bind Cobra flags to Viper.
+	// 2. Bind Cobra flags to Viper.
 	// This is the magic that makes the flag values available through Viper.
 	// It binds the full flag set of the command passed in.
 	err := viper.BindPFlags(cmd.Flags())
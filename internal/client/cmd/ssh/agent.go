@@ -0,0 +1,125 @@
+package sshmanager
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/agent"
+	"github.com/fatkulllin/gophkeeper/internal/client/app"
+	"github.com/fatkulllin/gophkeeper/internal/client/fs"
+	"github.com/fatkulllin/gophkeeper/internal/client/profile"
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/internal/client/sshagent"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// NewCmdSSHAgent возвращает команду запуска ssh-agent с ключами хранилища.
+func NewCmdSSHAgent(svc *service.Service) *cobra.Command {
+	var (
+		socket     string
+		confirmAll bool
+		askpass    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "ssh-agent",
+		Short: "Serve SSH keys from the vault over the ssh-agent protocol",
+		Long: `Run an ssh-agent in the foreground that serves the keys of ssh_key records.
+Point ssh to it with the printed SSH_AUTH_SOCK. Only processes of the same
+user may connect. Keys cannot be added or removed with ssh-add; use
+"gophkeeper ssh-key".
+
+Keys are read on every request, from the gophkeeper agent when it is
+running, otherwise from the local database, which is opened only for the
+read: they follow "record sync" and are not served while the vault is locked.
+
+Keys of records with "confirm" and, with --confirm, all keys require a
+confirmation on every use: by the ssh-askpass program from --askpass or
+$SSH_ASKPASS, or on the terminal of the agent.`,
+		Example: `  gophkeeper ssh-agent &
+  export SSH_AUTH_SOCK=$XDG_RUNTIME_DIR/gophkeeper-<id>/ssh-agent.sock
+  gophkeeper ssh-agent --confirm --askpass /usr/lib/ssh/ssh-askpass`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := profile.Current()
+			if err != nil {
+				return err
+			}
+			if socket == "" {
+				runtimeDir, err := fs.PrepareRuntimeDir(p.Dir)
+				if err != nil {
+					return err
+				}
+				socket = filepath.Join(runtimeDir, sshagent.SocketFile)
+			}
+
+			keys, err := keySource(svc, p.Dir)
+			if err != nil {
+				return err
+			}
+
+			if askpass == "" {
+				askpass = os.Getenv("SSH_ASKPASS")
+			}
+			confirm := sshagent.Terminal()
+			if askpass != "" {
+				confirm = sshagent.Askpass(askpass)
+			}
+
+			l, err := agent.Listen(socket)
+			if err != nil {
+				return err
+			}
+			defer os.Remove(socket)
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			// x/crypto/ssh/agent пишет отказы в стандартный лог
+			defer zap.RedirectStdLog(logger.Log)()
+
+			a := sshagent.New(keys, sshagent.Options{ConfirmAll: confirmAll, Confirm: confirm})
+			fmt.Printf("SSH_AUTH_SOCK=%s; export SSH_AUTH_SOCK;\n", socket)
+			logger.Log.Info("ssh-agent started", zap.String("socket", socket))
+			err = a.Serve(ctx, l)
+			logger.Log.Info("ssh-agent stopped")
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&socket, "socket", "", "socket path (default ssh-agent.sock in the runtime directory of the profile)")
+	cmd.Flags().BoolVar(&confirmAll, "confirm", false, "ask for confirmation on every use of any key")
+	cmd.Flags().StringVar(&askpass, "askpass", "", "ssh-askpass program for confirmations (default $SSH_ASKPASS, else the terminal)")
+	return cmd
+}
+
+// keySource возвращает источник ключей агента. С агентом gophkeeper ключи
+// читаются у него. Без агента BoltDB закрывается и открывается заново на
+// каждый запрос, чтобы не держать её заблокированной всё время работы
+// ssh-agent, а ключи каждый раз расшифровываются через Keyring: блокировка
+// хранилища, автоблокировка и удаление записей действуют сразу.
+func keySource(svc *service.Service, appDir string) (sshagent.KeySource, error) {
+	closer, ok := svc.Store.(io.Closer)
+	if !ok {
+		return svc.Record.SSHKeys, nil
+	}
+	if err := closer.Close(); err != nil {
+		return nil, err
+	}
+
+	return func() ([]service.SSHKey, error) {
+		svc, _, err := app.InitApp(appDir)
+		if err != nil {
+			return nil, err
+		}
+		if closer, ok := svc.Store.(io.Closer); ok {
+			defer closer.Close()
+		}
+		return svc.Record.SSHKeys()
+	}, nil
+}
@@ -0,0 +1,163 @@
+package sshmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/passphrase"
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/fatkulllin/gophkeeper/pkg/sshkey"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// NewCmdSSHKey возвращает команды управления записями ssh_key.
+func NewCmdSSHKey(svc *service.Service) *cobra.Command {
+	cmds := &cobra.Command{
+		Use:   "ssh-key",
+		Short: "Manage SSH keys stored in the vault",
+		Long: `Generate or import SSH keys into ssh_key records and print their public keys.
+Keys are served to ssh by "gophkeeper ssh-agent".`,
+	}
+	cmds.AddCommand(newCmdGenerate(svc))
+	cmds.AddCommand(newCmdImport(svc))
+	cmds.AddCommand(newCmdPublic(svc))
+	return cmds
+}
+
+// keyFlags — общие флаги создания записи ssh_key.
+type keyFlags struct {
+	name    string
+	comment string
+	confirm bool
+}
+
+func (f *keyFlags) add(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.name, "metadata", "", "record metadata (key name)")
+	cmd.Flags().StringVar(&f.comment, "comment", "", "key comment shown by ssh-add -l")
+	cmd.Flags().BoolVar(&f.confirm, "confirm", false, "ask for confirmation every time ssh-agent uses the key")
+	_ = cmd.MarkFlagRequired("metadata")
+}
+
+func newCmdGenerate(svc *service.Service) *cobra.Command {
+	var flags keyFlags
+	var alg string
+	var bits int
+
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate an SSH key in a new ssh_key record",
+		Long: `Generate a key pair, store it in a new ssh_key record and print the public
+key in authorized_keys format. The private key never touches the disk.`,
+		Example: `  gophkeeper ssh-key generate --metadata github
+  gophkeeper ssh-key generate --metadata legacy-host --type rsa --bits 3072 --confirm`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			private, err := sshkey.Generate(alg, bits, flags.comment)
+			if err != nil {
+				return exitcode.New(exitcode.Usage, err)
+			}
+			return addKey(cmd.Context(), svc, private, flags)
+		},
+	}
+	flags.add(cmd)
+	cmd.Flags().StringVar(&alg, "type", sshkey.Ed25519, "key type: ed25519 or rsa")
+	cmd.Flags().IntVar(&bits, "bits", sshkey.DefaultRSABits, "RSA key length")
+	return cmd
+}
+
+func newCmdImport(svc *service.Service) *cobra.Command {
+	var flags keyFlags
+
+	cmd := &cobra.Command{
+		Use:   "import <private-key-file>",
+		Short: "Import an existing private key into a new ssh_key record",
+		Long: `Import a private key (OpenSSH or PEM format) into a new ssh_key record and
+print its public key. A passphrase-protected key is decrypted with a
+passphrase asked on the terminal; the record is protected by the vault
+encryption instead. Delete the file afterwards.`,
+		Example: `  gophkeeper ssh-key import ~/.ssh/id_ed25519 --metadata work-laptop && rm ~/.ssh/id_ed25519`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			raw, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			private, err := sshkey.Decrypt(raw, nil, flags.comment)
+			if errors.Is(err, sshkey.ErrPassphraseRequired) {
+				var secret []byte
+				secret, err = passphrase.ReadTerminal("Passphrase of " + args[0] + ": ")
+				if err != nil {
+					return err
+				}
+				private, err = sshkey.Decrypt(raw, secret, flags.comment)
+			}
+			if err != nil {
+				return err
+			}
+			return addKey(cmd.Context(), svc, private, flags)
+		},
+	}
+	flags.add(cmd)
+	return cmd
+}
+
+// addKey сохраняет ключ в новой записи ssh_key и выводит открытый ключ.
+func addKey(ctx context.Context, svc *service.Service, private []byte, flags keyFlags) error {
+	key, err := service.NewSSHKeyData(private, flags.comment, flags.confirm)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	record := model.RecordInput{
+		Type:     model.TypeSSHKey,
+		Metadata: flags.name,
+		Data:     data,
+	}
+	resp, err := svc.Record.Add(ctx, record, viper.GetString("server")+"/api/record")
+	if err != nil {
+		return fmt.Errorf("internal error: %w", err)
+	}
+	if err := exitcode.FromResponse(resp, "add record"); err != nil {
+		return err
+	}
+	logger.Log.Info("ssh key added", zap.String("fingerprint", key.Fingerprint))
+
+	fmt.Println(key.PublicKey)
+	fmt.Fprintln(os.Stderr, `run "gophkeeper record sync" to make the key available to ssh-agent`)
+	return nil
+}
+
+func newCmdPublic(svc *service.Service) *cobra.Command {
+	return &cobra.Command{
+		Use:   "public <record>",
+		Short: "Print the public key of an ssh_key record",
+		Long:  `Print the public key of a local ssh_key record, given by id or metadata, in authorized_keys format.`,
+		Example: `  gophkeeper ssh-key public github >> authorized_keys
+  gophkeeper ssh-key public 42`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			secrets, err := svc.Record.LocalSecrets()
+			if err != nil {
+				return err
+			}
+			public, err := secrets.Lookup(args[0], "public_key")
+			if err != nil {
+				return err
+			}
+			fmt.Println(public)
+			return nil
+		},
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/passphrase"
+	"github.com/fatkulllin/gophkeeper/internal/client/profile"
 	"github.com/fatkulllin/gophkeeper/internal/client/service"
 	"github.com/fatkulllin/gophkeeper/model"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
@@ -28,7 +31,11 @@ Examples:
   gophkeeper login --username bob --password mypass
 
 After successful authentication, your access token is stored locally
-and used for future requests.`,
+and used for future requests. The server address given with --server is
+remembered in the current profile (see "gophkeeper profile"). The keys that decrypt your records are
+stored encrypted with a local passphrase (asked twice, or taken from
+GOPHKEEPER_VAULT_PASSPHRASE); the vault stays unlocked until --auto-lock of
+inactivity.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := svc.User.ClearDB()
 			if err != nil {
@@ -48,14 +55,11 @@ and used for future requests.`,
 
 			resp, err := svc.User.LoginUser(ctx, username, password, url)
 			if err != nil {
-				return fmt.Errorf("internal error: %v", err.Error())
+				return fmt.Errorf("internal error: %w", err)
 			}
 
-			if resp.StatusCode >= 400 && resp.StatusCode <= 500 {
-				if resp.StatusCode == 401 {
-					return fmt.Errorf("unauthorized: %s", resp.Body)
-				}
-				return fmt.Errorf("registration failed: %s", string(resp.Body))
+			if err := exitcode.FromResponse(resp, "login"); err != nil {
+				return err
 			}
 
 			logger.Log.Info("loggin successfully")
@@ -75,27 +79,42 @@ and used for future requests.`,
 				return fmt.Errorf("failed save token: %v", err)
 			}
 
+			// адрес сервера, указанный при входе, запоминается в профиле
+			if cmd.Flags().Changed("server") {
+				p, err := profile.Current()
+				if err != nil {
+					return err
+				}
+				if err := p.SaveSetting("server", viper.GetString("server")); err != nil {
+					return fmt.Errorf("failed save server to profile: %v", err)
+				}
+			}
+
 			var userKeyResponse model.UserKeyRespone
 			err = json.Unmarshal(resp.Body, &userKeyResponse)
 			if err != nil {
-				return fmt.Errorf("internal error: %v", err.Error())
+				return fmt.Errorf("internal error: %w", err)
 			}
-			err = svc.User.SaveUserKey(userKeyResponse.UserKey)
+			secret, err := passphrase.Read("Local vault passphrase: ", true)
 			if err != nil {
-				return fmt.Errorf("internal error: %v", err.Error())
+				return err
+			}
+			err = svc.User.SaveKeys(secret, userKeyResponse.UserKey, userKeyResponse.PrivateKey, viper.GetDuration("auto-lock"))
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+			if status, err := svc.Keyring.Status(); err == nil {
+				passphrase.WarnSessionOnDisk(cmd.ErrOrStderr(), status.SessionDir)
 			}
 
 			urlRecords := viper.GetString("server") + "/api/records"
 			recordsResponse, err := svc.Record.Get(cmd.Context(), urlRecords)
 
 			if err != nil {
-				return fmt.Errorf("internal error: %v", err.Error())
+				return fmt.Errorf("internal error: %w", err)
 			}
-			if recordsResponse.StatusCode >= 400 && recordsResponse.StatusCode <= 500 {
-				if recordsResponse.StatusCode == 401 {
-					return fmt.Errorf("unauthorized: %s", recordsResponse.Body)
-				}
-				return fmt.Errorf("registration failed: %s", string(recordsResponse.Body))
+			if err := exitcode.FromResponse(recordsResponse, "get records"); err != nil {
+				return err
 			}
 			logger.Log.Info("get all successfully")
 			var records []model.Record
@@ -112,5 +131,6 @@ and used for future requests.`,
 	cmd.Flags().StringP("username", "u", "", "username")
 	cmd.Flags().StringP("password", "p", "", "password")
 	cmd.Flags().Bool("userkey", false, "get user key")
+	cmd.Flags().Duration("auto-lock", service.DefaultAutoLock, "lock the local vault after this period of inactivity (0 disables auto-lock)")
 	return cmd
 }
@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
 	"github.com/fatkulllin/gophkeeper/internal/client/service"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
 	"github.com/spf13/cobra"
@@ -38,14 +39,11 @@ Upon successful registration, you will be automatically logged in.`,
 			resp, err := svc.User.LoginUser(ctx, username, password, url)
 
 			if err != nil {
-				return fmt.Errorf("internal error: %v", err.Error())
+				return fmt.Errorf("internal error: %w", err)
 			}
 
-			if resp.StatusCode >= 400 && resp.StatusCode <= 500 {
-				if resp.StatusCode == 401 {
-					return fmt.Errorf("unauthorized: %s", resp.Body)
-				}
-				return fmt.Errorf("registration failed: %s", string(resp.Body))
+			if err := exitcode.FromResponse(resp, "registration"); err != nil {
+				return err
 			}
 			logger.Log.Info("registration successfully")
 
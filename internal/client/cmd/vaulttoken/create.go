@@ -0,0 +1,43 @@
+package vaulttoken
+
+import (
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/output"
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewCmdCreate(svc *service.Service) *cobra.Command {
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a read-only Vault token",
+		Long: `Create a token for tools that read secrets through the Vault KV v2
+compatible API (vault CLI, consul-template, External Secrets). The token only
+reads your records under /v1 and is not accepted by the rest of the API; the
+session token of "user login" is not accepted under /v1.
+
+The token is printed once, the server keeps only its hash.
+
+Examples:
+  gophkeeper vault-token create --name ci
+  export VAULT_TOKEN=$(gophkeeper vault-token create --name ci --output raw --field token)`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := viper.GetString("server") + "/api/vault/tokens"
+			resp, err := svc.VaultToken.Create(cmd.Context(), url, viper.GetString("name"))
+
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+			if err := checkResponse(resp, "create vault token"); err != nil {
+				return err
+			}
+			return printJSON(resp.Body)
+		},
+	}
+	createCmd.Flags().String("name", "", "token name")
+	createCmd.MarkFlagRequired("name")
+	output.AddFieldFlag(createCmd)
+	return createCmd
+}
@@ -0,0 +1,29 @@
+package vaulttoken
+
+import (
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewCmdList(svc *service.Service) *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List Vault tokens",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := viper.GetString("server") + "/api/vault/tokens"
+			resp, err := svc.VaultToken.Get(cmd.Context(), url)
+
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+			if err := checkResponse(resp, "list vault tokens"); err != nil {
+				return err
+			}
+			return printJSON(resp.Body)
+		},
+	}
+	return listCmd
+}
@@ -0,0 +1,39 @@
+package vaulttoken
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+func NewCmdRevoke(svc *service.Service) *cobra.Command {
+	revokeCmd := &cobra.Command{
+		Use:   "revoke",
+		Short: "Revoke a Vault token",
+		Long: `Revoke a Vault token. Requests with it are rejected immediately.
+
+Examples:
+  gophkeeper vault-token revoke --id 2`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := viper.GetString("server") + "/api/vault/tokens/" + strconv.Itoa(viper.GetInt("id"))
+			resp, err := svc.VaultToken.Revoke(cmd.Context(), url)
+
+			if err != nil {
+				return fmt.Errorf("internal error: %w", err)
+			}
+			if err := checkResponse(resp, "revoke vault token"); err != nil {
+				return err
+			}
+			logger.Log.Info("vault token revoked", zap.Int("id", viper.GetInt("id")))
+			return nil
+		},
+	}
+	revokeCmd.Flags().Int("id", 0, "vault token id")
+	revokeCmd.MarkFlagRequired("id")
+	return revokeCmd
+}
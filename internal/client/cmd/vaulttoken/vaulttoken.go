@@ -0,0 +1,30 @@
+package vaulttoken
+
+import (
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/exitcode"
+	"github.com/fatkulllin/gophkeeper/internal/client/cmd/output"
+	"github.com/fatkulllin/gophkeeper/internal/client/models"
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdVaultToken(svc *service.Service) *cobra.Command {
+	cmds := &cobra.Command{
+		Use:   "vault-token",
+		Short: "Manage read-only tokens for the Vault KV v2 compatible API",
+	}
+	cmds.AddCommand(NewCmdCreate(svc))
+	cmds.AddCommand(NewCmdList(svc))
+	cmds.AddCommand(NewCmdRevoke(svc))
+	return cmds
+}
+
+// checkResponse преобразует ответ сервера с ошибкой в error.
+func checkResponse(resp *models.Response, action string) error {
+	return exitcode.FromResponse(resp, action)
+}
+
+// printJSON выводит ответ сервера в формате --output.
+func printJSON(body []byte) error {
+	return output.Print(body)
+}
@@ -0,0 +1,242 @@
+// Package config описывает схему файла настроек клиента
+// (~/.config/gophkeeper/config.yaml и config.yaml профилей): допустимые
+// ключи, их типы и проверки значений.
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+	"go.yaml.in/yaml/v3"
+)
+
+// Key — ключ файла настроек. Имя совпадает с именем флага CLI, значение
+// из файла используется, если флаг и переменная окружения не заданы.
+type Key struct {
+	Name        string
+	Description string
+	// Default — значение по умолчанию для вывода "config get/list".
+	Default string
+	// Parse проверяет значение и приводит его к типу ключа.
+	Parse func(value any) (any, error)
+}
+
+// Keys — схема настроек.
+var Keys = []Key{
+	{Name: "server", Default: "http://localhost:8080", Description: "server address (http:// or https:// URL)", Parse: parseURL},
+	{Name: "ca-cert", Description: "PEM file with CA certificates trusted for the server", Parse: parseString},
+	{Name: "pin-sha256", Description: "base64 SHA-256 pin of the server public key", Parse: parsePin},
+	{Name: "request-timeout", Default: "10s", Description: "timeout of a request to the server", Parse: parsePositiveDuration},
+	{Name: "output", Description: "output format of commands: json, yaml, table, env or raw", Parse: oneOf("json", "yaml", "table", "env", "raw")},
+	{Name: "auto-lock", Default: "15m0s", Description: "lock the local vault after this period of inactivity (0 disables auto-lock)", Parse: parseDuration},
+	{Name: "log-level", Default: "info", Description: "logging level: debug, info, warn or error", Parse: oneOf("debug", "info", "warn", "error")},
+	{Name: "develop-log", Default: "false", Description: "enable development logging", Parse: parseBool},
+}
+
+// Template возвращает содержимое нового файла настроек: закомментированный
+// список ключей.
+func Template() string {
+	var b strings.Builder
+	b.WriteString("# GophKeeper client settings. Flags and GOPHKEEPER_* variables take precedence.\n")
+	for _, k := range Keys {
+		fmt.Fprintf(&b, "#\n# %s\n# %s: %s\n", k.Description, k.Name, k.Default)
+	}
+	return b.String()
+}
+
+// Lookup возвращает описание ключа name.
+func Lookup(name string) (Key, error) {
+	for _, k := range Keys {
+		if k.Name == name {
+			return k, nil
+		}
+	}
+	return Key{}, fmt.Errorf("unknown config key %q", name)
+}
+
+// Validate проверяет настройки, прочитанные из файла path: неизвестные
+// ключи и недопустимые значения — ошибка.
+func Validate(path string, settings map[string]any) error {
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		k, err := Lookup(name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if _, err := k.Parse(settings[name]); err != nil {
+			return fmt.Errorf("%s: %s: %w", path, name, err)
+		}
+	}
+	return nil
+}
+
+// Read читает файл настроек. Отсутствующий файл — пустые настройки.
+func Read(path string) (map[string]any, error) {
+	settings := map[string]any{}
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(raw, &settings); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if settings == nil {
+		settings = map[string]any{}
+	}
+	return settings, nil
+}
+
+// ReadValid читает файл настроек и проверяет его по схеме.
+func ReadValid(path string) (map[string]any, error) {
+	settings, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := Validate(path, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// Write сохраняет настройки в файл path с правами 0600.
+func Write(path string, settings map[string]any) error {
+	raw, err := yaml.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0600)
+}
+
+// Set проверяет значение ключа name, заданное строкой, и сохраняет его в
+// файле path.
+func Set(path, name, value string) error {
+	k, err := Lookup(name)
+	if err != nil {
+		return err
+	}
+	parsed, err := k.Parse(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	settings, err := Read(path)
+	if err != nil {
+		return err
+	}
+	switch v := parsed.(type) {
+	case time.Duration:
+		settings[name] = v.String()
+	default:
+		settings[name] = v
+	}
+	return Write(path, settings)
+}
+
+// Unset удаляет ключ name из файла path.
+func Unset(path, name string) error {
+	if _, err := Lookup(name); err != nil {
+		return err
+	}
+	settings, err := Read(path)
+	if err != nil {
+		return err
+	}
+	delete(settings, name)
+	return Write(path, settings)
+}
+
+func parseURL(value any) (any, error) {
+	s, err := cast.ToStringE(value)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%q is not an http:// or https:// URL", s)
+	}
+	return s, nil
+}
+
+func parseString(value any) (any, error) {
+	return cast.ToStringE(value)
+}
+
+func parsePin(value any) (any, error) {
+	s, err := cast.ToStringE(value)
+	if err != nil {
+		return nil, err
+	}
+	if s == "" {
+		return s, nil
+	}
+	if raw, err := base64.StdEncoding.DecodeString(s); err != nil || len(raw) != sha256.Size {
+		return nil, fmt.Errorf("expected base64 SHA-256 digest")
+	}
+	return s, nil
+}
+
+func parseDuration(value any) (any, error) {
+	d, err := cast.ToDurationE(value)
+	if err != nil {
+		return nil, err
+	}
+	if d < 0 {
+		return nil, fmt.Errorf("must not be negative")
+	}
+	return d, nil
+}
+
+func parsePositiveDuration(value any) (any, error) {
+	d, err := parseDuration(value)
+	if err != nil {
+		return nil, err
+	}
+	if d.(time.Duration) == 0 {
+		return nil, fmt.Errorf("must be positive")
+	}
+	return d, nil
+}
+
+func parseBool(value any) (any, error) {
+	if s, ok := value.(string); ok {
+		return strconv.ParseBool(s)
+	}
+	return cast.ToBoolE(value)
+}
+
+func oneOf(values ...string) func(value any) (any, error) {
+	return func(value any) (any, error) {
+		s, err := cast.ToStringE(value)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range values {
+			if s == v {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not one of %v", s, values)
+	}
+}
@@ -1,6 +1,9 @@
 package fs
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"os"
 	"path/filepath"
 )
@@ -19,3 +22,53 @@ func PrepareAppDir() (string, error) {
 
 	return path, nil
 }
+
+// PrepareRuntimeDir создаёт каталог сессии разблокировки для каталога
+// приложения appDir. Каталог создаётся в XDG_RUNTIME_DIR (tmpfs, очищается
+// при выходе из системы и перезагрузке), а без него — в личном каталоге
+// пользователя во временном каталоге системы, который может находиться на
+// диске (см. RuntimeDirOnDisk).
+func PrepareRuntimeDir(appDir string) (string, error) {
+	base, err := runtimeBase()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(appDir))
+	path := filepath.Join(base, "gophkeeper-"+hex.EncodeToString(sum[:6]))
+
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// RuntimeDirOnDisk сообщает, что XDG_RUNTIME_DIR не задан и каталог сессии
+// находится во временном каталоге системы, а не в tmpfs.
+func RuntimeDirOnDisk() bool {
+	return os.Getenv("XDG_RUNTIME_DIR") == ""
+}
+
+// runtimeBase возвращает XDG_RUNTIME_DIR, а без него — каталог
+// os.TempDir()/gophkeeper-<uid>. Общий временный каталог доступен всем
+// пользователям, поэтому чужой или открытый для других каталог с тем же
+// именем не используется.
+func runtimeBase() (string, error) {
+	if base := os.Getenv("XDG_RUNTIME_DIR"); base != "" {
+		return base, nil
+	}
+
+	base := filepath.Join(os.TempDir(), tempDirName())
+	if err := os.Mkdir(base, 0700); err != nil && !errors.Is(err, os.ErrExist) {
+		return "", err
+	}
+	fi, err := os.Lstat(base)
+	if err != nil {
+		return "", err
+	}
+	if err := checkPrivateDir(base, fi); err != nil {
+		return "", err
+	}
+	return base, nil
+}
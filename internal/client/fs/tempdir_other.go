@@ -0,0 +1,21 @@
+//go:build !unix
+
+package fs
+
+import (
+	"fmt"
+	"os"
+)
+
+// tempDirName без uid: временный каталог на этих системах свой у каждого
+// пользователя.
+func tempDirName() string {
+	return "gophkeeper"
+}
+
+func checkPrivateDir(path string, fi os.FileInfo) error {
+	if !fi.IsDir() {
+		return fmt.Errorf("runtime dir %s is not a directory", path)
+	}
+	return nil
+}
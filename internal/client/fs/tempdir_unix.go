@@ -0,0 +1,30 @@
+//go:build unix
+
+package fs
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+func tempDirName() string {
+	return "gophkeeper-" + strconv.Itoa(os.Getuid())
+}
+
+// checkPrivateDir проверяет, что path — каталог (не символическая ссылка)
+// текущего пользователя, недоступный остальным.
+func checkPrivateDir(path string, fi os.FileInfo) error {
+	if !fi.IsDir() {
+		return fmt.Errorf("runtime dir %s is not a directory", path)
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok || int(st.Uid) != os.Getuid() {
+		return fmt.Errorf("runtime dir %s is owned by another user", path)
+	}
+	if fi.Mode().Perm()&0077 != 0 {
+		return fmt.Errorf("runtime dir %s is accessible by other users (mode %s)", path, fi.Mode().Perm())
+	}
+	return nil
+}
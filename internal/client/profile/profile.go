@@ -0,0 +1,211 @@
+// Package profile управляет профилями клиента: у каждого профиля свой
+// каталог с токеном, локальной BoltDB, сертификатом устройства и
+// настройками (адрес сервера и т.д.).
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/config"
+	"github.com/fatkulllin/gophkeeper/internal/client/fs"
+	"github.com/spf13/viper"
+)
+
+// Default — профиль по умолчанию. Его каталог — корневой каталог клиента,
+// поэтому данные, сохранённые до появления профилей, остаются в нём.
+const Default = "default"
+
+// SettingsFile — файл настроек профиля.
+const SettingsFile = "config.yaml"
+
+const (
+	profilesDir = "profiles"
+	activeFile  = "profile"
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+var ErrNotFound = errors.New("profile not found")
+
+// Profile — именованный профиль клиента.
+type Profile struct {
+	Name string
+	Dir  string
+}
+
+// ValidateName проверяет имя профиля: латинские буквы, цифры, '_', '.', '-'.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) || len(name) > 64 {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '_', '.' and '-'", name)
+	}
+	return nil
+}
+
+// Current возвращает профиль из флага --profile или GOPHKEEPER_PROFILE, а
+// без них — выбранный командой "profile use". Каталог профиля создаётся.
+func Current() (Profile, error) {
+	name := viper.GetString("profile")
+	if name == "" {
+		active, err := Active()
+		if err != nil {
+			return Profile{}, err
+		}
+		name = active
+	}
+	return Open(name)
+}
+
+// Open возвращает профиль name, создавая его каталог.
+func Open(name string) (Profile, error) {
+	p, err := lookup(name)
+	if err != nil {
+		return Profile{}, err
+	}
+	if err := os.MkdirAll(p.Dir, 0700); err != nil {
+		return Profile{}, err
+	}
+	return p, nil
+}
+
+// Active возвращает имя профиля, выбранного командой "profile use".
+func Active() (string, error) {
+	root, err := fs.PrepareAppDir()
+	if err != nil {
+		return "", err
+	}
+	raw, err := os.ReadFile(filepath.Join(root, activeFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return Default, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(string(raw))
+	if name == "" {
+		return Default, nil
+	}
+	return name, nil
+}
+
+// SetActive делает существующий профиль name профилем по умолчанию.
+func SetActive(name string) error {
+	p, err := lookup(name)
+	if err != nil {
+		return err
+	}
+	if !p.Exists() {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	root, err := fs.PrepareAppDir()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(root, activeFile), []byte(name+"\n"), 0600)
+}
+
+// List возвращает профиль по умолчанию и все именованные профили по алфавиту.
+func List() ([]Profile, error) {
+	root, err := fs.PrepareAppDir()
+	if err != nil {
+		return nil, err
+	}
+	profiles := []Profile{{Name: Default, Dir: root}}
+
+	entries, err := os.ReadDir(filepath.Join(root, profilesDir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() && ValidateName(e.Name()) == nil && e.Name() != Default {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		profiles = append(profiles, Profile{Name: name, Dir: filepath.Join(root, profilesDir, name)})
+	}
+	return profiles, nil
+}
+
+// Remove удаляет каталог профиля name со всеми данными. Профиль по
+// умолчанию удалить нельзя; если удаляется выбранный профиль, выбранным
+// становится профиль по умолчанию.
+func Remove(name string) error {
+	if name == Default {
+		return errors.New("the default profile cannot be removed, use \"gophkeeper logout\"")
+	}
+	p, err := lookup(name)
+	if err != nil {
+		return err
+	}
+	if !p.Exists() {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	if runtimeDir, err := fs.PrepareRuntimeDir(p.Dir); err == nil {
+		if err := os.RemoveAll(runtimeDir); err != nil {
+			return err
+		}
+	}
+	if err := os.RemoveAll(p.Dir); err != nil {
+		return err
+	}
+
+	active, err := Active()
+	if err != nil {
+		return err
+	}
+	if active == name {
+		return SetActive(Default)
+	}
+	return nil
+}
+
+// Exists сообщает, создан ли каталог профиля.
+func (p Profile) Exists() bool {
+	info, err := os.Stat(p.Dir)
+	return err == nil && info.IsDir()
+}
+
+// LoggedIn сообщает, сохранён ли в профиле токен авторизации.
+func (p Profile) LoggedIn() bool {
+	_, err := os.Stat(filepath.Join(p.Dir, "token"))
+	return err == nil
+}
+
+// SettingsPath возвращает путь к файлу настроек профиля.
+func (p Profile) SettingsPath() string {
+	return filepath.Join(p.Dir, SettingsFile)
+}
+
+// Settings читает и проверяет настройки профиля. Отсутствующий файл — пустые
+// настройки.
+func (p Profile) Settings() (map[string]any, error) {
+	return config.ReadValid(p.SettingsPath())
+}
+
+// SaveSetting проверяет и сохраняет значение key в настройках профиля.
+func (p Profile) SaveSetting(key, value string) error {
+	return config.Set(p.SettingsPath(), key, value)
+}
+
+func lookup(name string) (Profile, error) {
+	root, err := fs.PrepareAppDir()
+	if err != nil {
+		return Profile{}, err
+	}
+	if name == Default {
+		return Profile{Name: Default, Dir: root}, nil
+	}
+	if err := ValidateName(name); err != nil {
+		return Profile{}, err
+	}
+	return Profile{Name: name, Dir: filepath.Join(root, profilesDir, name)}, nil
+}
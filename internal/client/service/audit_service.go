@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/models"
+)
+
+// AuditService выполняет запросы к журналу аудита сервера.
+type AuditService struct {
+	apiClient   ApiClient
+	fileManager FileManager
+}
+
+func NewAuditService(apiClient ApiClient, fileManager FileManager) *AuditService {
+	return &AuditService{
+		apiClient:   apiClient,
+		fileManager: fileManager,
+	}
+}
+
+func (s *AuditService) Get(ctx context.Context, url string) (*models.Response, error) {
+	return send(ctx, s.apiClient, s.fileManager, http.MethodGet, url, nil)
+}
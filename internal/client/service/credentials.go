@@ -0,0 +1,120 @@
+package service
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Credential — учётные данные записи login_password, метаданные которой —
+// адрес сервиса: https://github.com, https://git.example.com/team/repo.git.
+type Credential struct {
+	RecordID int64
+	URL      *url.URL
+	Login    string
+	Password string
+	// Data — все поля данных записи, чтобы при обновлении не потерять
+	// остальные (например, totp).
+	Data map[string]any
+}
+
+// ParseCredentialURL разбирает метаданные записи как адрес сервиса. Адрес
+// должен содержать схему и хост.
+func ParseCredentialURL(metadata string) (*url.URL, bool) {
+	if !strings.Contains(metadata, "://") {
+		return nil, false
+	}
+	u, err := url.Parse(metadata)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, false
+	}
+	return u, true
+}
+
+// Credentials возвращает учётные данные из локальных записей login_password
+// с адресом в метаданных, включая записи организаций.
+func (s *RecordService) Credentials() ([]Credential, error) {
+	records, err := s.boltDB.All()
+	if err != nil {
+		return nil, err
+	}
+
+	var userKey []byte
+	credentials := make([]Credential, 0)
+	for _, rec := range records {
+		if rec.Type != model.TypeLoginPassword {
+			continue
+		}
+		u, ok := ParseCredentialURL(rec.Metadata)
+		if !ok {
+			continue
+		}
+		// ключ нужен, только если есть подходящие записи
+		if userKey == nil {
+			if userKey, err = s.keyring.UserKey(); err != nil {
+				return nil, err
+			}
+		}
+		record, err := s.decryptRecord(rec, userKey)
+		if err != nil {
+			return nil, err
+		}
+		var data map[string]any
+		if err := json.Unmarshal(record.Data, &data); err != nil {
+			logger.Log.Warn("skip invalid login_password record", zap.Int64("id", rec.ID), zap.Error(err))
+			continue
+		}
+		login, _ := data["login"].(string)
+		password, _ := data["password"].(string)
+		credentials = append(credentials, Credential{
+			RecordID: rec.ID,
+			URL:      u,
+			Login:    login,
+			Password: password,
+			Data:     data,
+		})
+	}
+	return credentials, nil
+}
+
+// MatchCredential выбирает учётные данные для адреса target. Схема и хост
+// (с портом) должны совпадать; путь записи должен быть префиксом пути target
+// по границе сегментов, и из подходящих выбирается запись с самым длинным
+// путём. Непустой login должен совпадать с логином записи.
+func MatchCredential(credentials []Credential, target *url.URL, login string) (Credential, bool) {
+	var best Credential
+	bestLen := -1
+	for _, c := range credentials {
+		if !strings.EqualFold(c.URL.Scheme, target.Scheme) || !strings.EqualFold(c.URL.Host, target.Host) {
+			continue
+		}
+		if login != "" && c.Login != login {
+			continue
+		}
+		prefix := strings.Trim(c.URL.Path, "/")
+		if !pathHasPrefix(strings.Trim(target.Path, "/"), prefix) {
+			continue
+		}
+		if len(prefix) > bestLen {
+			best, bestLen = c, len(prefix)
+		}
+	}
+	return best, bestLen >= 0
+}
+
+func pathHasPrefix(path, prefix string) bool {
+	if prefix == "" || path == prefix {
+		return true
+	}
+	return strings.HasPrefix(path, prefix+"/")
+}
+
+// DeleteLocal удаляет локальную копию записи; запись на сервере остаётся и
+// вернётся при следующей синхронизации.
+func (s *RecordService) DeleteLocal(id int64) error {
+	return s.boltDB.Delete(id)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/models"
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// Файлы сертификата устройства в каталоге приложения.
+const (
+	DeviceCertFile   = "device.crt"
+	DeviceKeyFile    = "device.key"
+	DeviceCACertFile = "device-ca.crt"
+)
+
+// DeviceService регистрирует устройство клиента и управляет устройствами пользователя.
+type DeviceService struct {
+	apiClient   ApiClient
+	fileManager FileManager
+}
+
+func NewDeviceService(apiClient ApiClient, fileManager FileManager) *DeviceService {
+	return &DeviceService{
+		apiClient:   apiClient,
+		fileManager: fileManager,
+	}
+}
+
+// Enroll генерирует ключ устройства и CSR, получает сертификат от сервера
+// и сохраняет ключ и сертификаты в каталоге приложения.
+// Закрытый ключ не покидает устройство.
+func (s *DeviceService) Enroll(ctx context.Context, url string, name string) (*models.Response, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate device key: %w", err)
+	}
+
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: name},
+	}, key)
+	if err != nil {
+		return nil, fmt.Errorf("create CSR: %w", err)
+	}
+
+	resp, err := send(ctx, s.apiClient, s.fileManager, http.MethodPost, url, model.EnrollInput{
+		Name: name,
+		CSR:  string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})),
+	})
+	if err != nil || resp.StatusCode != http.StatusCreated {
+		return resp, err
+	}
+
+	var enrolled model.EnrollResponse
+	if err := json.Unmarshal(resp.Body, &enrolled); err != nil {
+		return nil, fmt.Errorf("decode enroll response: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("marshal device key: %w", err)
+	}
+	if err := s.fileManager.SaveFile(DeviceKeyFile, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})), 0600); err != nil {
+		return nil, fmt.Errorf("save device key: %w", err)
+	}
+	if err := s.fileManager.SaveFile(DeviceCertFile, enrolled.Certificate, 0644); err != nil {
+		return nil, fmt.Errorf("save device certificate: %w", err)
+	}
+	if err := s.fileManager.SaveFile(DeviceCACertFile, enrolled.CACertificate, 0644); err != nil {
+		return nil, fmt.Errorf("save device CA certificate: %w", err)
+	}
+	return resp, nil
+}
+
+func (s *DeviceService) Get(ctx context.Context, url string) (*models.Response, error) {
+	return send(ctx, s.apiClient, s.fileManager, http.MethodGet, url, nil)
+}
+
+func (s *DeviceService) Revoke(ctx context.Context, url string) (*models.Response, error) {
+	return send(ctx, s.apiClient, s.fileManager, http.MethodDelete, url, nil)
+}
+
+func (s *DeviceService) SetPolicy(ctx context.Context, url string, policy model.DevicePolicy) (*models.Response, error) {
+	return send(ctx, s.apiClient, s.fileManager, http.MethodPut, url, policy)
+}
@@ -0,0 +1,388 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/pkg/cryptoutil"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Имена ключей в бакете users локальной BoltDB.
+const (
+	keyUserKey    = "userKey"
+	keyPrivateKey = "privateKey"
+	keyWrap       = "keyWrap"
+	keySession    = "sessionKey"
+)
+
+// SessionFile — файл сессии разблокировки в каталоге времени выполнения.
+const SessionFile = "session"
+
+// DefaultAutoLock — время бездействия, после которого хранилище блокируется.
+const DefaultAutoLock = 15 * time.Minute
+
+// Параметры scrypt для ключа из парольной фразы (как у паролей на сервере,
+// но с N = 2^15: ключ выводится при каждой разблокировке на клиенте).
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+var ErrLocked = errors.New("local vault is locked, run \"gophkeeper unlock\"")
+var ErrNotProtected = errors.New("local vault is not protected by a passphrase, run \"gophkeeper unlock\" to set one")
+var ErrNoKeys = errors.New("no local keys, run \"gophkeeper user login\"")
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+// keyWrapParams — параметры вывода ключа шифрования ключей (KEK) из парольной фразы.
+type keyWrapParams struct {
+	KDF  string `json:"kdf"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt []byte `json:"salt"`
+}
+
+// session — содержимое файла сессии. Key расшифровывает KEK, сохранённый
+// в BoltDB на время сессии, поэтому ни файл сессии, ни data.db по
+// отдельности не раскрывают ключи.
+type session struct {
+	Key       []byte        `json:"key"`
+	Timeout   time.Duration `json:"timeout"`
+	ExpiresAt time.Time     `json:"expires_at,omitempty"`
+}
+
+// KeyringStatus — состояние локальных ключей.
+type KeyringStatus struct {
+	HasKeys   bool
+	Protected bool
+	Unlocked  bool
+	ExpiresAt time.Time
+	// SessionDir — каталог файла сессии, если он не в tmpfs и ключ сессии
+	// попадает на диск. Пуст, если сессия хранится в XDG_RUNTIME_DIR или в
+	// памяти агента.
+	SessionDir string
+}
+
+// Keyring хранит user-key и закрытый ключ пользователя в локальной BoltDB,
+// зашифрованными ключом (KEK), производным от локальной парольной фразы
+// (scrypt). После разблокировки KEK сохраняется в BoltDB зашифрованным
+// случайным ключом сессии, а сам ключ сессии — в файле сессии в
+// XDG_RUNTIME_DIR (без него — во временном каталоге системы). Сессия
+// продлевается при каждом обращении к ключам и истекает после таймаута
+// бездействия.
+type Keyring struct {
+	boltDB     KeyRepository
+	session    FileManager
+	sessionDir string
+	now        func() time.Time
+}
+
+func NewKeyring(boltDB KeyRepository, session FileManager) *Keyring {
+	return &Keyring{boltDB: boltDB, session: session, now: time.Now}
+}
+
+// SessionOnDisk отмечает, что файл сессии хранится в каталоге dir вне tmpfs.
+// Status сообщает его в KeyringStatus.SessionDir, чтобы команды
+// предупредили пользователя.
+func (k *Keyring) SessionOnDisk(dir string) {
+	k.sessionDir = dir
+}
+
+// Protect сохраняет ключи, полученные при входе (base64), зашифрованными
+// парольной фразой, и сразу открывает сессию с таймаутом timeout.
+func (k *Keyring) Protect(passphrase []byte, userKey, privateKey string, timeout time.Duration) error {
+	rawUserKey, err := base64.StdEncoding.DecodeString(userKey)
+	if err != nil {
+		return fmt.Errorf("decode key before save: %w", err)
+	}
+	var rawPrivateKey []byte
+	if privateKey != "" {
+		rawPrivateKey, err = base64.StdEncoding.DecodeString(privateKey)
+		if err != nil {
+			return fmt.Errorf("decode private key before save: %w", err)
+		}
+	}
+	return k.protect(passphrase, rawUserKey, rawPrivateKey, timeout)
+}
+
+// SetPassphrase защищает парольной фразой ключи хранилища, сохранённого
+// до появления защиты, и открывает сессию.
+func (k *Keyring) SetPassphrase(passphrase []byte, timeout time.Duration) error {
+	status, err := k.Status()
+	if err != nil {
+		return err
+	}
+	if !status.HasKeys {
+		return ErrNoKeys
+	}
+	if status.Protected {
+		return errors.New("local vault is already protected by a passphrase")
+	}
+
+	userKey, err := k.boltDB.GetKey(keyUserKey)
+	if err != nil {
+		return err
+	}
+	privateKey, err := k.boltDB.GetKey(keyPrivateKey)
+	if err != nil {
+		return err
+	}
+	return k.protect(passphrase, userKey, privateKey, timeout)
+}
+
+func (k *Keyring) protect(passphrase, userKey, privateKey []byte, timeout time.Duration) error {
+	if len(passphrase) == 0 {
+		return errors.New("passphrase must not be empty")
+	}
+	salt, err := cryptoutil.GenerateRandom(16)
+	if err != nil {
+		return err
+	}
+	params := keyWrapParams{KDF: "scrypt", N: scryptN, R: scryptR, P: scryptP, Salt: salt}
+	kek, err := params.derive(passphrase)
+	if err != nil {
+		return err
+	}
+
+	wrappedUserKey, err := cryptoutil.Encrypt(userKey, kek)
+	if err != nil {
+		return err
+	}
+	// закрытый ключ прежнего пользователя, зашифрованный прежним KEK,
+	// удаляется вместе с записью новых ключей
+	var wrappedPrivateKey []byte
+	if privateKey != nil {
+		wrappedPrivateKey, err = cryptoutil.Encrypt(privateKey, kek)
+		if err != nil {
+			return err
+		}
+	}
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	// ключи и параметры KEK записываются одной транзакцией: иначе сбой
+	// между записями оставил бы ключи, которые нельзя расшифровать
+	err = k.boltDB.PutKeys(map[string][]byte{
+		keyUserKey:    wrappedUserKey,
+		keyPrivateKey: wrappedPrivateKey,
+		keyWrap:       rawParams,
+	})
+	if err != nil {
+		return err
+	}
+	return k.openSession(kek, timeout)
+}
+
+// Unlock проверяет парольную фразу и открывает сессию. timeout = 0 —
+// сессия без автоблокировки (до lock, logout или перезагрузки).
+func (k *Keyring) Unlock(passphrase []byte, timeout time.Duration) error {
+	params, err := k.params()
+	if err != nil {
+		return err
+	}
+	kek, err := params.derive(passphrase)
+	if err != nil {
+		return err
+	}
+
+	wrappedUserKey, err := k.boltDB.GetKey(keyUserKey)
+	if err != nil {
+		return err
+	}
+	if _, err := cryptoutil.Decrypt(wrappedUserKey, kek); err != nil {
+		return ErrWrongPassphrase
+	}
+	return k.openSession(kek, timeout)
+}
+
+// Lock закрывает сессию: удаляет файл сессии и KEK, зашифрованный её ключом.
+func (k *Keyring) Lock() error {
+	if err := k.session.RemoveFile(SessionFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return k.boltDB.DeleteKey(keySession)
+}
+
+// Status возвращает состояние ключей без продления сессии.
+func (k *Keyring) Status() (KeyringStatus, error) {
+	var status KeyringStatus
+	userKey, err := k.boltDB.GetKey(keyUserKey)
+	if err != nil {
+		return status, err
+	}
+	params, err := k.boltDB.GetKey(keyWrap)
+	if err != nil {
+		return status, err
+	}
+	status.HasKeys = userKey != nil
+	status.Protected = params != nil
+	status.SessionDir = k.sessionDir
+
+	if s, err := k.loadSession(); err == nil {
+		status.Unlocked = true
+		status.ExpiresAt = s.ExpiresAt
+	}
+	return status, nil
+}
+
+// Expire закрывает сессию, если истёк её таймаут, и удаляет файл сессии.
+// Без этого истёкшая сессия закрывается только при следующем обращении.
+func (k *Keyring) Expire() error {
+	if _, err := k.loadSession(); err != nil && !errors.Is(err, ErrLocked) {
+		return err
+	}
+	return nil
+}
+
+// UserKey возвращает user-key открытой сессии и продлевает её.
+func (k *Keyring) UserKey() ([]byte, error) {
+	return k.open(keyUserKey)
+}
+
+// PrivateKey возвращает закрытый ключ пользователя открытой сессии.
+func (k *Keyring) PrivateKey() ([]byte, error) {
+	return k.open(keyPrivateKey)
+}
+
+func (k *Keyring) open(name string) ([]byte, error) {
+	wrapped, err := k.boltDB.GetKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if wrapped == nil {
+		if name == keyPrivateKey {
+			return nil, errors.New("private key not found")
+		}
+		return nil, ErrNoKeys
+	}
+	if params, err := k.boltDB.GetKey(keyWrap); err != nil {
+		return nil, err
+	} else if params == nil {
+		return nil, ErrNotProtected
+	}
+
+	kek, err := k.sessionKEK()
+	if err != nil {
+		return nil, err
+	}
+	key, err := cryptoutil.Decrypt(wrapped, kek)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt %s: %w", name, err)
+	}
+	return key, nil
+}
+
+// sessionKEK возвращает KEK открытой сессии и продлевает её на таймаут.
+func (k *Keyring) sessionKEK() ([]byte, error) {
+	s, err := k.loadSession()
+	if err != nil {
+		return nil, err
+	}
+	wrappedKEK, err := k.boltDB.GetKey(keySession)
+	if err != nil {
+		return nil, err
+	}
+	if wrappedKEK == nil {
+		return nil, ErrLocked
+	}
+	kek, err := cryptoutil.Decrypt(wrappedKEK, s.Key)
+	if err != nil {
+		// файл сессии от другой разблокировки
+		return nil, ErrLocked
+	}
+
+	if s.Timeout > 0 {
+		s.ExpiresAt = k.now().Add(s.Timeout)
+		if err := k.saveSession(s); err != nil {
+			return nil, err
+		}
+	}
+	return kek, nil
+}
+
+func (k *Keyring) openSession(kek []byte, timeout time.Duration) error {
+	key, err := cryptoutil.GenerateRandom(32)
+	if err != nil {
+		return err
+	}
+	wrappedKEK, err := cryptoutil.Encrypt(kek, key)
+	if err != nil {
+		return err
+	}
+	if err := k.boltDB.PutKey(keySession, wrappedKEK); err != nil {
+		return err
+	}
+
+	s := session{Key: key, Timeout: timeout}
+	if timeout > 0 {
+		s.ExpiresAt = k.now().Add(timeout)
+	}
+	return k.saveSession(s)
+}
+
+// loadSession читает файл сессии. Истёкшая сессия закрывается.
+func (k *Keyring) loadSession() (session, error) {
+	raw, err := k.session.LoadFile(SessionFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return session{}, ErrLocked
+	}
+	if err != nil {
+		return session{}, err
+	}
+	var s session
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		return session{}, fmt.Errorf("read session: %w", err)
+	}
+	if s.Timeout > 0 && !k.now().Before(s.ExpiresAt) {
+		if err := k.Lock(); err != nil {
+			return session{}, err
+		}
+		return session{}, fmt.Errorf("%w (auto-locked after %s of inactivity)", ErrLocked, s.Timeout)
+	}
+	return s, nil
+}
+
+func (k *Keyring) saveSession(s session) error {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return k.session.SaveFile(SessionFile, string(raw), 0600)
+}
+
+func (k *Keyring) params() (keyWrapParams, error) {
+	raw, err := k.boltDB.GetKey(keyWrap)
+	if err != nil {
+		return keyWrapParams{}, err
+	}
+	if raw == nil {
+		userKey, err := k.boltDB.GetKey(keyUserKey)
+		if err != nil {
+			return keyWrapParams{}, err
+		}
+		if userKey == nil {
+			return keyWrapParams{}, ErrNoKeys
+		}
+		return keyWrapParams{}, ErrNotProtected
+	}
+	var params keyWrapParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return keyWrapParams{}, fmt.Errorf("read key parameters: %w", err)
+	}
+	return params, nil
+}
+
+func (p keyWrapParams) derive(passphrase []byte) ([]byte, error) {
+	if p.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported key derivation %q", p.KDF)
+	}
+	return scrypt.Key(passphrase, p.Salt, p.N, p.R, p.P, scryptKeyLen)
+}
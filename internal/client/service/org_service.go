@@ -0,0 +1,77 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/models"
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// OrgService выполняет запросы к API организаций.
+type OrgService struct {
+	apiClient   ApiClient
+	fileManager FileManager
+}
+
+func NewOrgService(apiClient ApiClient, fileManager FileManager) *OrgService {
+	return &OrgService{
+		apiClient:   apiClient,
+		fileManager: fileManager,
+	}
+}
+
+func (s *OrgService) Create(ctx context.Context, url string, input model.OrgInput) (*models.Response, error) {
+	return send(ctx, s.apiClient, s.fileManager, http.MethodPost, url, input)
+}
+
+func (s *OrgService) Invite(ctx context.Context, url string, input model.InviteInput) (*models.Response, error) {
+	return send(ctx, s.apiClient, s.fileManager, http.MethodPost, url, input)
+}
+
+func (s *OrgService) CreateCollection(ctx context.Context, url string, input model.CollectionInput) (*models.Response, error) {
+	return send(ctx, s.apiClient, s.fileManager, http.MethodPost, url, input)
+}
+
+func (s *OrgService) Get(ctx context.Context, url string) (*models.Response, error) {
+	return send(ctx, s.apiClient, s.fileManager, http.MethodGet, url, nil)
+}
+
+// send выполняет авторизованный запрос; body, если задан, передаётся в формате JSON.
+func send(ctx context.Context, apiClient ApiClient, fileManager FileManager, method, url string, body any) (*models.Response, error) {
+	token, err := fileManager.LoadFile("token")
+	if err != nil {
+		return nil, fmt.Errorf("failed read token: %w", err)
+	}
+
+	var bodyReader io.Reader = bytes.NewBuffer([]byte{})
+	if body != nil {
+		reqBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed marshal batch: %w", err)
+		}
+		bodyReader = bytes.NewBuffer(reqBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	cookie := &http.Cookie{
+		Name:  "auth_token",
+		Value: token,
+		Path:  "/",
+	}
+	req.AddCookie(cookie)
+	resp, err := apiClient.Do(req)
+
+	if err != nil {
+		return nil, fmt.Errorf("response error: %w", err)
+	}
+
+	return resp, nil
+}
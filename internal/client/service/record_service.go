@@ -3,14 +3,18 @@ package service
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"time"
 
 	"github.com/fatkulllin/gophkeeper/internal/client/models"
 	"github.com/fatkulllin/gophkeeper/model"
 	"github.com/fatkulllin/gophkeeper/pkg/cryptoutil"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/fatkulllin/gophkeeper/pkg/totp"
 	"go.uber.org/zap"
 )
 
@@ -18,13 +22,15 @@ type RecordService struct {
 	apiClient   ApiClient
 	fileManager FileManager
 	boltDB      Repository
+	keyring     KeyStore
 }
 
-func NewRecordService(apiClient ApiClient, fileManager FileManager, boltDB Repository) *RecordService {
+func NewRecordService(apiClient ApiClient, fileManager FileManager, boltDB Repository, keyring KeyStore) *RecordService {
 	return &RecordService{
 		apiClient:   apiClient,
 		fileManager: fileManager,
 		boltDB:      boltDB,
+		keyring:     keyring,
 	}
 }
 
@@ -94,26 +100,55 @@ func (s *RecordService) GetLocal(ctx context.Context, id int64) (model.RecordRes
 		return model.RecordResponse{}, err
 	}
 
-	userKey, err := s.boltDB.GetUserKey()
+	userKey, err := s.keyring.UserKey()
 
 	if err != nil {
 		logger.Log.Error("", zap.Error(err))
 		return model.RecordResponse{}, err
 	}
-	decryptData, err := cryptoutil.Decrypt(record.Data, userKey)
 
+	return s.decryptRecord(record, userKey)
+}
+
+// decryptRecord расшифровывает данные записи. Записи с собственным ключом
+// (общие записи) расшифровываются ключом, полученным с помощью закрытого
+// ключа пользователя, остальные — user-key.
+func (s *RecordService) decryptRecord(record model.Record, userKey []byte) (model.RecordResponse, error) {
+	key := userKey
+
+	if record.DataKey != "" {
+		wrappedKey, err := base64.StdEncoding.DecodeString(record.DataKey)
+		if err != nil {
+			return model.RecordResponse{}, fmt.Errorf("decode record key: %w", err)
+		}
+		privateKey, err := s.keyring.PrivateKey()
+		if err != nil {
+			return model.RecordResponse{}, err
+		}
+		key, err = cryptoutil.Open(wrappedKey, privateKey)
+		if err != nil {
+			return model.RecordResponse{}, fmt.Errorf("open record key: %w", err)
+		}
+	}
+
+	decryptData, err := cryptoutil.Decrypt(record.Data, key)
 	if err != nil {
 		logger.Log.Error("", zap.Error(err))
 		return model.RecordResponse{}, err
 	}
 
 	return model.RecordResponse{
-		ID:       record.ID,
-		Type:     record.Type,
-		Metadata: record.Metadata,
-		Data:     decryptData,
+		ID:           record.ID,
+		CollectionID: record.CollectionID,
+		OrgID:        record.OrgID,
+		Type:         record.Type,
+		Metadata:     record.Metadata,
+		Data:         decryptData,
+		Permission:   record.Permission,
+		UpdatedAt:    record.UpdatedAt,
+		ExpiresAt:    record.ExpiresAt,
+		Ephemeral:    record.Ephemeral,
 	}, nil
-
 }
 
 func (s *RecordService) Delete(ctx context.Context, url string) (*models.Response, error) {
@@ -177,6 +212,37 @@ func (s *RecordService) Update(ctx context.Context, url string, input model.Reco
 	return resp, nil
 }
 
+func (s *RecordService) Share(ctx context.Context, url string, input model.ShareInput) (*models.Response, error) {
+
+	token, err := s.fileManager.LoadFile("token")
+	if err != nil {
+		return nil, fmt.Errorf("failed read token: %w", err)
+	}
+	reqBody, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed marshal batch: %w", err)
+	}
+	bodyReader := bytes.NewBuffer(reqBody)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	cookie := &http.Cookie{
+		Name:  "auth_token",
+		Value: token,
+		Path:  "/",
+	}
+	req.AddCookie(cookie)
+	resp, err := s.apiClient.Do(req)
+
+	if err != nil {
+		return nil, fmt.Errorf("response error: %w", err)
+	}
+
+	return resp, nil
+}
+
 func (s *RecordService) SaveRecords(records []model.Record) error {
 	if err := s.boltDB.SaveRecords(records); err != nil {
 		return err
@@ -184,7 +250,9 @@ func (s *RecordService) SaveRecords(records []model.Record) error {
 	return nil
 }
 
-func (s *RecordService) GetAll() ([]model.RecordResponse, error) {
+// GetAll возвращает расшифрованные локальные записи. При orgID = 0 возвращаются
+// личные и общие записи, иначе — записи коллекций указанной организации.
+func (s *RecordService) GetAll(orgID int) ([]model.RecordResponse, error) {
 
 	recordsOutput := make([]model.RecordResponse, 0)
 
@@ -195,7 +263,7 @@ func (s *RecordService) GetAll() ([]model.RecordResponse, error) {
 		return nil, err
 	}
 
-	userKey, err := s.boltDB.GetUserKey()
+	userKey, err := s.keyring.UserKey()
 
 	if err != nil {
 		logger.Log.Error("", zap.Error(err))
@@ -203,20 +271,91 @@ func (s *RecordService) GetAll() ([]model.RecordResponse, error) {
 	}
 
 	for _, rec := range records {
-		decryptData, err := cryptoutil.Decrypt(rec.Data, userKey)
+		if rec.OrgID != orgID {
+			continue
+		}
+		record, err := s.decryptRecord(rec, userKey)
 		if err != nil {
-			logger.Log.Error("", zap.Error(err))
 			return nil, err
 		}
-		record := model.RecordResponse{
-			ID:       rec.ID,
-			Type:     rec.Type,
-			Metadata: rec.Metadata,
-			Data:     decryptData,
-		}
 
 		recordsOutput = append(recordsOutput, record)
 	}
 
 	return recordsOutput, nil
 }
+
+// OTP вычисляет текущий TOTP-код записи login_password по локальной копии
+// и возвращает его вместе со временем, оставшимся до смены кода.
+func (s *RecordService) OTP(ctx context.Context, id int64, now time.Time) (string, time.Duration, error) {
+	record, err := s.GetLocal(ctx, id)
+	if err != nil {
+		return "", 0, err
+	}
+	if record.Type != model.TypeLoginPassword {
+		return "", 0, fmt.Errorf("record %d is %s, TOTP is supported only for %s records", id, record.Type, model.TypeLoginPassword)
+	}
+
+	var data model.LoginPassword
+	if err := json.Unmarshal(record.Data, &data); err != nil {
+		return "", 0, fmt.Errorf("decode record data: %w", err)
+	}
+	if data.TOTP == "" {
+		return "", 0, fmt.Errorf("record %d has no TOTP secret", id)
+	}
+
+	key, err := totp.Parse(data.TOTP)
+	if err != nil {
+		return "", 0, err
+	}
+	return key.Code(now)
+}
+
+// Field возвращает значение поля данных локальной записи. Строки
+// возвращаются как есть, остальные значения — в виде JSON.
+func (s *RecordService) Field(ctx context.Context, id int64, name string) (string, error) {
+	record, err := s.GetLocal(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return dataField(record, name)
+}
+
+// dataField возвращает значение поля данных расшифрованной записи.
+func dataField(record model.RecordResponse, name string) (string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(record.Data, &fields); err != nil {
+		return "", fmt.Errorf("record %d data is not a JSON object: %w", record.ID, err)
+	}
+	raw, ok := fields[name]
+	if !ok {
+		return "", fmt.Errorf("record %d has no field %q", record.ID, name)
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return string(raw), nil
+	}
+	return value, nil
+}
+
+// Expiring возвращает локальные записи, срок действия которых истекает
+// не позже чем через within (включая истёкшие), в порядке истечения.
+func (s *RecordService) Expiring(orgID int, within time.Duration, now time.Time) ([]model.RecordResponse, error) {
+	records, err := s.GetAll(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := now.Add(within)
+	expiring := make([]model.RecordResponse, 0)
+	for _, r := range records {
+		if r.ExpiresAt != nil && !r.ExpiresAt.After(deadline) {
+			expiring = append(expiring, r)
+		}
+	}
+	sort.Slice(expiring, func(i, j int) bool {
+		return expiring[i].ExpiresAt.Before(*expiring[j].ExpiresAt)
+	})
+	return expiring, nil
+}
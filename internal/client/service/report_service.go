@@ -0,0 +1,221 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/breach"
+	"github.com/fatkulllin/gophkeeper/pkg/passgen"
+)
+
+// Issue — вид проблемы, найденной в записи.
+type Issue string
+
+const (
+	IssueBreached     Issue = "breached"
+	IssueWeak         Issue = "weak"
+	IssueReused       Issue = "reused"
+	IssueOld          Issue = "old"
+	IssueCardExpired  Issue = "card_expired"
+	IssueCardExpiring Issue = "card_expiring"
+	IssueInvalidData  Issue = "invalid_data"
+)
+
+// issuePenalty — доля, на которую проблема снижает оценку записи.
+var issuePenalty = map[Issue]float64{
+	IssueBreached:     1,
+	IssueWeak:         0.5,
+	IssueReused:       0.5,
+	IssueOld:          0.25,
+	IssueCardExpired:  0.5,
+	IssueCardExpiring: 0.25,
+	IssueInvalidData:  0.25,
+}
+
+// ReportOptions — пороги проверки хранилища.
+type ReportOptions struct {
+	OrgID int
+	// MinEntropy — минимальная оценка энтропии пароля в битах.
+	MinEntropy float64
+	// MaxAge — срок, после которого пароль считается старым; 0 отключает проверку.
+	MaxAge time.Duration
+	// CardWarning — за сколько до окончания срока действия карты предупреждать.
+	CardWarning time.Duration
+	// Breach — база утёкших паролей; nil отключает проверку.
+	Breach breach.Source
+	Now    time.Time
+}
+
+// Finding — проблема записи.
+type Finding struct {
+	RecordID int64            `json:"record_id"`
+	Type     model.RecordType `json:"type"`
+	Metadata string           `json:"metadata,omitempty"`
+	Issue    Issue            `json:"issue"`
+	Detail   string           `json:"detail"`
+}
+
+// Report — результат проверки хранилища. Score — оценка гигиены от 0 до 100:
+// среднее по проверенным записям, где каждая запись начинает со 100% и теряет
+// долю issuePenalty за каждую проблему.
+type Report struct {
+	Score    int       `json:"score"`
+	Checked  int       `json:"checked"`
+	Findings []Finding `json:"findings"`
+}
+
+type ReportService struct {
+	records *RecordService
+}
+
+func NewReportService(records *RecordService) *ReportService {
+	return &ReportService{records: records}
+}
+
+// Build расшифровывает локальные записи и проверяет пароли записей
+// login_password и сроки действия карт bank_card.
+func (s *ReportService) Build(opts ReportOptions) (Report, error) {
+	records, err := s.records.GetAll(opts.OrgID)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{Findings: make([]Finding, 0)}
+	passwords := make(map[int64]string)
+	checked := make([]model.RecordResponse, 0, len(records))
+	add := func(r model.RecordResponse, issue Issue, detail string) {
+		report.Findings = append(report.Findings, Finding{
+			RecordID: r.ID, Type: r.Type, Metadata: r.Metadata, Issue: issue, Detail: detail,
+		})
+	}
+
+	for _, r := range records {
+		switch r.Type {
+		case model.TypeLoginPassword:
+			checked = append(checked, r)
+			var data model.LoginPassword
+			if err := json.Unmarshal(r.Data, &data); err != nil || data.Password == "" {
+				add(r, IssueInvalidData, "no password field")
+				continue
+			}
+			passwords[r.ID] = data.Password
+
+			if bits := passgen.Estimate(data.Password); bits < opts.MinEntropy {
+				add(r, IssueWeak, fmt.Sprintf("estimated entropy %.0f bits, want at least %.0f", bits, opts.MinEntropy))
+			}
+			// у записей, созданных до появления password_changed_at, возраст
+			// пароля считается по времени изменения записи
+			changedAt := r.UpdatedAt
+			if data.PasswordChangedAt != nil {
+				changedAt = *data.PasswordChangedAt
+			}
+			if opts.MaxAge > 0 && !changedAt.IsZero() && opts.Now.Sub(changedAt) > opts.MaxAge {
+				add(r, IssueOld, fmt.Sprintf("unchanged for %d days", int(opts.Now.Sub(changedAt).Hours()/24)))
+			}
+		case model.TypeBankCard:
+			checked = append(checked, r)
+			var data model.BankCard
+			if err := json.Unmarshal(r.Data, &data); err != nil || data.Expiry == "" {
+				add(r, IssueInvalidData, "no expiry field")
+				continue
+			}
+			expires, err := cardExpiry(data.Expiry)
+			if err != nil {
+				add(r, IssueInvalidData, err.Error())
+				continue
+			}
+			switch left := expires.Sub(opts.Now); {
+			case left <= 0:
+				add(r, IssueCardExpired, "expired "+expires.AddDate(0, 0, -1).Format("01/2006"))
+			case left <= opts.CardWarning:
+				add(r, IssueCardExpiring, fmt.Sprintf("expires in %d days", int(math.Ceil(left.Hours()/24))))
+			}
+		}
+	}
+
+	reused := make(map[string][]int64)
+	for id, password := range passwords {
+		reused[password] = append(reused[password], id)
+	}
+	for _, r := range checked {
+		password, ok := passwords[r.ID]
+		ids := reused[password]
+		if !ok || len(ids) < 2 {
+			continue
+		}
+		others := make([]string, 0, len(ids)-1)
+		for _, id := range sortedIDs(ids) {
+			if id != r.ID {
+				others = append(others, strconv.FormatInt(id, 10))
+			}
+		}
+		add(r, IssueReused, "same password as record "+strings.Join(others, ", "))
+	}
+
+	if opts.Breach != nil && len(passwords) > 0 {
+		hashes := make([]string, 0, len(passwords))
+		for _, password := range passwords {
+			hashes = append(hashes, breach.Hash(password))
+		}
+		counts, err := opts.Breach.Counts(hashes)
+		if err != nil {
+			return Report{}, err
+		}
+		for _, r := range checked {
+			password, ok := passwords[r.ID]
+			if !ok {
+				continue
+			}
+			if count, found := counts[breach.Hash(password)]; found {
+				add(r, IssueBreached, fmt.Sprintf("found in breaches %d times", count))
+			}
+		}
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return report.Findings[i].RecordID < report.Findings[j].RecordID
+	})
+	report.Checked = len(checked)
+	report.Score = score(checked, report.Findings)
+	return report, nil
+}
+
+// score вычисляет оценку гигиены по найденным проблемам.
+func score(checked []model.RecordResponse, findings []Finding) int {
+	if len(checked) == 0 {
+		return 100
+	}
+	penalty := make(map[int64]float64)
+	for _, f := range findings {
+		penalty[f.RecordID] += issuePenalty[f.Issue]
+	}
+	var total float64
+	for _, r := range checked {
+		total += math.Max(0, 1-penalty[r.ID])
+	}
+	return int(math.Round(100 * total / float64(len(checked))))
+}
+
+func sortedIDs(ids []int64) []int64 {
+	sorted := append([]int64(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// cardExpiry возвращает момент окончания срока действия карты: карта
+// действует до конца указанного месяца включительно.
+func cardExpiry(expiry string) (time.Time, error) {
+	expiry = strings.TrimSpace(expiry)
+	for _, layout := range []string{"01/06", "1/06", "01/2006", "1/2006", "2006-01"} {
+		if t, err := time.ParseInLocation(layout, expiry, time.Local); err == nil {
+			return t.AddDate(0, 1, 0), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid expiry %q, want MM/YY", expiry)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// RefScheme — префикс ссылки на секрет вида gk://<запись>/<поле>. Запись
+// задаётся идентификатором или метаданными (именем) записи.
+const RefScheme = "gk://"
+
+// IsRef сообщает, является ли value ссылкой на секрет.
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, RefScheme)
+}
+
+// ParseRef разбирает ссылку gk://<запись>/<поле>. Части ссылки могут быть
+// закодированы как в URL (например, %2F для косой черты в имени).
+func ParseRef(ref string) (record, field string, err error) {
+	rest, ok := strings.CutPrefix(ref, RefScheme)
+	if !ok {
+		return "", "", fmt.Errorf("invalid secret reference %q: must start with %s", ref, RefScheme)
+	}
+	i := strings.LastIndex(rest, "/")
+	if i <= 0 || i == len(rest)-1 {
+		return "", "", fmt.Errorf("invalid secret reference %q: want %s<record>/<field>", ref, RefScheme)
+	}
+	if record, err = url.PathUnescape(rest[:i]); err != nil {
+		return "", "", fmt.Errorf("invalid secret reference %q: %w", ref, err)
+	}
+	if field, err = url.PathUnescape(rest[i+1:]); err != nil {
+		return "", "", fmt.Errorf("invalid secret reference %q: %w", ref, err)
+	}
+	return record, field, nil
+}
+
+// Secrets — расшифрованные записи, по которым разрешаются ссылки на секреты.
+type Secrets struct {
+	records []model.RecordResponse
+}
+
+// NewSecrets возвращает набор секретов из расшифрованных записей.
+func NewSecrets(records []model.RecordResponse) *Secrets {
+	return &Secrets{records: records}
+}
+
+// Lookup возвращает значение поля field записи record. Запись ищется по
+// идентификатору, если record — число, иначе по метаданным; имя должно
+// быть однозначным.
+func (s *Secrets) Lookup(record, field string) (string, error) {
+	if id, err := strconv.ParseInt(record, 10, 64); err == nil {
+		for _, r := range s.records {
+			if r.ID == id {
+				return dataField(r, field)
+			}
+		}
+		return "", fmt.Errorf("record %d: %w", id, model.ErrRecordNotFound)
+	}
+
+	var found []model.RecordResponse
+	for _, r := range s.records {
+		if r.Metadata == record {
+			found = append(found, r)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("record %q: %w", record, model.ErrRecordNotFound)
+	case 1:
+		return dataField(found[0], field)
+	default:
+		return "", fmt.Errorf("%d records are named %q, refer to one of them by id", len(found), record)
+	}
+}
+
+// Resolve возвращает значение, на которое указывает ссылка ref.
+func (s *Secrets) Resolve(ref string) (string, error) {
+	record, field, err := ParseRef(ref)
+	if err != nil {
+		return "", err
+	}
+	return s.Lookup(record, field)
+}
+
+// LocalSecrets расшифровывает все локальные записи, включая записи
+// организаций.
+func (s *RecordService) LocalSecrets() (*Secrets, error) {
+	records, err := s.boltDB.All()
+	if err != nil {
+		return nil, err
+	}
+	return s.Secrets(records)
+}
+
+// Secrets расшифровывает записи records, например загруженные с сервера.
+func (s *RecordService) Secrets(records []model.Record) (*Secrets, error) {
+	userKey, err := s.keyring.UserKey()
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted := make([]model.RecordResponse, 0, len(records))
+	for _, rec := range records {
+		record, err := s.decryptRecord(rec, userKey)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt record %d: %w", rec.ID, err)
+		}
+		decrypted = append(decrypted, record)
+	}
+	return NewSecrets(decrypted), nil
+}
@@ -3,14 +3,23 @@ package service
 import (
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/fatkulllin/gophkeeper/internal/client/models"
 	"github.com/fatkulllin/gophkeeper/model"
 )
 
 type Service struct {
-	User   *UserService
-	Record *RecordService
+	User       *UserService
+	Record     *RecordService
+	Org        *OrgService
+	Audit      *AuditService
+	Device     *DeviceService
+	Report     *ReportService
+	VaultToken *VaultTokenService
+	Keyring    KeyStore
+	// Store — локальная копия записей: BoltDB или запущенный агент.
+	Store Repository
 }
 
 type ApiClient interface {
@@ -24,17 +33,47 @@ type FileManager interface {
 }
 
 type Repository interface {
-	PutUserKey(userKey string) error
-	GetUserKey() ([]byte, error)
 	SaveRecords(records []model.Record) error
 	Clear() error
 	All() ([]model.Record, error)
 	Get(id int64) (model.Record, error)
+	Delete(id int64) error
 }
 
-func NewService(apiClient ApiClient, fileManager FileManager, boltDB Repository) *Service {
+// KeyRepository хранит зашифрованные ключи пользователя (бакет users BoltDB).
+type KeyRepository interface {
+	PutKey(name string, value []byte) error
+	PutKeys(values map[string][]byte) error
+	GetKey(name string) ([]byte, error)
+	DeleteKey(name string) error
+}
+
+// KeyStore выдаёт ключи расшифровки записей и управляет блокировкой
+// хранилища. Реализуется Keyring (ключи в локальной BoltDB) и клиентом
+// агента (ключи в памяти gophkeeper agent).
+type KeyStore interface {
+	Protect(passphrase []byte, userKey, privateKey string, timeout time.Duration) error
+	SetPassphrase(passphrase []byte, timeout time.Duration) error
+	Unlock(passphrase []byte, timeout time.Duration) error
+	Lock() error
+	Status() (KeyringStatus, error)
+	UserKey() ([]byte, error)
+	PrivateKey() ([]byte, error)
+}
+
+// NewService собирает сервисы клиента поверх локальной копии записей boltDB
+// и хранилища ключей keyring.
+func NewService(apiClient ApiClient, fileManager FileManager, boltDB Repository, keyring KeyStore) *Service {
+	records := NewRecordService(apiClient, fileManager, boltDB, keyring)
 	return &Service{
-		User:   NewUserService(apiClient, fileManager, boltDB),
-		Record: NewRecordService(apiClient, fileManager, boltDB),
+		User:       NewUserService(apiClient, fileManager, boltDB, keyring),
+		Record:     records,
+		Org:        NewOrgService(apiClient, fileManager),
+		Audit:      NewAuditService(apiClient, fileManager),
+		Device:     NewDeviceService(apiClient, fileManager),
+		Report:     NewReportService(records),
+		VaultToken: NewVaultTokenService(apiClient, fileManager),
+		Keyring:    keyring,
+		Store:      boltDB,
 	}
 }
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/fatkulllin/gophkeeper/pkg/sshkey"
+	"go.uber.org/zap"
+)
+
+// SSHKey — ключ из записи ssh_key локальной копии.
+type SSHKey struct {
+	model.SSHKey
+	RecordID int64
+	Name     string
+}
+
+// NewSSHKeyData возвращает данные записи ssh_key для закрытого ключа
+// privateKey в формате OpenSSH: открытый ключ и отпечаток вычисляются по нему.
+func NewSSHKeyData(privateKey []byte, comment string, confirm bool) (model.SSHKey, error) {
+	publicKey, fingerprint, err := sshkey.Describe(privateKey, comment)
+	if err != nil {
+		return model.SSHKey{}, err
+	}
+	return model.SSHKey{
+		PrivateKey:  string(privateKey),
+		PublicKey:   publicKey,
+		Comment:     comment,
+		Fingerprint: fingerprint,
+		Confirm:     confirm,
+	}, nil
+}
+
+// SSHKeys возвращает ключи из записей ssh_key локальной копии, включая
+// записи организаций. Записи с повреждёнными данными пропускаются.
+func (s *RecordService) SSHKeys() ([]SSHKey, error) {
+	records, err := s.boltDB.All()
+	if err != nil {
+		return nil, err
+	}
+
+	userKey, err := s.keyring.UserKey()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]SSHKey, 0)
+	for _, rec := range records {
+		if rec.Type != model.TypeSSHKey {
+			continue
+		}
+		record, err := s.decryptRecord(rec, userKey)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt record %d: %w", rec.ID, err)
+		}
+		var data model.SSHKey
+		if err := json.Unmarshal(record.Data, &data); err != nil || data.PrivateKey == "" {
+			logger.Log.Warn("skip invalid ssh_key record", zap.Int64("id", rec.ID), zap.Error(err))
+			continue
+		}
+		keys = append(keys, SSHKey{SSHKey: data, RecordID: rec.ID, Name: rec.Metadata})
+	}
+	return keys, nil
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/fatkulllin/gophkeeper/internal/client/models"
 )
@@ -16,13 +17,15 @@ type UserService struct {
 	apiClient   ApiClient
 	fileManager FileManager
 	boltDB      Repository
+	keyring     KeyStore
 }
 
-func NewUserService(apiClient ApiClient, fileManager FileManager, boltDB Repository) *UserService {
+func NewUserService(apiClient ApiClient, fileManager FileManager, boltDB Repository, keyring KeyStore) *UserService {
 	return &UserService{
 		apiClient:   apiClient,
 		fileManager: fileManager,
 		boltDB:      boltDB,
+		keyring:     keyring,
 	}
 }
 
@@ -84,15 +87,16 @@ func (s *UserService) RegisterUser(ctx context.Context, username, password, url
 	return resp, nil
 }
 
-func (s *UserService) SaveUserKey(userKey string) error {
-	err := s.boltDB.PutUserKey(userKey)
-	if err != nil {
-		return err
-	}
-	return nil
+// SaveKeys сохраняет ключи, полученные при входе, зашифрованными локальной
+// парольной фразой и разблокирует хранилище на timeout.
+func (s *UserService) SaveKeys(passphrase []byte, userKey, privateKey string, timeout time.Duration) error {
+	return s.keyring.Protect(passphrase, userKey, privateKey, timeout)
 }
 
 func (s *UserService) ClearDB() error {
+	if err := s.keyring.Lock(); err != nil {
+		return err
+	}
 	err := s.boltDB.Clear()
 	if err != nil {
 		return err
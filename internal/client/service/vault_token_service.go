@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/models"
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// VaultTokenService управляет токенами API Vault KV v2 пользователя.
+type VaultTokenService struct {
+	apiClient   ApiClient
+	fileManager FileManager
+}
+
+func NewVaultTokenService(apiClient ApiClient, fileManager FileManager) *VaultTokenService {
+	return &VaultTokenService{
+		apiClient:   apiClient,
+		fileManager: fileManager,
+	}
+}
+
+func (s *VaultTokenService) Create(ctx context.Context, url string, name string) (*models.Response, error) {
+	return send(ctx, s.apiClient, s.fileManager, http.MethodPost, url, model.VaultTokenInput{Name: name})
+}
+
+func (s *VaultTokenService) Get(ctx context.Context, url string) (*models.Response, error) {
+	return send(ctx, s.apiClient, s.fileManager, http.MethodGet, url, nil)
+}
+
+func (s *VaultTokenService) Revoke(ctx context.Context, url string) (*models.Response, error) {
+	return send(ctx, s.apiClient, s.fileManager, http.MethodDelete, url, nil)
+}
@@ -0,0 +1,53 @@
+package sshagent
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Askpass возвращает подтверждение программой program в стиле ssh-askpass:
+// программа получает вопрос аргументом и SSH_ASKPASS_PROMPT=confirm, нулевой
+// код завершения означает согласие.
+func Askpass(program string) ConfirmFunc {
+	return func(key service.SSHKey) bool {
+		cmd := exec.Command(program, prompt(key))
+		cmd.Env = append(os.Environ(), "SSH_ASKPASS_PROMPT=confirm")
+		if err := cmd.Run(); err != nil {
+			logger.Log.Debug("ssh-agent: askpass", zap.Error(err))
+			return false
+		}
+		return true
+	}
+}
+
+// Terminal возвращает подтверждение вопросом на управляющем терминале
+// агента.
+func Terminal() ConfirmFunc {
+	return func(key service.SSHKey) bool {
+		tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+		if err != nil {
+			logger.Log.Warn("ssh-agent: no terminal to confirm use of the key", zap.Error(err))
+			return false
+		}
+		defer tty.Close()
+
+		fmt.Fprintf(tty, "%s [y/N] ", prompt(key))
+		answer, err := bufio.NewReader(tty).ReadString('\n')
+		if err != nil {
+			return false
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		return answer == "y" || answer == "yes"
+	}
+}
+
+func prompt(key service.SSHKey) string {
+	return fmt.Sprintf("Allow use of SSH key %q (%s) from record %d?", key.Name, key.Fingerprint, key.RecordID)
+}
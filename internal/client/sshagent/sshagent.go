@@ -0,0 +1,257 @@
+// Пакет sshagent реализует протокол ssh-agent для ключей из записей ssh_key.
+// Ключи читаются из хранилища при каждом запросе, поэтому агент видит
+// изменения после синхронизации и не выдаёт ключи заблокированного хранилища.
+package sshagent
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/fatkulllin/gophkeeper/internal/client/service"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// SocketFile — имя сокета ssh-agent в runtime-каталоге профиля.
+const SocketFile = "ssh-agent.sock"
+
+var (
+	// ErrReadOnly возвращается на попытки изменить набор ключей через
+	// протокол ssh-agent (ssh-add).
+	ErrReadOnly = errors.New("keys are managed by gophkeeper, use gophkeeper ssh-key")
+	// ErrAgentLocked возвращается, пока агент заблокирован ssh-add -x.
+	ErrAgentLocked = errors.New("agent is locked")
+	// ErrKeyNotFound возвращается на запрос подписи неизвестным ключом.
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrDenied возвращается, если пользователь не подтвердил использование
+	// ключа.
+	ErrDenied = errors.New("use of the key was not confirmed")
+)
+
+// KeySource возвращает ключи хранилища.
+type KeySource func() ([]service.SSHKey, error)
+
+// ConfirmFunc спрашивает пользователя, разрешить ли использование ключа.
+type ConfirmFunc func(key service.SSHKey) bool
+
+// Options — настройки агента. Confirm вызывается для ключей с Confirm и,
+// при ConfirmAll, для всех ключей.
+type Options struct {
+	ConfirmAll bool
+	Confirm    ConfirmFunc
+}
+
+// Agent — агент SSH только для чтения: ключи добавляются командами
+// gophkeeper ssh-key, а не ssh-add.
+type Agent struct {
+	keys KeySource
+	opts Options
+
+	mu         sync.Mutex
+	passphrase []byte // не nil, пока агент заблокирован
+
+	// запросы подтверждения не должны перекрываться
+	confirmMu sync.Mutex
+}
+
+var _ agent.ExtendedAgent = (*Agent)(nil)
+
+// New возвращает агент с ключами из keys.
+func New(keys KeySource, opts Options) *Agent {
+	return &Agent{keys: keys, opts: opts}
+}
+
+// Serve обслуживает соединения ln, пока не отменён ctx.
+func (a *Agent) Serve(ctx context.Context, ln net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			if err := agent.ServeAgent(a, conn); err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				logger.Log.Debug("ssh-agent: connection closed", zap.Error(err))
+			}
+		}()
+	}
+}
+
+// List возвращает открытые ключи. Если хранилище заблокировано, список пуст,
+// чтобы ssh перешёл к другим способам аутентификации.
+func (a *Agent) List() ([]*agent.Key, error) {
+	if a.isLocked() {
+		return nil, nil
+	}
+	keys, err := a.load()
+	if err != nil {
+		logger.Log.Warn("ssh-agent: no keys", zap.Error(err))
+		return nil, nil
+	}
+
+	list := make([]*agent.Key, 0, len(keys))
+	for _, k := range keys {
+		public := k.signer.PublicKey()
+		list = append(list, &agent.Key{
+			Format:  public.Type(),
+			Blob:    public.Marshal(),
+			Comment: k.comment(),
+		})
+	}
+	return list, nil
+}
+
+// Sign подписывает data ключом key.
+func (a *Agent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	return a.SignWithFlags(key, data, 0)
+}
+
+// SignWithFlags подписывает data ключом key; флаги выбирают алгоритм
+// подписи RSA (rsa-sha2-256, rsa-sha2-512).
+func (a *Agent) SignWithFlags(key ssh.PublicKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	if a.isLocked() {
+		return nil, ErrAgentLocked
+	}
+	keys, err := a.load()
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := key.Marshal()
+	for _, k := range keys {
+		if !bytes.Equal(k.signer.PublicKey().Marshal(), wanted) {
+			continue
+		}
+		if (a.opts.ConfirmAll || k.Confirm) && !a.confirm(k.SSHKey) {
+			logger.Log.Info("ssh-agent: use of key denied", zap.Int64("record", k.RecordID))
+			return nil, ErrDenied
+		}
+		logger.Log.Info("ssh-agent: sign", zap.Int64("record", k.RecordID), zap.String("fingerprint", k.Fingerprint))
+		return sign(k.signer, data, flags)
+	}
+	return nil, ErrKeyNotFound
+}
+
+// Signers не поддерживается: подпись доступна только через Sign, где
+// проверяется подтверждение.
+func (a *Agent) Signers() ([]ssh.Signer, error) {
+	return nil, ErrReadOnly
+}
+
+// Add не поддерживается.
+func (a *Agent) Add(key agent.AddedKey) error { return ErrReadOnly }
+
+// Remove не поддерживается.
+func (a *Agent) Remove(key ssh.PublicKey) error { return ErrReadOnly }
+
+// RemoveAll не поддерживается.
+func (a *Agent) RemoveAll() error { return ErrReadOnly }
+
+// Lock блокирует агент парольной фразой (ssh-add -x).
+func (a *Agent) Lock(passphrase []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.passphrase != nil {
+		return ErrAgentLocked
+	}
+	a.passphrase = append([]byte{}, passphrase...)
+	return nil
+}
+
+// Unlock снимает блокировку агента (ssh-add -X).
+func (a *Agent) Unlock(passphrase []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.passphrase == nil {
+		return errors.New("agent is not locked")
+	}
+	if subtle.ConstantTimeCompare(a.passphrase, passphrase) != 1 {
+		return errors.New("incorrect passphrase")
+	}
+	a.passphrase = nil
+	return nil
+}
+
+// Extension не поддерживается.
+func (a *Agent) Extension(extensionType string, contents []byte) ([]byte, error) {
+	return nil, agent.ErrExtensionUnsupported
+}
+
+func (a *Agent) isLocked() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.passphrase != nil
+}
+
+func (a *Agent) confirm(key service.SSHKey) bool {
+	if a.opts.Confirm == nil {
+		return false
+	}
+	a.confirmMu.Lock()
+	defer a.confirmMu.Unlock()
+	return a.opts.Confirm(key)
+}
+
+type loadedKey struct {
+	service.SSHKey
+	signer ssh.Signer
+}
+
+func (k loadedKey) comment() string {
+	if k.Comment != "" {
+		return k.Comment
+	}
+	return k.Name
+}
+
+// load разбирает ключи хранилища; ключи, которые не удалось разобрать,
+// пропускаются.
+func (a *Agent) load() ([]loadedKey, error) {
+	keys, err := a.keys()
+	if err != nil {
+		return nil, err
+	}
+	loaded := make([]loadedKey, 0, len(keys))
+	for _, k := range keys {
+		signer, err := ssh.ParsePrivateKey([]byte(k.PrivateKey))
+		if err != nil {
+			logger.Log.Warn("ssh-agent: skip invalid key", zap.Int64("record", k.RecordID), zap.Error(err))
+			continue
+		}
+		loaded = append(loaded, loadedKey{SSHKey: k, signer: signer})
+	}
+	return loaded, nil
+}
+
+func sign(signer ssh.Signer, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	var algorithm string
+	switch {
+	case flags&agent.SignatureFlagRsaSha256 != 0:
+		algorithm = ssh.KeyAlgoRSASHA256
+	case flags&agent.SignatureFlagRsaSha512 != 0:
+		algorithm = ssh.KeyAlgoRSASHA512
+	default:
+		return signer.Sign(rand.Reader, data)
+	}
+	algorithmSigner, ok := signer.(ssh.AlgorithmSigner)
+	if !ok {
+		return nil, fmt.Errorf("key %s does not support %s signatures", signer.PublicKey().Type(), algorithm)
+	}
+	return algorithmSigner.SignWithAlgorithm(rand.Reader, data, algorithm)
+}
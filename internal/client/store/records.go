@@ -42,7 +42,7 @@ func (s *BoltStore) Get(id int64) (model.Record, error) {
 		}
 		data := b.Get([]byte(strconv.FormatInt(id, 10)))
 		if data == nil {
-			return fmt.Errorf("record not found")
+			return model.ErrRecordNotFound
 		}
 		return json.Unmarshal(data, &rec)
 	})
@@ -50,6 +50,17 @@ func (s *BoltStore) Get(id int64) (model.Record, error) {
 	return rec, err
 }
 
+// Delete удаляет локальную копию записи; отсутствие записи не ошибка.
+func (s *BoltStore) Delete(id int64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketRecords)
+		if b == nil {
+			return fmt.Errorf("bucket 'records' not found")
+		}
+		return b.Delete([]byte(strconv.FormatInt(id, 10)))
+	})
+}
+
 func (s *BoltStore) Put(r model.Record) error {
 	return s.db.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket(bucketRecords)
@@ -75,3 +75,8 @@ func (s *BoltStore) Clear() error {
 		return nil
 	})
 }
+
+// Close закрывает файл BoltDB и снимает с него блокировку.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
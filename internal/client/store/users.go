@@ -1,36 +1,57 @@
 package store
 
 import (
-	"encoding/base64"
-	"fmt"
-
 	bolt "go.etcd.io/bbolt"
 )
 
-func (s *BoltStore) PutUserKey(userKey string) error {
-	raw, err := base64.StdEncoding.DecodeString(userKey)
-	if err != nil {
-		return fmt.Errorf("decode key before save: %w", err)
-	}
+// PutKey сохраняет значение ключа name в бакете users. Хранилище не
+// шифрует значения: ключи пользователя сохраняет уже зашифрованными
+// service.Keyring.
+func (s *BoltStore) PutKey(name string, value []byte) error {
 	return s.db.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket(bucketUsers)
-		return b.Put([]byte("userKey"), raw)
+		return b.Put([]byte(name), value)
 	})
 }
 
-func (s *BoltStore) GetUserKey() ([]byte, error) {
+// PutKeys сохраняет значения нескольких ключей в одной транзакции: после
+// сбоя в базе остаются либо все новые значения, либо все прежние. Ключ со
+// значением nil удаляется.
+func (s *BoltStore) PutKeys(values map[string][]byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketUsers)
+		for name, value := range values {
+			var err error
+			if value == nil {
+				err = b.Delete([]byte(name))
+			} else {
+				err = b.Put([]byte(name), value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetKey возвращает значение ключа name или nil, если ключа нет.
+func (s *BoltStore) GetKey(name string) ([]byte, error) {
 	var value []byte
 	err := s.db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket(bucketUsers)
-		v := b.Get([]byte("userKey"))
-		if v == nil {
-			return fmt.Errorf("key not found")
+		if v := b.Get([]byte(name)); v != nil {
+			value = append([]byte(nil), v...)
 		}
-		value = append(value, v...)
 		return nil
 	})
-	if err != nil {
-		return nil, err
-	}
-	return value, nil
+	return value, err
+}
+
+// DeleteKey удаляет ключ name.
+func (s *BoltStore) DeleteKey(name string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketUsers)
+		return b.Delete([]byte(name))
+	})
 }
@@ -0,0 +1,88 @@
+// Package apierror сопоставляет доменные ошибки (model.Error) статусам HTTP
+// и кодам gRPC и записывает ошибки API в едином JSON-формате
+// model.ErrorResponse. Ошибки без кода считаются внутренними: клиенту
+// уходит общее сообщение, подробности — в лог сервера.
+package apierror
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mapping — статус HTTP и код gRPC для кода ошибки API.
+type mapping struct {
+	http int
+	grpc codes.Code
+}
+
+var mappings = map[model.ErrorCode]mapping{
+	model.CodeNotFound:       {http.StatusNotFound, codes.NotFound},
+	model.CodeConflict:       {http.StatusConflict, codes.AlreadyExists},
+	model.CodeValidation:     {http.StatusBadRequest, codes.InvalidArgument},
+	model.CodeForbidden:      {http.StatusForbidden, codes.PermissionDenied},
+	model.CodeUnauthorized:   {http.StatusUnauthorized, codes.Unauthenticated},
+	model.CodeNotImplemented: {http.StatusNotImplemented, codes.Unimplemented},
+	model.CodeInternal:       {http.StatusInternalServerError, codes.Internal},
+}
+
+func mappingOf(code model.ErrorCode) mapping {
+	if m, ok := mappings[code]; ok {
+		return m
+	}
+	return mappings[model.CodeInternal]
+}
+
+// Write записывает ошибку err в ответ: статус по коду ошибки и тело
+// model.ErrorResponse. Внутренние ошибки логируются вместе с запросом.
+func Write(res http.ResponseWriter, req *http.Request, err error) {
+	e := model.AsError(err)
+	if e.Code == model.CodeInternal {
+		logger.Log.Error("request failed", zap.String("method", req.Method), zap.String("path", req.URL.Path), zap.Error(err))
+	} else {
+		logger.Log.Debug("request rejected", zap.String("method", req.Method), zap.String("path", req.URL.Path), zap.Error(err))
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	res.Header().Set("X-Content-Type-Options", "nosniff")
+	res.WriteHeader(mappingOf(e.Code).http)
+	body := model.ErrorResponse{Error: model.ErrorBody{Code: e.Code, Message: e.Message}}
+	if err := json.NewEncoder(res).Encode(body); err != nil {
+		logger.Log.Error("json encoder error", zap.Error(err))
+	}
+}
+
+// Status возвращает ошибку gRPC для err. Ошибки, уже имеющие статус gRPC,
+// возвращаются без изменений.
+func Status(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	e := model.AsError(err)
+	if e.Code == model.CodeInternal {
+		logger.Log.Error("gRPC call failed", zap.Error(err))
+	}
+	return status.Error(mappingOf(e.Code).grpc, e.Message)
+}
+
+// UnaryServerInterceptor преобразует ошибки обработчиков gRPC в статусы
+// по тем же правилам, что и Write для HTTP.
+func UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	return resp, Status(err)
+}
+
+// StreamServerInterceptor — потоковый вариант UnaryServerInterceptor.
+func StreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return Status(handler(srv, ss))
+}
@@ -2,20 +2,26 @@ package app
 
 import (
 	"context"
-	"database/sql"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"net"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/fatkulllin/gophkeeper/internal/server/auth"
 	"github.com/fatkulllin/gophkeeper/internal/server/config"
 	"github.com/fatkulllin/gophkeeper/internal/server/cryptoutil"
-	"github.com/fatkulllin/gophkeeper/internal/server/db"
+	"github.com/fatkulllin/gophkeeper/internal/server/deviceca"
 	"github.com/fatkulllin/gophkeeper/internal/server/handlers"
+	"github.com/fatkulllin/gophkeeper/internal/server/metrics"
 	"github.com/fatkulllin/gophkeeper/internal/server/password"
-	"github.com/fatkulllin/gophkeeper/internal/server/repositories/postgres"
 	"github.com/fatkulllin/gophkeeper/internal/server/server"
 	"github.com/fatkulllin/gophkeeper/internal/server/service"
-	"github.com/fatkulllin/gophkeeper/migrations"
+	"github.com/fatkulllin/gophkeeper/internal/server/storage"
+	"github.com/fatkulllin/gophkeeper/internal/server/tlsutil"
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
@@ -25,32 +31,50 @@ import (
 // App объединяет зависимости серверного приложения и
 // управляет запуском HTTP и gRPC серверов.
 type App struct {
-	server *server.Server
-	pgConn *sql.DB
+	server        *server.Server
+	storage       *storage.Storage
+	traceShutdown func(context.Context) error
+	certReloader  *tlsutil.CertReloader
+	records       *service.RecordService
+	audit         *service.AuditService
+	sweepInterval time.Duration
+	auditInterval time.Duration
+	// auditFullInterval — период полной проверки цепочки журнала аудита.
+	auditFullInterval time.Duration
 }
 
 // NewApp создаёт и настраивает серверное приложение GophKeeper.
-// Здесь выполняется подключение к базе данных, применение миграций,
+// Здесь выполняется подключение к выбранному хранилищу, применение миграций,
 // инициализация сервисов, хендлеров и серверов.
 //
 // Возвращает экземпляр App или ошибку инициализации.
 func NewApp(cfg config.Config) (App, error) {
-	pgConn, err := db.NewPostgres(cfg.DatabaseURI)
+	traceShutdown, err := tracing.Init(context.Background(), cfg.TraceExporter, cfg.TraceEndpoint)
+	if err != nil {
+		return App{}, fmt.Errorf("failed to initialize tracing %w", err)
+	}
 
+	store, err := storage.Open(cfg.Storage, cfg.StorageDSN())
 	if err != nil {
-		return App{}, fmt.Errorf("failed to connect to database %w", err)
+		return App{}, err
 	}
 
-	logger.Log.Debug("successfully connected to database")
+	logger.Log.Debug("storage opened successfully", zap.String("storage", cfg.Storage))
 
-	err = db.Bootstrap(pgConn, migrations.FS)
-	if err != nil {
-		return App{}, fmt.Errorf("failed to apply migrations %w", err)
+	var deviceCA service.DeviceCA
+	var clientCAs *x509.CertPool
+	if cfg.DeviceAuthEnabled() {
+		ca, err := deviceca.Load(cfg.DeviceCACert, cfg.DeviceCAKey)
+		if err != nil {
+			return App{}, fmt.Errorf("failed to load device CA %w", err)
+		}
+		deviceCA = ca
+		clientCAs = ca.Pool()
 	}
-	logger.Log.Debug("database migrated successfully")
 
-	recordRepo := postgres.NewRecordRepo(pgConn)
-	userRepo := postgres.NewUserRepo(pgConn)
+	if err := metrics.Register(store.DB, store.Records); err != nil {
+		return App{}, fmt.Errorf("failed to register metrics %w", err)
+	}
 
 	v := validator.New()
 	tokenManager := auth.NewJWTManager(cfg.JWTSecret, cfg.JWTExpires)
@@ -60,25 +84,76 @@ func NewApp(cfg config.Config) (App, error) {
 	pwdHasher := password.NewPassword()
 	cryptoUtil := cryptoutil.NewCryptoUtil(cfg.MasterKey)
 
-	service := service.NewService(userRepo, recordRepo, tokenManager, pwdHasher, cryptoUtil)
+	service := service.NewService(store.Users, store.Records, store.Orgs, store.Audit, store.Devices, store.VaultTokens, tokenManager, pwdHasher, cryptoUtil, deviceCA)
 	healthHandler := handlers.NewHealthHandler()
 	loggerHandler := handlers.NewLoggerHandler(v)
 	authHandler := handlers.NewAuthHandler(service.User, v)
 	recordHandler := handlers.NewRecordHandler(service.Record, v)
-	srv := server.NewServer(cfg, healthHandler, loggerHandler, authHandler, recordHandler)
+	orgHandler := handlers.NewOrgHandler(service.Org, v)
+	auditHandler := handlers.NewAuditHandler(service.Audit)
+	deviceHandler := handlers.NewDeviceHandler(service.Device, v)
+	var vaultHandler *handlers.VaultHandler
+	var vaultTokenHandler *handlers.VaultTokenHandler
+	if cfg.VaultEnabled() {
+		vaultHandler = handlers.NewVaultHandler(service.Record, cfg.VaultMount)
+		vaultTokenHandler = handlers.NewVaultTokenHandler(service.VaultToken, v)
+	}
+	var tlsConfig *tls.Config
+	var certReloader *tlsutil.CertReloader
+	if cfg.TLSEnabled() {
+		if cfg.TLSSelfSigned {
+			if err := tlsutil.EnsureSelfSigned(cfg.TLSCert, cfg.TLSKey, devHosts(cfg.HTTPAddress)); err != nil {
+				return App{}, fmt.Errorf("failed to generate self-signed certificate %w", err)
+			}
+		}
+		certReloader, err = tlsutil.NewCertReloader(cfg.TLSCert, cfg.TLSKey)
+		if err != nil {
+			return App{}, fmt.Errorf("failed to load TLS certificate %w", err)
+		}
+		tlsConfig = certReloader.TLSConfig()
+		if clientCAs != nil {
+			// сертификат устройства необязателен: без него можно войти и зарегистрировать устройство
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+			tlsConfig.ClientCAs = clientCAs
+		}
+	}
+
+	srv := server.NewServer(cfg, tlsConfig, service.Org, service.Device, service.VaultToken, healthHandler, loggerHandler, authHandler, recordHandler, orgHandler, auditHandler, deviceHandler, vaultHandler, vaultTokenHandler)
 
 	return App{
-		server: srv,
-		pgConn: pgConn,
+		server:            srv,
+		storage:           store,
+		traceShutdown:     traceShutdown,
+		certReloader:      certReloader,
+		records:           service.Record,
+		audit:             service.Audit,
+		sweepInterval:     cfg.ExpirySweep,
+		auditInterval:     cfg.AuditVerify,
+		auditFullInterval: cfg.AuditFullVerify,
 	}, nil
 }
 
+// devHosts возвращает имена, на которые выписывается самоподписанный сертификат:
+// хост из адреса HTTP-сервера и localhost.
+func devHosts(address string) []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	host, _, err := net.SplitHostPort(address)
+	if err == nil && host != "" && !slices.Contains(hosts, host) {
+		hosts = append([]string{host}, hosts...)
+	}
+	return hosts
+}
+
 // Run запускает HTTP и gRPC сервера и ожидает их завершения.
 // Остановка выполняется при получении сигнала завершения
 // или при возникновении ошибки в одном из серверов.
 func (app *App) Run(ctx context.Context) error {
 	group, ctx := errgroup.WithContext(ctx)
 
+	if app.certReloader != nil {
+		go app.certReloader.WatchSIGHUP(ctx)
+	}
+
 	// HTTP server
 	group.Go(func() error {
 		if err := app.server.Start(ctx); err != nil && err != http.ErrServerClosed {
@@ -88,6 +163,15 @@ func (app *App) Run(ctx context.Context) error {
 		return nil
 	})
 
+	// Prometheus metrics
+	group.Go(func() error {
+		if err := app.server.StartMetrics(ctx); err != nil {
+			logger.Log.Error("metrics server exited with error", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+
 	// gRPC server
 	group.Go(func() error {
 		if err := app.server.StartGRPC(ctx); err != nil {
@@ -97,18 +181,105 @@ func (app *App) Run(ctx context.Context) error {
 		return nil
 	})
 
+	if app.sweepInterval > 0 {
+		group.Go(func() error {
+			app.sweepExpired(ctx)
+			return nil
+		})
+	}
+
+	if app.auditInterval > 0 || app.auditFullInterval > 0 {
+		group.Go(func() error {
+			app.verifyAudit(ctx)
+			return nil
+		})
+	}
+
 	if err := group.Wait(); err != nil {
 		logger.Log.Warn("shutting down due to error", zap.Error(err))
 		return err
 	}
 
-	if err := app.pgConn.Close(); err != nil {
+	if err := app.storage.Close(); err != nil {
 		logger.Log.Warn("failed to close database connection", zap.Error(err))
 	}
 
 	logger.Log.Info("shutting down...")
 
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := app.traceShutdown(shutdownCtx); err != nil {
+		logger.Log.Warn("failed to flush traces", zap.Error(err))
+	}
+
 	logger.Log.Info("shutdown complete")
 
 	return nil
 }
+
+// sweepExpired периодически удаляет эфемерные записи с истёкшим сроком
+// действия до отмены контекста. Ошибка одного прохода не останавливает сервер.
+func (app *App) sweepExpired(ctx context.Context) {
+	ticker := time.NewTicker(app.sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			deleted, err := app.records.PurgeExpired(ctx, now)
+			if err != nil {
+				logger.Log.Error("failed to delete expired records", zap.Error(err))
+				continue
+			}
+			if deleted > 0 {
+				logger.Log.Info("expired ephemeral records deleted", zap.Int("count", deleted))
+			}
+		}
+	}
+}
+
+// verifyAudit проверяет цепочку журнала аудита при запуске и затем
+// периодически до отмены контекста: с периодом auditInterval — только новые
+// события, с периодом auditFullInterval — всю цепочку от первого события.
+// Результат пишется в лог и в метрики; нарушение цепочки логируется как
+// ошибка, но не останавливает сервер.
+func (app *App) verifyAudit(ctx context.Context) {
+	var incremental, full <-chan time.Time
+	if app.auditInterval > 0 {
+		ticker := time.NewTicker(app.auditInterval)
+		defer ticker.Stop()
+		incremental = ticker.C
+	}
+	if app.auditFullInterval > 0 {
+		ticker := time.NewTicker(app.auditFullInterval)
+		defer ticker.Stop()
+		full = ticker.C
+	}
+
+	verify := app.audit.Verify
+	for {
+		result, err := verify(ctx)
+		switch {
+		case err != nil:
+			logger.Log.Error("failed to verify audit chain", zap.Error(err))
+		case !result.Valid:
+			logger.Log.Error("audit chain is broken", zap.Int64("event id", result.BrokenAt), zap.Int("verified events", result.Events))
+		default:
+			logger.Log.Debug("audit chain verified", zap.Int("events", result.Events))
+		}
+		if err == nil {
+			metrics.ObserveAuditVerification(result.Valid, result.Events)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-incremental:
+			verify = app.audit.Verify
+		case <-full:
+			verify = app.audit.VerifyFull
+		}
+	}
+}
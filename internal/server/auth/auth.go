@@ -24,8 +24,10 @@ func NewJWTManager(jwtSecret string, tokenExpires int) *JWTManager {
 }
 
 // Generate создаёт JWT-токен для заданного пользователя.
+// Непустой certThumbprint записывается в claim cnf (x5t#S256) и привязывает
+// токен к сертификату устройства.
 // Возвращает строку токена, срок жизни и ошибку при подписи.
-func (m *JWTManager) Generate(userID int, userLogin string) (string, int, error) {
+func (m *JWTManager) Generate(userID int, userLogin string, certThumbprint string) (string, int, error) {
 	now := time.Now()
 	claims := model.Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
@@ -36,6 +38,9 @@ func (m *JWTManager) Generate(userID int, userLogin string) (string, int, error)
 		UserID:    userID,
 		UserLogin: userLogin,
 	}
+	if certThumbprint != "" {
+		claims.Cnf = &model.Confirmation{X5tS256: certThumbprint}
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
@@ -1,3 +1,4 @@
 // Пакет auth содержит инструменты для генерации и проверки JWT-токенов,
-// используемых в серверной части GophKeeper.
+// используемых в серверной части GophKeeper, в том числе токенов,
+// привязанных к сертификату устройства (mTLS), для HTTP и gRPC.
 package auth
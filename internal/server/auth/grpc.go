@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/apierror"
+	"github.com/fatkulllin/gophkeeper/internal/server/ctxkeys"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// publicMethodPrefix — методы gRPC, доступные без токена (health-check).
+const publicMethodPrefix = "/grpc.health.v1.Health/"
+
+// UnaryServerInterceptor проверяет токен из метаданных "authorization: Bearer <token>"
+// и его привязку к клиентскому сертификату так же, как AuthMiddleware,
+// и помещает claims в контекст вызова.
+func UnaryServerInterceptor(secret string, devices DeviceChecker) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if strings.HasPrefix(info.FullMethod, publicMethodPrefix) {
+			return handler(ctx, req)
+		}
+		ctx, err := authenticateGRPC(ctx, secret, devices)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor — потоковый вариант UnaryServerInterceptor.
+func StreamServerInterceptor(secret string, devices DeviceChecker) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasPrefix(info.FullMethod, publicMethodPrefix) {
+			return handler(srv, ss)
+		}
+		ctx, err := authenticateGRPC(ss.Context(), secret, devices)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authStream подменяет контекст потока контекстом с claims.
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+func authenticateGRPC(ctx context.Context, secret string, devices DeviceChecker) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || !strings.HasPrefix(values[0], "Bearer ") {
+		return nil, apierror.Status(errMissingToken)
+	}
+
+	claims, err := parseToken(secret, strings.TrimPrefix(values[0], "Bearer "))
+	if err != nil {
+		return nil, apierror.Status(errInvalidToken)
+	}
+
+	var peerCerts []*x509.Certificate
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			peerCerts = tlsInfo.State.PeerCertificates
+		}
+	}
+	if err := verifyBinding(ctx, claims, peerCerts, devices); err != nil {
+		return nil, apierror.Status(unauthorized(err))
+	}
+
+	return context.WithValue(ctx, ctxkeys.UserContextKey, claims), nil
+}
@@ -2,50 +2,52 @@ package auth
 
 import (
 	"context"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"net/http"
 
+	"github.com/fatkulllin/gophkeeper/internal/server/apierror"
 	"github.com/fatkulllin/gophkeeper/internal/server/ctxkeys"
 	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/cryptoutil"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 )
 
+var errTokenNotValid = errors.New("token is not valid")
+var errMissingToken = model.NewError(model.CodeUnauthorized, "missing auth token")
+var errInvalidToken = model.NewError(model.CodeUnauthorized, "invalid token")
+var errCertMismatch = model.NewError(model.CodeUnauthorized, "token is bound to another device certificate")
+
+// DeviceChecker проверяет, что сертификат устройства выпущен сервером и не
+// отозван, и сообщает, обязателен ли пользователю вход с устройства.
+type DeviceChecker interface {
+	CheckDevice(ctx context.Context, thumbprint string) error
+	RequireDevice(ctx context.Context, userID int) (bool, error)
+}
+
 // AuthMiddleware проверяет JWT-токен из cookie "auth_token".
+// Токен, привязанный к сертификату устройства (claim cnf), принимается только
+// в TLS-соединении с этим сертификатом, если сертификат не отозван; токен без
+// привязки не принимается, если пользователю обязателен вход с устройства.
 // При успешной аутентификации помещает данные пользователя (claims) в контекст
 // и передает управление следующему обработчику. В случае ошибки возвращает
-// статус 401 Unauthorized.
-func AuthMiddleware(secret string) func(http.Handler) http.Handler {
+// статус 401 Unauthorized (500 при сбое хранилища устройств).
+func AuthMiddleware(secret string, devices DeviceChecker) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
 			cookie, err := req.Cookie("auth_token")
 
 			if err != nil {
-				http.Error(res, "unauthorized: missing auth token", http.StatusUnauthorized)
+				apierror.Write(res, req, errMissingToken)
 				return
 			}
 
-			tokenString := cookie.Value
-
-			claims := model.Claims{}
-
-			token, err := jwt.ParseWithClaims(tokenString, &claims,
-				func(t *jwt.Token) (any, error) {
-					if t.Method != jwt.SigningMethodHS256 {
-						return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
-					}
-					return []byte(secret), nil
-				})
-
+			claims, err := authenticateHTTP(req, secret, cookie.Value, devices)
 			if err != nil {
-				logger.Log.Error("JWT validation failed", zap.Error(err))
-				http.Error(res, "unauthorized: invalid token", http.StatusUnauthorized)
-				return
-			}
-
-			if !token.Valid {
-				http.Error(res, "unauthorized: token is not valid", http.StatusUnauthorized)
+				apierror.Write(res, req, err)
 				return
 			}
 
@@ -58,3 +60,76 @@ func AuthMiddleware(secret string) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// authenticateHTTP проверяет токен HTTP-запроса и его привязку к клиентскому
+// сертификату и возвращает claims.
+func authenticateHTTP(req *http.Request, secret string, token string, devices DeviceChecker) (model.Claims, error) {
+	claims, err := parseToken(secret, token)
+	if err != nil {
+		logger.Log.Error("JWT validation failed", zap.Error(err))
+		return model.Claims{}, errInvalidToken
+	}
+
+	var peerCerts []*x509.Certificate
+	if req.TLS != nil {
+		peerCerts = req.TLS.PeerCertificates
+	}
+	if err := verifyBinding(req.Context(), claims, peerCerts, devices); err != nil {
+		logger.Log.Warn("device binding check failed", zap.String("login", claims.UserLogin), zap.Error(err))
+		return model.Claims{}, unauthorized(err)
+	}
+	return claims, nil
+}
+
+// unauthorized приводит ошибку проверки устройства (устройство не найдено,
+// сертификат отозван) к коду unauthorized. Сбои хранилища остаются
+// внутренними ошибками.
+func unauthorized(err error) error {
+	e := model.AsError(err)
+	if e.Code == model.CodeInternal || e.Code == model.CodeUnauthorized {
+		return err
+	}
+	return fmt.Errorf("%w: %w", model.NewError(model.CodeUnauthorized, e.Message), err)
+}
+
+// parseToken проверяет подпись и срок действия токена и возвращает его claims.
+func parseToken(secret string, tokenString string) (model.Claims, error) {
+	claims := model.Claims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, &claims,
+		func(t *jwt.Token) (any, error) {
+			if t.Method != jwt.SigningMethodHS256 {
+				return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+			}
+			return []byte(secret), nil
+		})
+	if err != nil {
+		return model.Claims{}, err
+	}
+	if !token.Valid {
+		return model.Claims{}, errTokenNotValid
+	}
+	return claims, nil
+}
+
+// verifyBinding проверяет, что токен, привязанный к сертификату устройства,
+// предъявлен вместе с этим сертификатом и сертификат не отозван.
+// Непривязанный токен проходит, только если пользователю не обязателен вход
+// с устройства: так токены, выданные до включения требования, перестают
+// действовать сразу, а не по истечении срока.
+func verifyBinding(ctx context.Context, claims model.Claims, peerCerts []*x509.Certificate, devices DeviceChecker) error {
+	if claims.Cnf == nil {
+		require, err := devices.RequireDevice(ctx, claims.UserID)
+		if err != nil {
+			return err
+		}
+		if require {
+			return model.ErrDeviceRequired
+		}
+		return nil
+	}
+	if len(peerCerts) == 0 || cryptoutil.CertThumbprint(peerCerts[0]) != claims.Cnf.X5tS256 {
+		return errCertMismatch
+	}
+	return devices.CheckDevice(ctx, claims.Cnf.X5tS256)
+}
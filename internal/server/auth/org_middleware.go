@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/apierror"
+	"github.com/fatkulllin/gophkeeper/internal/server/ctxkeys"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// OrgRoleResolver возвращает роль пользователя в организации.
+type OrgRoleResolver interface {
+	MemberRole(ctx context.Context, orgID int, userID int) (model.OrgRole, error)
+}
+
+// OrgMiddleware определяет организацию запроса по параметру пути {orgID}
+// или query-параметру org, проверяет членство пользователя и дополняет
+// claims из контекста идентификатором организации и ролью пользователя.
+// Запросы без организации передаются дальше без изменений.
+// Должен подключаться после AuthMiddleware.
+func OrgMiddleware(resolver OrgRoleResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			rawOrgID := chi.URLParam(req, "orgID")
+			if rawOrgID == "" {
+				rawOrgID = req.URL.Query().Get("org")
+			}
+
+			if rawOrgID == "" {
+				next.ServeHTTP(res, req)
+				return
+			}
+
+			orgID, err := strconv.Atoi(rawOrgID)
+			if err != nil || orgID <= 0 {
+				apierror.Write(res, req, model.NewError(model.CodeValidation, "invalid organization id"))
+				return
+			}
+
+			claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+			if !ok {
+				apierror.Write(res, req, model.NewError(model.CodeUnauthorized, "claims not found"))
+				return
+			}
+
+			role, err := resolver.MemberRole(req.Context(), orgID, claims.UserID)
+			if err != nil {
+				apierror.Write(res, req, fmt.Errorf("resolve role in organization %d: %w", orgID, err))
+				return
+			}
+
+			claims.OrgID = orgID
+			claims.OrgRole = role
+
+			logger.Log.Debug("organization scope", zap.Int("org id", orgID), zap.String("role", string(role)))
+
+			ctx := context.WithValue(req.Context(), ctxkeys.UserContextKey, claims)
+
+			next.ServeHTTP(res, req.WithContext(ctx))
+		})
+	}
+}
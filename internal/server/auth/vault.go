@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/ctxkeys"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// VaultTokenHeader — заголовок с токеном клиентов Vault.
+const VaultTokenHeader = "X-Vault-Token"
+
+// VaultTokenAuthenticator проверяет токены клиентов Vault.
+type VaultTokenAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (model.Claims, error)
+}
+
+// VaultTokenMiddleware проверяет токен клиента Vault из заголовка
+// X-Vault-Token или "Authorization: Bearer <token>". Принимаются только
+// токены, выпущенные для API Vault (POST /api/vault/tokens); JWT сессии
+// здесь не действует. Как и Vault, на отсутствующий, неверный или отозванный
+// токен отвечает 403 с телом {"errors":["permission denied"]}.
+func VaultTokenMiddleware(tokens VaultTokenAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			token := VaultToken(req)
+			if token == "" {
+				writeVaultDenied(res)
+				return
+			}
+
+			claims, err := tokens.Authenticate(req.Context(), token)
+			if err != nil {
+				if !errors.Is(err, model.ErrVaultTokenNotFound) {
+					logger.Log.Error("vault token check failed", zap.Error(err))
+				}
+				writeVaultDenied(res)
+				return
+			}
+
+			logger.Log.Debug("vault token validated", zap.String("login", claims.UserLogin))
+
+			ctx := context.WithValue(req.Context(), ctxkeys.UserContextKey, claims)
+			next.ServeHTTP(res, req.WithContext(ctx))
+		})
+	}
+}
+
+// VaultToken возвращает токен клиента Vault из запроса.
+func VaultToken(req *http.Request) string {
+	if token := req.Header.Get(VaultTokenHeader); token != "" {
+		return token
+	}
+	if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	return ""
+}
+
+func writeVaultDenied(res http.ResponseWriter) {
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(res).Encode(map[string][]string{"errors": {"permission denied"}})
+}
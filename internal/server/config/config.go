@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"net"
+	"strings"
+	"time"
 
 	"github.com/caarlos0/env"
 	"github.com/spf13/pflag"
@@ -17,17 +19,61 @@ type Config struct {
 	JWTSecret   string `env:"JWT_SECRET_KEY"`
 	JWTExpires  int    `env:"JWT_EXPIRES"`
 	MasterKey   string `env:"MASTER_KEY"`
+	// MetricsAddress — адрес отдельного HTTP-сервера метрик Prometheus,
+	// не доступного через публичный API; пустое значение отключает метрики.
+	MetricsAddress string `env:"METRICS_ADDRESS"`
+	// Storage — хранилище данных: postgres, sqlite или memory.
+	Storage string `env:"STORAGE"`
+	// SQLitePath — файл базы SQLite для хранилища sqlite.
+	SQLitePath string `env:"SQLITE_PATH"`
+	// ExpirySweep — период фоновой задачи, удаляющей эфемерные
+	// записи с истёкшим сроком действия; 0 отключает задачу.
+	ExpirySweep time.Duration `env:"EXPIRY_SWEEP_INTERVAL"`
+	// AuditVerify — период фоновой проверки целостности цепочки журнала
+	// аудита; 0 отключает проверку.
+	AuditVerify time.Duration `env:"AUDIT_VERIFY_INTERVAL"`
+	// AuditFullVerify — период полной проверки цепочки от первого события,
+	// которая обнаруживает изменение уже проверенных событий; 0 отключает её.
+	AuditFullVerify time.Duration `env:"AUDIT_FULL_VERIFY_INTERVAL"`
+	// TraceExporter — экспортёр спанов OpenTelemetry: none, stdout или otlp.
+	TraceExporter string `env:"TRACE_EXPORTER"`
+	// TraceEndpoint — адрес OTLP/HTTP-коллектора (host:port).
+	TraceEndpoint string `env:"TRACE_ENDPOINT"`
+	// TLSCert и TLSKey — пути к PEM-файлам сертификата и ключа сервера.
+	// Если заданы, HTTP и gRPC принимают только TLS-соединения.
+	TLSCert string `env:"TLS_CERT"`
+	TLSKey  string `env:"TLS_KEY"`
+	// TLSSelfSigned включает генерацию самоподписанного сертификата для разработки.
+	TLSSelfSigned bool `env:"TLS_SELF_SIGNED"`
+	// DeviceCACert и DeviceCAKey — сертификат и ключ внутреннего CA устройств.
+	// Если заданы, включается выпуск сертификатов устройств и взаимная
+	// TLS-аутентификация; при отсутствии файлов CA создаётся автоматически.
+	DeviceCACert string `env:"DEVICE_CA_CERT"`
+	DeviceCAKey  string `env:"DEVICE_CA_KEY"`
+	// VaultMount — точка монтирования API, совместимого с Vault KV v2
+	// (например, secret); пустое значение отключает API.
+	VaultMount string `env:"VAULT_MOUNT"`
 }
 
 const (
-	DefaultHTTPAddress = "localhost:8080"
-	DeafultGRPCAddress = "localhost:9090"
-	DeafultDevelopLog  = false
-	DefaultLogLevel    = "INFO"
-	DefaultDatabaseURI = "host=localhost user=postgres password=postgres dbname=postgres port=5432 sslmode=disable"
-	DefaultJWTSecret   = "TOKEN"
-	DefaultJWTExpires  = 24
-	DefaultMasterKey   = "DV4MIaUe9zYYO8ENbmdxBbTLo2fK+miK+GqXs4jKqnM="
+	DefaultHTTPAddress     = "localhost:8080"
+	DeafultGRPCAddress     = "localhost:9090"
+	DefaultMetricsAddress  = "localhost:9464"
+	DeafultDevelopLog      = false
+	DefaultLogLevel        = "INFO"
+	DefaultStorage         = "postgres"
+	DefaultSQLitePath      = "gophkeeper.db"
+	DefaultExpirySweep     = time.Minute
+	DefaultAuditVerify     = time.Hour
+	DefaultAuditFullVerify = 24 * time.Hour
+	DefaultDatabaseURI     = "host=localhost user=postgres password=postgres dbname=postgres port=5432 sslmode=disable"
+	DefaultJWTSecret       = "TOKEN"
+	DefaultJWTExpires      = 24
+	DefaultMasterKey       = "DV4MIaUe9zYYO8ENbmdxBbTLo2fK+miK+GqXs4jKqnM="
+	DefaultTraceExporter   = "none"
+	DefaultTraceEndpoint   = "localhost:4318"
+	DefaultSelfSignedCert  = "gophkeeper-dev.crt"
+	DefaultSelfSignedKey   = "gophkeeper-dev.key"
 )
 
 func validateAddress(s string) error {
@@ -41,25 +87,47 @@ func validateAddress(s string) error {
 func LoadConfig() (Config, error) {
 
 	config := Config{
-		HTTPAddress: DefaultHTTPAddress,
-		GRPCAddress: DeafultGRPCAddress,
-		DevelopLog:  DeafultDevelopLog,
-		LogLevel:    DefaultLogLevel,
-		DatabaseURI: DefaultDatabaseURI,
-		JWTSecret:   DefaultJWTSecret,
-		JWTExpires:  DefaultJWTExpires,
-		MasterKey:   DefaultMasterKey,
+		HTTPAddress:     DefaultHTTPAddress,
+		GRPCAddress:     DeafultGRPCAddress,
+		MetricsAddress:  DefaultMetricsAddress,
+		DevelopLog:      DeafultDevelopLog,
+		LogLevel:        DefaultLogLevel,
+		Storage:         DefaultStorage,
+		DatabaseURI:     DefaultDatabaseURI,
+		SQLitePath:      DefaultSQLitePath,
+		ExpirySweep:     DefaultExpirySweep,
+		AuditVerify:     DefaultAuditVerify,
+		AuditFullVerify: DefaultAuditFullVerify,
+		JWTSecret:       DefaultJWTSecret,
+		JWTExpires:      DefaultJWTExpires,
+		MasterKey:       DefaultMasterKey,
+		TraceExporter:   DefaultTraceExporter,
+		TraceEndpoint:   DefaultTraceEndpoint,
 	}
 
 	pflag.CommandLine.SortFlags = false // чтобы флаги выводились в заданном порядке
 	pflag.StringVar(&config.HTTPAddress, "http-address", config.HTTPAddress, "HTTP server listen address (host:port)")
 	pflag.StringVar(&config.GRPCAddress, "grpc-address", config.GRPCAddress, "GRPC server listen address (host:port)")
+	pflag.StringVar(&config.MetricsAddress, "metrics-address", config.MetricsAddress, "Prometheus metrics listen address (host:port, empty to disable)")
 	pflag.StringVarP(&config.LogLevel, "log-level", "l", config.LogLevel, "logging level: debug, info, warn, error")
 	pflag.BoolVar(&config.DevelopLog, "develop-log", config.DevelopLog, "enabled develop log")
+	pflag.StringVar(&config.Storage, "storage", config.Storage, "storage backend: postgres, sqlite, memory")
 	pflag.StringVarP(&config.DatabaseURI, "database", "d", config.DatabaseURI, "set database dsn")
+	pflag.StringVar(&config.SQLitePath, "sqlite-path", config.SQLitePath, "SQLite database file for sqlite storage")
+	pflag.DurationVar(&config.ExpirySweep, "expiry-sweep-interval", config.ExpirySweep, "interval of deleting expired ephemeral records (0 to disable)")
+	pflag.DurationVar(&config.AuditVerify, "audit-verify-interval", config.AuditVerify, "interval of verifying the audit log hash chain (0 to disable)")
+	pflag.DurationVar(&config.AuditFullVerify, "audit-full-verify-interval", config.AuditFullVerify, "interval of re-verifying the whole audit log hash chain from the first event (0 to disable)")
 	pflag.StringVarP(&config.JWTSecret, "secret", "s", config.JWTSecret, "set secret token")
 	pflag.IntVarP(&config.JWTExpires, "expires", "e", config.JWTExpires, "set expires jwt")
 	pflag.StringVarP(&config.MasterKey, "master-key", "m", config.MasterKey, "set master key")
+	pflag.StringVar(&config.TraceExporter, "trace-exporter", config.TraceExporter, "trace exporter: none, stdout, otlp")
+	pflag.StringVar(&config.TraceEndpoint, "trace-endpoint", config.TraceEndpoint, "OTLP/HTTP collector address (host:port)")
+	pflag.StringVar(&config.TLSCert, "tls-cert", config.TLSCert, "TLS certificate file (PEM)")
+	pflag.StringVar(&config.TLSKey, "tls-key", config.TLSKey, "TLS private key file (PEM)")
+	pflag.BoolVar(&config.TLSSelfSigned, "tls-self-signed", config.TLSSelfSigned, "generate self-signed TLS certificate for development")
+	pflag.StringVar(&config.DeviceCACert, "device-ca-cert", config.DeviceCACert, "device CA certificate file (PEM), enables device certificates")
+	pflag.StringVar(&config.DeviceCAKey, "device-ca-key", config.DeviceCAKey, "device CA private key file (PEM)")
+	pflag.StringVar(&config.VaultMount, "vault-mount", config.VaultMount, "mount path of the Vault KV v2 compatible read API, e.g. secret (empty to disable)")
 	pflag.Parse()
 
 	err := env.Parse(&config)
@@ -72,5 +140,88 @@ func LoadConfig() (Config, error) {
 		return config, fmt.Errorf("invalid server address: %s, %w", config.HTTPAddress, err)
 	}
 
+	if config.MetricsEnabled() {
+		if err := validateAddress(config.MetricsAddress); err != nil {
+			return config, fmt.Errorf("invalid metrics address: %s, %w", config.MetricsAddress, err)
+		}
+	}
+
+	if config.TLSSelfSigned {
+		if config.TLSCert == "" {
+			config.TLSCert = DefaultSelfSignedCert
+		}
+		if config.TLSKey == "" {
+			config.TLSKey = DefaultSelfSignedKey
+		}
+	}
+	if (config.TLSCert == "") != (config.TLSKey == "") {
+		return config, fmt.Errorf("both TLS certificate and key must be set")
+	}
+
+	if (config.DeviceCACert == "") != (config.DeviceCAKey == "") {
+		return config, fmt.Errorf("both device CA certificate and key must be set")
+	}
+	if config.DeviceAuthEnabled() && !config.TLSEnabled() {
+		return config, fmt.Errorf("device certificates require TLS")
+	}
+
+	switch config.Storage {
+	case "postgres", "sqlite", "memory":
+	default:
+		return config, fmt.Errorf("invalid storage: %s", config.Storage)
+	}
+
+	if config.ExpirySweep < 0 {
+		return config, fmt.Errorf("invalid expiry sweep interval: %s", config.ExpirySweep)
+	}
+
+	if config.AuditVerify < 0 {
+		return config, fmt.Errorf("invalid audit verify interval: %s", config.AuditVerify)
+	}
+
+	if config.AuditFullVerify < 0 {
+		return config, fmt.Errorf("invalid audit full verify interval: %s", config.AuditFullVerify)
+	}
+
+	switch config.TraceExporter {
+	case "none", "stdout", "otlp":
+	default:
+		return config, fmt.Errorf("invalid trace exporter: %s", config.TraceExporter)
+	}
+
+	config.VaultMount = strings.Trim(config.VaultMount, "/")
+	if strings.ContainsAny(config.VaultMount, "/{}*") || config.VaultMount == "sys" || config.VaultMount == "auth" {
+		return config, fmt.Errorf("invalid vault mount: %s", config.VaultMount)
+	}
+
 	return config, nil
 }
+
+// MetricsEnabled сообщает, запущен ли сервер метрик Prometheus.
+func (c Config) MetricsEnabled() bool {
+	return c.MetricsAddress != ""
+}
+
+// VaultEnabled сообщает, включён ли API, совместимый с Vault KV v2.
+func (c Config) VaultEnabled() bool {
+	return c.VaultMount != ""
+}
+
+// StorageDSN возвращает строку подключения выбранного хранилища:
+// DSN Postgres или путь к файлу SQLite.
+func (c Config) StorageDSN() string {
+	if c.Storage == "sqlite" {
+		return c.SQLitePath
+	}
+	return c.DatabaseURI
+}
+
+// DeviceAuthEnabled сообщает, включены ли сертификаты устройств.
+func (c Config) DeviceAuthEnabled() bool {
+	return c.DeviceCACert != ""
+}
+
+// TLSEnabled сообщает, должны ли серверы принимать только TLS-соединения.
+func (c Config) TLSEnabled() bool {
+	return c.TLSCert != ""
+}
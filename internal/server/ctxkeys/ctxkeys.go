@@ -11,3 +11,7 @@ type contextKey string
 // UserContextKey — ключ, под которым в контексте хранится информация
 // о текущем авторизованном пользователе (структура model.Claims).
 const UserContextKey = contextKey("user")
+
+// RequestInfoKey — ключ, под которым в контексте хранятся сведения о клиенте
+// (структура model.RequestInfo): IP-адрес и User-Agent для журнала аудита.
+const RequestInfoKey = contextKey("request_info")
@@ -1,3 +1,3 @@
-// Package db содержит функции для подключения к базе данных PostgreSQL
-// и применения миграций при старте приложения.
+// Package db содержит функции для подключения к базам данных PostgreSQL
+// и SQLite и применения миграций при старте приложения.
 package db
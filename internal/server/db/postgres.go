@@ -3,8 +3,8 @@ package db
 import (
 	"context"
 	"database/sql"
-	"embed"
 	"fmt"
+	"io/fs"
 	"time"
 
 	"github.com/pressly/goose/v3"
@@ -27,11 +27,12 @@ func NewPostgres(dsn string) (*sql.DB, error) {
 }
 
 // Bootstrap применяет миграции goose, используя встроенную файловую систему.
+// dialect — диалект goose: "postgres" или "sqlite3".
 // Используется при старте приложения.
-func Bootstrap(db *sql.DB, fs embed.FS) error {
-	goose.SetBaseFS(fs)
-	if err := goose.SetDialect("postgres"); err != nil {
-		return fmt.Errorf("failed to set postgres dialect: %w", err)
+func Bootstrap(db *sql.DB, fsys fs.FS, dialect string) error {
+	goose.SetBaseFS(fsys)
+	if err := goose.SetDialect(dialect); err != nil {
+		return fmt.Errorf("failed to set %s dialect: %w", dialect, err)
 	}
 
 	if err := goose.Up(db, "."); err != nil {
@@ -0,0 +1,144 @@
+// Package deviceca реализует внутренний удостоверяющий центр, выпускающий
+// клиентские сертификаты устройств для взаимной TLS-аутентификации.
+package deviceca
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// caValidity — срок действия сертификата CA.
+	caValidity = 10 * 365 * 24 * time.Hour
+	// DeviceValidity — срок действия сертификата устройства.
+	DeviceValidity = 365 * 24 * time.Hour
+)
+
+// CA подписывает сертификаты устройств.
+type CA struct {
+	cert    *x509.Certificate
+	certPEM []byte
+	key     any
+}
+
+// Load загружает сертификат и ключ CA из PEM-файлов.
+// Если файла сертификата нет, CA создаётся и сохраняется по этим путям.
+func Load(certFile, keyFile string) (*CA, error) {
+	if _, err := os.Stat(certFile); errors.Is(err, os.ErrNotExist) {
+		if err := generate(certFile, keyFile); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load device CA: %w", err)
+	}
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("read device CA: %w", err)
+	}
+
+	return &CA{cert: pair.Leaf, certPEM: certPEM, key: pair.PrivateKey}, nil
+}
+
+// Pool возвращает пул с сертификатом CA для проверки клиентских сертификатов.
+func (ca *CA) Pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// CertPEM возвращает сертификат CA в формате PEM.
+func (ca *CA) CertPEM() []byte {
+	return ca.certPEM
+}
+
+// Sign выпускает сертификат устройства для открытого ключа из CSR.
+// Из CSR берётся только ключ: субъект задаёт сервер по логину пользователя.
+func (ca *CA) Sign(csr *x509.CertificateRequest, login string) (*x509.Certificate, error) {
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{"GophKeeper device"}, CommonName: login},
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(DeviceValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, csr.PublicKey, ca.key)
+	if err != nil {
+		return nil, fmt.Errorf("sign device certificate: %w", err)
+	}
+	return x509.ParseCertificate(der)
+}
+
+func generate(certFile, keyFile string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("generate CA key: %w", err)
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"GophKeeper"}, CommonName: "GophKeeper device CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return fmt.Errorf("create CA certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("marshal CA key: %w", err)
+	}
+
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return fmt.Errorf("write CA key: %w", err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return fmt.Errorf("write CA certificate: %w", err)
+	}
+
+	logger.Log.Warn("generated device CA", zap.String("cert", certFile))
+	return nil
+}
+
+func randomSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("generate serial: %w", err)
+	}
+	return serial, nil
+}
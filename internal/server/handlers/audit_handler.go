@@ -0,0 +1,91 @@
+// AuditHandler выдаёт журнал аудита.
+//
+// Поддерживаемые эндпоинты:
+//
+//   - GET /api/audit — события пользователя и события с его записями
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/apierror"
+	"github.com/fatkulllin/gophkeeper/internal/server/ctxkeys"
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// AuditService определяет интерфейс бизнес-логики журнала аудита.
+type AuditService interface {
+	List(ctx context.Context, userID int, filter model.AuditFilter) ([]model.AuditEvent, error)
+}
+
+// AuditHandler обрабатывает HTTP-запросы к журналу аудита.
+type AuditHandler struct {
+	service AuditService
+}
+
+// NewAuditHandler создаёт новый AuditHandler.
+func NewAuditHandler(service AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// List возвращает события журнала аудита от новых к старым.
+// Поддерживает фильтры в query-параметрах: action, result, record_id,
+// since и until (RFC 3339), limit.
+//
+// GET /api/audit
+func (h *AuditHandler) List(res http.ResponseWriter, req *http.Request) {
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+
+	if !ok {
+		apierror.Write(res, req, errNoClaims)
+		return
+	}
+
+	filter, err := parseAuditFilter(req)
+	if err != nil {
+		apierror.Write(res, req, model.Errorf(model.CodeValidation, "invalid filter: %s", err))
+		return
+	}
+
+	events, err := h.service.List(req.Context(), claims.UserID, filter)
+	if err != nil {
+		apierror.Write(res, req, err)
+		return
+	}
+
+	writeJSON(res, http.StatusOK, events)
+}
+
+func parseAuditFilter(req *http.Request) (model.AuditFilter, error) {
+	query := req.URL.Query()
+	filter := model.AuditFilter{
+		Action: model.AuditAction(query.Get("action")),
+		Result: model.AuditResult(query.Get("result")),
+	}
+
+	var err error
+	if v := query.Get("record_id"); v != "" {
+		if filter.RecordID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return model.AuditFilter{}, err
+		}
+	}
+	if v := query.Get("since"); v != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			return model.AuditFilter{}, err
+		}
+	}
+	if v := query.Get("until"); v != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			return model.AuditFilter{}, err
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			return model.AuditFilter{}, err
+		}
+	}
+	return filter, nil
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/fatkulllin/gophkeeper/internal/server/apierror"
 	"github.com/fatkulllin/gophkeeper/model"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
 	"github.com/go-playground/validator/v10"
@@ -14,7 +15,7 @@ import (
 
 type AuthService interface {
 	UserRegister(ctx context.Context, user model.UserCredentials) (string, int, error)
-	UserLogin(ctx context.Context, user model.UserCredentials, wantUserKey bool) (string, int, string, error)
+	UserLogin(ctx context.Context, user model.UserCredentials, wantUserKey bool) (string, int, model.UserKeyRespone, error)
 }
 
 type AuthHandler struct {
@@ -26,7 +27,7 @@ func NewAuthHandler(service AuthService, validate *validator.Validate) *AuthHand
 	return &AuthHandler{service: service, validate: validate}
 }
 
-func writeAuthSuccessResponse(res http.ResponseWriter, token string, expires int, userKey string) {
+func writeAuthSuccessResponse(res http.ResponseWriter, token string, expires int, keys model.UserKeyRespone) {
 	cookie := &http.Cookie{
 		Name:     "auth_token",
 		Value:    token,
@@ -38,15 +39,10 @@ func writeAuthSuccessResponse(res http.ResponseWriter, token string, expires int
 	}
 	http.SetCookie(res, cookie)
 
-	if userKey != "" {
-		var userKeyResponse model.UserKeyRespone
-		userKeyResponse.UserKey = userKey
+	if keys.UserKey != "" {
 		res.Header().Set("Content-Type", "application/json")
-		err := json.NewEncoder(res).Encode(userKeyResponse)
-		if err != nil {
+		if err := json.NewEncoder(res).Encode(keys); err != nil {
 			logger.Log.Error("json encoder error", zap.Error(err))
-			http.Error(res, "error", http.StatusInternalServerError)
-			return
 		}
 		return
 	}
@@ -63,12 +59,12 @@ func (h *AuthHandler) UserRegister(res http.ResponseWriter, req *http.Request) {
 	var user model.UserCredentials
 
 	if err := json.NewDecoder(req.Body).Decode(&user); err != nil {
-		http.Error(res, "Invalid JSON", http.StatusBadRequest)
+		apierror.Write(res, req, errInvalidJSON)
 		return
 	}
 
 	if err := h.validate.Struct(user); err != nil {
-		http.Error(res, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		apierror.Write(res, req, validationError(err))
 		return
 	}
 
@@ -76,14 +72,11 @@ func (h *AuthHandler) UserRegister(res http.ResponseWriter, req *http.Request) {
 	if err != nil {
 		if errors.Is(err, model.ErrUserExists) {
 			logger.Log.Warn("attempt to register existing user", zap.String("login", user.Username))
-			http.Error(res, err.Error(), http.StatusConflict)
-			return
 		}
-		logger.Log.Error("save user", zap.String("login", user.Username), zap.Error(err))
-		http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		apierror.Write(res, req, err)
 		return
 	}
-	writeAuthSuccessResponse(res, tokenString, tokenExpires, "")
+	writeAuthSuccessResponse(res, tokenString, tokenExpires, model.UserKeyRespone{})
 }
 
 func (h *AuthHandler) UserLogin(res http.ResponseWriter, req *http.Request) {
@@ -93,27 +86,27 @@ func (h *AuthHandler) UserLogin(res http.ResponseWriter, req *http.Request) {
 
 	if err := json.NewDecoder(req.Body).Decode(&user); err != nil {
 		logger.Log.Error("", zap.Error(err))
-		http.Error(res, "Invalid JSON", http.StatusBadRequest)
+		apierror.Write(res, req, errInvalidJSON)
 		return
 	}
 
 	if err := h.validate.Struct(user); err != nil {
 		logger.Log.Error("", zap.Error(err))
-		http.Error(res, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		apierror.Write(res, req, validationError(err))
 		return
 	}
-	tokenString, tokenExpires, userKey, err := h.service.UserLogin(req.Context(), user, wantUserKey)
+	tokenString, tokenExpires, keys, err := h.service.UserLogin(req.Context(), user, wantUserKey)
 	if err != nil {
-		if errors.Is(err, model.ErrIncorrectPassword) {
+		switch {
+		case errors.Is(err, model.ErrIncorrectPassword):
 			logger.Log.Warn("attempt to login incorrect password", zap.String("login", user.Username))
-			http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
-			return
+		case errors.Is(err, model.ErrDeviceRequired):
+			logger.Log.Warn("attempt to login without enrolled device", zap.String("login", user.Username))
 		}
-		logger.Log.Error("login user", zap.String("login", user.Username), zap.Error(err))
-		http.Error(res, "internal server error", http.StatusInternalServerError)
+		apierror.Write(res, req, err)
 		return
 	}
-	writeAuthSuccessResponse(res, tokenString, tokenExpires, userKey)
+	writeAuthSuccessResponse(res, tokenString, tokenExpires, keys)
 }
 
 func (h *AuthHandler) UserLogout(res http.ResponseWriter, req *http.Request) {
@@ -0,0 +1,144 @@
+// DeviceHandler обрабатывает регистрацию устройств и их сертификатов.
+//
+// Поддерживаемые эндпоинты:
+//
+//   - POST   /api/devices/enroll — выпуск сертификата устройства по CSR
+//   - GET    /api/devices        — устройства пользователя
+//   - DELETE /api/devices/{id}   — отзыв сертификата устройства (CRL)
+//   - PUT    /api/devices/policy — обязательный вход с сертификатом устройства
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/apierror"
+	"github.com/fatkulllin/gophkeeper/internal/server/ctxkeys"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// DeviceService определяет интерфейс бизнес-логики для работы с устройствами.
+type DeviceService interface {
+	Enroll(ctx context.Context, userID int, login string, input model.EnrollInput) (model.EnrollResponse, error)
+	List(ctx context.Context, userID int) ([]model.Device, error)
+	Revoke(ctx context.Context, userID int, deviceID int) error
+	SetPolicy(ctx context.Context, userID int, policy model.DevicePolicy) error
+}
+
+// DeviceHandler обрабатывает HTTP-запросы, связанные с устройствами.
+type DeviceHandler struct {
+	service  DeviceService
+	validate *validator.Validate
+}
+
+// NewDeviceHandler создаёт новый DeviceHandler.
+func NewDeviceHandler(service DeviceService, validate *validator.Validate) *DeviceHandler {
+	return &DeviceHandler{service: service, validate: validate}
+}
+
+// Enroll выпускает сертификат устройства по CSR.
+//
+// POST /api/devices/enroll
+func (h *DeviceHandler) Enroll(res http.ResponseWriter, req *http.Request) {
+	var input model.EnrollInput
+
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+
+	if !ok {
+		apierror.Write(res, req, errNoClaims)
+		return
+	}
+
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		apierror.Write(res, req, errInvalidJSON)
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		apierror.Write(res, req, validationError(err))
+		return
+	}
+
+	enrolled, err := h.service.Enroll(req.Context(), claims.UserID, claims.UserLogin, input)
+	if err != nil {
+		apierror.Write(res, req, err)
+		return
+	}
+
+	writeJSON(res, http.StatusCreated, enrolled)
+}
+
+// List возвращает устройства пользователя.
+//
+// GET /api/devices
+func (h *DeviceHandler) List(res http.ResponseWriter, req *http.Request) {
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+
+	if !ok {
+		apierror.Write(res, req, errNoClaims)
+		return
+	}
+
+	devices, err := h.service.List(req.Context(), claims.UserID)
+	if err != nil {
+		apierror.Write(res, req, err)
+		return
+	}
+
+	writeJSON(res, http.StatusOK, devices)
+}
+
+// Revoke отзывает сертификат устройства.
+//
+// DELETE /api/devices/{id}
+func (h *DeviceHandler) Revoke(res http.ResponseWriter, req *http.Request) {
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+
+	if !ok {
+		apierror.Write(res, req, errNoClaims)
+		return
+	}
+
+	deviceID, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		apierror.Write(res, req, model.NewError(model.CodeValidation, "invalid device id"))
+		return
+	}
+
+	if err := h.service.Revoke(req.Context(), claims.UserID, deviceID); err != nil {
+		apierror.Write(res, req, err)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+}
+
+// SetPolicy включает или отключает обязательный вход с сертификатом устройства.
+//
+// PUT /api/devices/policy
+func (h *DeviceHandler) SetPolicy(res http.ResponseWriter, req *http.Request) {
+	var policy model.DevicePolicy
+
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+
+	if !ok {
+		apierror.Write(res, req, errNoClaims)
+		return
+	}
+
+	if err := json.NewDecoder(req.Body).Decode(&policy); err != nil {
+		apierror.Write(res, req, errInvalidJSON)
+		return
+	}
+
+	if err := h.service.SetPolicy(req.Context(), claims.UserID, policy); err != nil {
+		apierror.Write(res, req, err)
+		return
+	}
+
+	writeJSON(res, http.StatusOK, policy)
+}
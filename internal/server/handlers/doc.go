@@ -8,9 +8,14 @@
 //   - AuthHandler — обработка регистрации, входа и выхода пользователя;
 //   - RecordHandler — работа с пользовательскими записями (создание,
 //     получение, обновление, удаление);
+//   - OrgHandler — организации, их участники и коллекции;
+//   - AuditHandler — журнал аудита и проверка его целостности;
+//   - DeviceHandler — сертификаты устройств для взаимной TLS-аутентификации;
+//   - VaultHandler — чтение секретов в API, совместимом с Vault KV v2;
 //   - HealthHandler — эндпоинт проверки состояния сервера;
 //   - LoggerHandler — изменение уровня логирования во время работы сервера.
 //
 // Хендлеры не содержат бизнес-логики. Всё поведение делегируется в слой service.
 // Каждый хендлер отвечает только за HTTP-обвязку и корректное формирование ответов.
+// Ошибки сервисов записываются через apierror.Write в едином формате JSON.
 package handlers
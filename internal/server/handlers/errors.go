@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// Ошибки разбора запросов, общие для хендлеров.
+var (
+	errNoClaims    = model.NewError(model.CodeUnauthorized, "claims not found")
+	errInvalidJSON = model.NewError(model.CodeValidation, "invalid JSON")
+)
+
+// validationError возвращает ошибку проверки входных данных.
+func validationError(err error) error {
+	return model.Errorf(model.CodeValidation, "validation failed: %s", err)
+}
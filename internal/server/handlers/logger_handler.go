@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/fatkulllin/gophkeeper/internal/server/apierror"
 	"github.com/fatkulllin/gophkeeper/model"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
 	"github.com/go-playground/validator/v10"
@@ -35,18 +36,17 @@ func (h *LoggerHandler) SetLevel(res http.ResponseWriter, req *http.Request) {
 	var level model.LogLevel
 
 	if err := json.NewDecoder(req.Body).Decode(&level); err != nil {
-		http.Error(res, "invalid JSON", http.StatusBadRequest)
+		apierror.Write(res, req, errInvalidJSON)
 		return
 	}
 
 	if err := h.validate.Struct(level); err != nil {
-		http.Error(res, "validation failed: "+err.Error(), http.StatusBadRequest)
+		apierror.Write(res, req, validationError(err))
 		return
 	}
 
 	if err := logger.SetLevel(level.Level); err != nil {
-		logger.Log.Error("failed to set log level", zap.String("level", level.Level), zap.Error(err))
-		http.Error(res, "internal error", http.StatusInternalServerError)
+		apierror.Write(res, req, model.Errorf(model.CodeValidation, "invalid log level %q", level.Level))
 		return
 	}
 
@@ -0,0 +1,214 @@
+// OrgHandler обрабатывает операции с организациями, участниками и коллекциями.
+//
+// Поддерживаемые эндпоинты:
+//
+//   - POST /api/orgs                         — создание организации
+//   - GET  /api/orgs                         — организации пользователя
+//   - POST /api/orgs/{orgID}/members         — приглашение участника
+//   - GET  /api/orgs/{orgID}/members         — список участников
+//   - POST /api/orgs/{orgID}/collections     — создание коллекции
+//   - GET  /api/orgs/{orgID}/collections     — список коллекций
+//
+// Роль пользователя в организации определяет auth.OrgMiddleware.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/apierror"
+	"github.com/fatkulllin/gophkeeper/internal/server/ctxkeys"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// OrgService определяет интерфейс бизнес-логики для операций с организациями.
+type OrgService interface {
+	Create(ctx context.Context, userID int, input model.OrgInput) (model.Organization, error)
+	List(ctx context.Context, userID int) ([]model.Organization, error)
+	Invite(ctx context.Context, userID int, orgID int, role model.OrgRole, input model.InviteInput) error
+	Members(ctx context.Context, orgID int) ([]model.OrgMember, error)
+	CreateCollection(ctx context.Context, orgID int, role model.OrgRole, input model.CollectionInput) (model.Collection, error)
+	Collections(ctx context.Context, userID int, orgID int) ([]model.Collection, error)
+}
+
+// OrgHandler обрабатывает HTTP-запросы, связанные с организациями.
+type OrgHandler struct {
+	service  OrgService
+	validate *validator.Validate
+}
+
+// NewOrgHandler создаёт новый OrgHandler.
+func NewOrgHandler(service OrgService, validate *validator.Validate) *OrgHandler {
+	return &OrgHandler{service: service, validate: validate}
+}
+
+// Create создаёт организацию. Создатель становится её владельцем.
+//
+// POST /api/orgs
+func (h *OrgHandler) Create(res http.ResponseWriter, req *http.Request) {
+	var input model.OrgInput
+
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+
+	if !ok {
+		apierror.Write(res, req, errNoClaims)
+		return
+	}
+
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		apierror.Write(res, req, errInvalidJSON)
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		apierror.Write(res, req, validationError(err))
+		return
+	}
+
+	org, err := h.service.Create(req.Context(), claims.UserID, input)
+	if err != nil {
+		apierror.Write(res, req, err)
+		return
+	}
+
+	writeJSON(res, http.StatusCreated, org)
+}
+
+// List возвращает организации пользователя.
+//
+// GET /api/orgs
+func (h *OrgHandler) List(res http.ResponseWriter, req *http.Request) {
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+
+	if !ok {
+		apierror.Write(res, req, errNoClaims)
+		return
+	}
+
+	orgs, err := h.service.List(req.Context(), claims.UserID)
+	if err != nil {
+		apierror.Write(res, req, err)
+		return
+	}
+
+	writeJSON(res, http.StatusOK, orgs)
+}
+
+// Invite добавляет пользователя в организацию.
+//
+// POST /api/orgs/{orgID}/members
+func (h *OrgHandler) Invite(res http.ResponseWriter, req *http.Request) {
+	var input model.InviteInput
+
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+
+	if !ok {
+		apierror.Write(res, req, errNoClaims)
+		return
+	}
+
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		apierror.Write(res, req, errInvalidJSON)
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		apierror.Write(res, req, validationError(err))
+		return
+	}
+
+	if err := h.service.Invite(req.Context(), claims.UserID, claims.OrgID, claims.OrgRole, input); err != nil {
+		apierror.Write(res, req, err)
+		return
+	}
+
+	writeJSON(res, http.StatusOK, map[string]string{
+		"status":  "ok",
+		"invited": input.Username,
+	})
+}
+
+// Members возвращает участников организации.
+//
+// GET /api/orgs/{orgID}/members
+func (h *OrgHandler) Members(res http.ResponseWriter, req *http.Request) {
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+
+	if !ok {
+		apierror.Write(res, req, errNoClaims)
+		return
+	}
+
+	members, err := h.service.Members(req.Context(), claims.OrgID)
+	if err != nil {
+		apierror.Write(res, req, err)
+		return
+	}
+
+	writeJSON(res, http.StatusOK, members)
+}
+
+// CreateCollection создаёт коллекцию организации.
+//
+// POST /api/orgs/{orgID}/collections
+func (h *OrgHandler) CreateCollection(res http.ResponseWriter, req *http.Request) {
+	var input model.CollectionInput
+
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+
+	if !ok {
+		apierror.Write(res, req, errNoClaims)
+		return
+	}
+
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		apierror.Write(res, req, errInvalidJSON)
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		apierror.Write(res, req, validationError(err))
+		return
+	}
+
+	collection, err := h.service.CreateCollection(req.Context(), claims.OrgID, claims.OrgRole, input)
+	if err != nil {
+		apierror.Write(res, req, err)
+		return
+	}
+
+	writeJSON(res, http.StatusCreated, collection)
+}
+
+// Collections возвращает коллекции организации.
+//
+// GET /api/orgs/{orgID}/collections
+func (h *OrgHandler) Collections(res http.ResponseWriter, req *http.Request) {
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+
+	if !ok {
+		apierror.Write(res, req, errNoClaims)
+		return
+	}
+
+	collections, err := h.service.Collections(req.Context(), claims.UserID, claims.OrgID)
+	if err != nil {
+		apierror.Write(res, req, err)
+		return
+	}
+
+	writeJSON(res, http.StatusOK, collections)
+}
+
+// writeJSON записывает ответ в формате JSON с указанным статусом.
+func writeJSON(res http.ResponseWriter, status int, body any) {
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(status)
+	if err := json.NewEncoder(res).Encode(body); err != nil {
+		logger.Log.Error("json encoder error", zap.Error(err))
+	}
+}
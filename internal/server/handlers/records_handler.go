@@ -7,6 +7,14 @@
 //   - GET    /api/records/{id}  — получение записи по ID
 //   - DELETE /api/records/{id}  — удаление записи
 //   - PATCH  /api/records/{id}  — обновление записи
+//   - POST   /api/records/{id}/shares            — открыть доступ к записи
+//   - GET    /api/records/{id}/shares            — список пользователей с доступом
+//   - DELETE /api/records/{id}/shares/{username} — отозвать доступ
+//
+// Параметр org (?org=<id>) переключает создание и список записей на коллекции
+// организации; членство проверяет auth.OrgMiddleware. Параметр expiring_within
+// (?expiring_within=30d) оставляет в списке записи, срок действия которых
+// истекает в пределах указанного интервала или уже истёк.
 //
 // Хендлеры извлекают идентификатор пользователя из JWT (через контекст),
 // проводят базовую проверку входных данных и вызывают доменный сервис.
@@ -15,13 +23,13 @@ package handlers
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
-	"errors"
 	"net/http"
 
+	"github.com/fatkulllin/gophkeeper/internal/server/apierror"
 	"github.com/fatkulllin/gophkeeper/internal/server/ctxkeys"
 	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/duration"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
@@ -31,11 +39,14 @@ import (
 // RecordService определяет интерфейс бизнес-логики для операций над
 // пользовательскими записями.
 type RecordService interface {
-	Create(ctx context.Context, userID int, input model.RecordInput) error
-	GetAll(ctx context.Context, userID int) ([]model.Record, error)
+	Create(ctx context.Context, userID int, orgID int, input model.RecordInput) error
+	GetAll(ctx context.Context, userID int, orgID int, filter model.RecordFilter) ([]model.Record, error)
 	Get(ctx context.Context, userID int, idRecord string) (model.RecordResponse, error)
 	Delete(ctx context.Context, userID int, idRecord string) error
 	Update(ctx context.Context, userID int, idRecord string, record model.RecordUpdateInput) error
+	Share(ctx context.Context, ownerID int, idRecord string, input model.ShareInput) error
+	Unshare(ctx context.Context, ownerID int, idRecord string, username string) error
+	ListShares(ctx context.Context, ownerID int, idRecord string) ([]model.Share, error)
 }
 
 // RecordHandler обрабатывает HTTP-запросы, связанные с пользовательскими записями.
@@ -51,7 +62,8 @@ func NewRecordHandler(service RecordService, validate *validator.Validate) *Reco
 	return &RecordHandler{service: service, validate: validate}
 }
 
-// CreateRecord обрабатывает создание записи.
+// CreateRecord обрабатывает создание записи. С параметром org
+// запись создаётся в коллекции организации.
 //
 // POST /api/record
 func (h *RecordHandler) CreateRecord(res http.ResponseWriter, req *http.Request) {
@@ -60,45 +72,50 @@ func (h *RecordHandler) CreateRecord(res http.ResponseWriter, req *http.Request)
 	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
 
 	if !ok {
-		http.Error(res, "claims not found", http.StatusUnauthorized)
+		apierror.Write(res, req, errNoClaims)
 		return
 	}
 
 	if err := json.NewDecoder(req.Body).Decode(&record); err != nil {
 		logger.Log.Error("error decode json", zap.Error(err))
-		http.Error(res, "Invalid JSON", http.StatusBadRequest)
+		apierror.Write(res, req, errInvalidJSON)
 		return
 	}
 
-	if err := h.service.Create(req.Context(), claims.UserID, record); err != nil {
-		http.Error(res, "error", http.StatusInternalServerError)
+	if err := h.service.Create(req.Context(), claims.UserID, claims.OrgID, record); err != nil {
+		apierror.Write(res, req, err)
 		return
 	}
 }
 
-// ListRecords возвращает список всех записей пользователя.
+// ListRecords возвращает список всех записей пользователя,
+// а с параметром org — записи коллекций организации.
 //
 // GET /api/records
 func (h *RecordHandler) ListRecords(res http.ResponseWriter, req *http.Request) {
 	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
 
 	if !ok {
-		http.Error(res, "claims not found", http.StatusUnauthorized)
+		apierror.Write(res, req, errNoClaims)
 		return
 	}
-	result, err := h.service.GetAll(req.Context(), claims.UserID)
-	if err != nil {
-		http.Error(res, "error", http.StatusInternalServerError)
-		return
+	var filter model.RecordFilter
+	if within := req.URL.Query().Get("expiring_within"); within != "" {
+		d, err := duration.Parse(within)
+		if err != nil || d <= 0 {
+			apierror.Write(res, req, model.NewError(model.CodeValidation, "invalid expiring_within: want a positive duration like 30d or 12h"))
+			return
+		}
+		filter.ExpiringWithin = d
 	}
 
-	res.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(res).Encode(result)
+	result, err := h.service.GetAll(req.Context(), claims.UserID, claims.OrgID, filter)
 	if err != nil {
-		logger.Log.Error("json encoder error", zap.Error(err))
-		http.Error(res, "error", http.StatusInternalServerError)
+		apierror.Write(res, req, err)
 		return
 	}
+
+	writeJSON(res, http.StatusOK, result)
 }
 
 // GetRecord возвращает запись по ID.
@@ -109,21 +126,15 @@ func (h *RecordHandler) GetRecord(res http.ResponseWriter, req *http.Request) {
 	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
 
 	if !ok {
-		http.Error(res, "claims not found", http.StatusUnauthorized)
+		apierror.Write(res, req, errNoClaims)
 		return
 	}
 	record, err := h.service.Get(req.Context(), claims.UserID, idRecord)
 	if err != nil {
-		http.Error(res, "error", http.StatusInternalServerError)
-		return
-	}
-	res.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(res).Encode(record)
-	if err != nil {
-		logger.Log.Error("json encoder error", zap.Error(err))
-		http.Error(res, "error", http.StatusInternalServerError)
+		apierror.Write(res, req, err)
 		return
 	}
+	writeJSON(res, http.StatusOK, record)
 }
 
 // Delete удаляет запись по ID.
@@ -134,17 +145,12 @@ func (h *RecordHandler) Delete(res http.ResponseWriter, req *http.Request) {
 	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
 
 	if !ok {
-		http.Error(res, "claims not found", http.StatusUnauthorized)
+		apierror.Write(res, req, errNoClaims)
 		return
 	}
 	err := h.service.Delete(req.Context(), claims.UserID, idRecord)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			http.Error(res, "record not found", http.StatusNotFound)
-			return
-		}
-		logger.Log.Error("delete record", zap.String("record id", idRecord), zap.Error(err))
-		http.Error(res, "error", http.StatusInternalServerError)
+		apierror.Write(res, req, err)
 		return
 	}
 
@@ -156,7 +162,8 @@ func (h *RecordHandler) Delete(res http.ResponseWriter, req *http.Request) {
 }
 
 // Update обновляет запись. Разрешено обновлять только те поля,
-// которые явно указаны в JSON (metadata, data).
+// которые явно указаны в JSON (metadata, data, expires_at, ephemeral;
+// clear_expiry снимает срок действия).
 //
 // PATCH /api/records/{id}
 func (h *RecordHandler) Update(res http.ResponseWriter, req *http.Request) {
@@ -165,29 +172,19 @@ func (h *RecordHandler) Update(res http.ResponseWriter, req *http.Request) {
 	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
 
 	if !ok {
-		http.Error(res, "claims not found", http.StatusUnauthorized)
+		apierror.Write(res, req, errNoClaims)
 		return
 	}
 
 	if err := json.NewDecoder(req.Body).Decode(&record); err != nil {
 		logger.Log.Error("error decode json", zap.Error(err))
-		http.Error(res, "Invalid JSON", http.StatusBadRequest)
-		return
-	}
-
-	if record.Data == nil && record.Metadata == nil {
-		http.Error(res, "no fields to update", http.StatusBadRequest)
+		apierror.Write(res, req, errInvalidJSON)
 		return
 	}
 
 	err := h.service.Update(req.Context(), claims.UserID, idRecord, record)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			http.Error(res, "record not found", http.StatusNotFound)
-			return
-		}
-		logger.Log.Error("failed to update record", zap.String("record id", idRecord), zap.Error(err))
-		http.Error(res, "failed to update record", http.StatusInternalServerError)
+		apierror.Write(res, req, err)
 		return
 	}
 
@@ -197,3 +194,85 @@ func (h *RecordHandler) Update(res http.ResponseWriter, req *http.Request) {
 		"updated": idRecord,
 	})
 }
+
+// ShareRecord открывает другому пользователю доступ к записи.
+//
+// POST /api/records/{id}/shares
+func (h *RecordHandler) ShareRecord(res http.ResponseWriter, req *http.Request) {
+	var input model.ShareInput
+	idRecord := chi.URLParam(req, "id")
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+
+	if !ok {
+		apierror.Write(res, req, errNoClaims)
+		return
+	}
+
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		logger.Log.Error("error decode json", zap.Error(err))
+		apierror.Write(res, req, errInvalidJSON)
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		apierror.Write(res, req, validationError(err))
+		return
+	}
+
+	if err := h.service.Share(req.Context(), claims.UserID, idRecord, input); err != nil {
+		apierror.Write(res, req, err)
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(res).Encode(map[string]string{
+		"status": "ok",
+		"shared": idRecord,
+	})
+}
+
+// UnshareRecord отзывает доступ пользователя к записи.
+//
+// DELETE /api/records/{id}/shares/{username}
+func (h *RecordHandler) UnshareRecord(res http.ResponseWriter, req *http.Request) {
+	idRecord := chi.URLParam(req, "id")
+	username := chi.URLParam(req, "username")
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+
+	if !ok {
+		apierror.Write(res, req, errNoClaims)
+		return
+	}
+
+	if err := h.service.Unshare(req.Context(), claims.UserID, idRecord, username); err != nil {
+		apierror.Write(res, req, err)
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(res).Encode(map[string]string{
+		"status":  "ok",
+		"revoked": username,
+	})
+}
+
+// ListShares возвращает пользователей, которым открыт доступ к записи.
+//
+// GET /api/records/{id}/shares
+func (h *RecordHandler) ListShares(res http.ResponseWriter, req *http.Request) {
+	idRecord := chi.URLParam(req, "id")
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+
+	if !ok {
+		apierror.Write(res, req, errNoClaims)
+		return
+	}
+
+	shares, err := h.service.ListShares(req.Context(), claims.UserID, idRecord)
+	if err != nil {
+		apierror.Write(res, req, err)
+		return
+	}
+
+	writeJSON(res, http.StatusOK, shares)
+}
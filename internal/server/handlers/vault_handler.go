@@ -0,0 +1,286 @@
+// VaultHandler реализует чтение секретов в API, совместимом с движком
+// HashiCorp Vault KV v2, чтобы существующие инструменты (vault CLI,
+// consul-template, External Secrets и т.п.) читали записи GophKeeper без
+// изменений.
+//
+// Поддерживаемые эндпоинты (mount — точка монтирования из конфигурации):
+//
+//   - GET  /v1/{mount}/data/{path}                 — чтение секрета
+//   - GET  /v1/{mount}/metadata/{path}             — метаданные секрета
+//   - LIST /v1/{mount}/metadata/{path}             — список ключей (или GET ?list=true)
+//   - GET  /v1/sys/internal/ui/mounts/{mount}/...  — версия движка для vault CLI
+//   - GET  /v1/auth/token/lookup-self              — сведения о токене
+//
+// Путь секрета — метаданные записи, данные записи — поля секрета. Версии
+// не хранятся: у секрета всегда одна версия 1. Ошибки возвращаются в
+// формате Vault: {"errors": ["..."]}.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/auth"
+	"github.com/fatkulllin/gophkeeper/internal/server/ctxkeys"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// VaultService определяет операции чтения секретов для API Vault KV v2.
+type VaultService interface {
+	ReadSecret(ctx context.Context, userID int, path string) (model.RecordResponse, error)
+	ListSecrets(ctx context.Context, userID int, prefix string) ([]string, error)
+}
+
+// VaultHandler обрабатывает запросы клиентов Vault.
+type VaultHandler struct {
+	service VaultService
+	mount   string
+}
+
+// NewVaultHandler создаёт VaultHandler для точки монтирования mount.
+func NewVaultHandler(service VaultService, mount string) *VaultHandler {
+	return &VaultHandler{service: service, mount: strings.Trim(mount, "/")}
+}
+
+// Mount возвращает точку монтирования движка KV.
+func (h *VaultHandler) Mount() string {
+	return h.mount
+}
+
+// vaultResponse — конверт ответа Vault.
+type vaultResponse struct {
+	RequestID     string   `json:"request_id"`
+	LeaseID       string   `json:"lease_id"`
+	Renewable     bool     `json:"renewable"`
+	LeaseDuration int      `json:"lease_duration"`
+	Data          any      `json:"data"`
+	WrapInfo      any      `json:"wrap_info"`
+	Warnings      []string `json:"warnings"`
+	Auth          any      `json:"auth"`
+}
+
+// vaultVersion — метаданные версии секрета.
+type vaultVersion struct {
+	CreatedTime    string            `json:"created_time"`
+	CustomMetadata map[string]string `json:"custom_metadata"`
+	DeletionTime   string            `json:"deletion_time"`
+	Destroyed      bool              `json:"destroyed"`
+	Version        int               `json:"version"`
+}
+
+// secretVersion — единственная версия секретов GophKeeper.
+const secretVersion = 1
+
+// ReadSecret возвращает поля секрета. Данные записи, не являющиеся
+// JSON-объектом, возвращаются в поле value.
+//
+// GET /v1/{mount}/data/{path}
+func (h *VaultHandler) ReadSecret(res http.ResponseWriter, req *http.Request) {
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+	if !ok {
+		writeVaultError(res, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	path := secretPath(req)
+	if v := req.URL.Query().Get("version"); v != "" && v != "0" && v != strconv.Itoa(secretVersion) {
+		writeVaultError(res, http.StatusNotFound)
+		return
+	}
+
+	record, ok := h.readSecret(res, req, claims, path)
+	if !ok {
+		return
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(record.Data, &fields); err != nil || fields == nil {
+		fields = map[string]any{"value": record.Data}
+	}
+
+	writeVaultResponse(res, map[string]any{
+		"data":     fields,
+		"metadata": newVaultVersion(record),
+	})
+}
+
+// ReadMetadata возвращает метаданные секрета, а с ?list=true — список ключей.
+//
+// GET /v1/{mount}/metadata/{path}
+func (h *VaultHandler) ReadMetadata(res http.ResponseWriter, req *http.Request) {
+	if list, _ := strconv.ParseBool(req.URL.Query().Get("list")); list {
+		h.ListSecrets(res, req)
+		return
+	}
+
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+	if !ok {
+		writeVaultError(res, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	record, ok := h.readSecret(res, req, claims, secretPath(req))
+	if !ok {
+		return
+	}
+
+	version := newVaultVersion(record)
+	writeVaultResponse(res, map[string]any{
+		"cas_required":         false,
+		"created_time":         version.CreatedTime,
+		"current_version":      secretVersion,
+		"custom_metadata":      version.CustomMetadata,
+		"delete_version_after": "0s",
+		"max_versions":         0,
+		"oldest_version":       secretVersion,
+		"updated_time":         version.CreatedTime,
+		"versions": map[string]vaultVersion{
+			strconv.Itoa(secretVersion): version,
+		},
+	})
+}
+
+// ListSecrets возвращает ключи на уровне пути; «каталоги» заканчиваются "/".
+//
+// LIST /v1/{mount}/metadata/{path}
+func (h *VaultHandler) ListSecrets(res http.ResponseWriter, req *http.Request) {
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+	if !ok {
+		writeVaultError(res, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	prefix := secretPath(req)
+	keys, err := h.service.ListSecrets(req.Context(), claims.UserID, prefix)
+	if err != nil {
+		if errors.Is(err, model.ErrRecordNotFound) {
+			writeVaultError(res, http.StatusNotFound)
+			return
+		}
+		logger.Log.Error("vault list secrets", zap.String("path", prefix), zap.Error(err))
+		writeVaultError(res, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	writeVaultResponse(res, map[string]any{"keys": keys})
+}
+
+// MountInfo сообщает vault CLI, что путь обслуживает движок KV версии 2.
+//
+// GET /v1/sys/internal/ui/mounts/{path}
+func (h *VaultHandler) MountInfo(res http.ResponseWriter, req *http.Request) {
+	path := strings.Trim(chi.URLParam(req, "*"), "/")
+	if path != h.mount && !strings.HasPrefix(path, h.mount+"/") {
+		writeVaultError(res, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	writeVaultResponse(res, map[string]any{
+		"path":        h.mount + "/",
+		"type":        "kv",
+		"description": "GophKeeper records",
+		"options":     map[string]string{"version": "2"},
+	})
+}
+
+// LookupSelf возвращает сведения о токене запроса. Им пользуются клиенты,
+// проверяющие токен и срок его действия.
+//
+// GET /v1/auth/token/lookup-self
+func (h *VaultHandler) LookupSelf(res http.ResponseWriter, req *http.Request) {
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+	if !ok {
+		writeVaultError(res, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	data := map[string]any{
+		"accessor":         "",
+		"display_name":     "gophkeeper-" + claims.UserLogin,
+		"entity_id":        "",
+		"explicit_max_ttl": 0,
+		"id":               auth.VaultToken(req),
+		"meta":             map[string]string{"username": claims.UserLogin},
+		"num_uses":         0,
+		"orphan":           true,
+		"path":             "auth/gophkeeper/login",
+		"policies":         []string{"default"},
+		"renewable":        false,
+		"ttl":              0,
+		"type":             "service",
+	}
+	if claims.IssuedAt != nil {
+		data["creation_time"] = claims.IssuedAt.Unix()
+	}
+	if claims.ExpiresAt != nil {
+		data["expire_time"] = claims.ExpiresAt.UTC().Format(time.RFC3339)
+		data["ttl"] = int(time.Until(claims.ExpiresAt.Time).Seconds())
+		if claims.IssuedAt != nil {
+			data["creation_ttl"] = int(claims.ExpiresAt.Sub(claims.IssuedAt.Time).Seconds())
+		}
+	}
+
+	writeVaultResponse(res, data)
+}
+
+// readSecret читает запись по пути секрета и при ошибке пишет ответ сам.
+func (h *VaultHandler) readSecret(res http.ResponseWriter, req *http.Request, claims model.Claims, path string) (model.RecordResponse, bool) {
+	record, err := h.service.ReadSecret(req.Context(), claims.UserID, path)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRecordNotFound):
+			writeVaultError(res, http.StatusNotFound)
+		case errors.Is(err, model.ErrAmbiguousSecretPath):
+			writeVaultError(res, http.StatusBadRequest, err.Error())
+		default:
+			logger.Log.Error("vault read secret", zap.String("path", path), zap.Error(err))
+			writeVaultError(res, http.StatusInternalServerError, "internal error")
+		}
+		return model.RecordResponse{}, false
+	}
+	return record, true
+}
+
+// secretPath возвращает путь секрета из адреса запроса без "/" по краям.
+func secretPath(req *http.Request) string {
+	return strings.Trim(chi.URLParam(req, "*"), "/")
+}
+
+// newVaultVersion описывает запись как версию секрета. Идентификатор и тип
+// записи передаются в пользовательских метаданных.
+func newVaultVersion(record model.RecordResponse) vaultVersion {
+	return vaultVersion{
+		CreatedTime: record.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		CustomMetadata: map[string]string{
+			"gophkeeper_id":   strconv.FormatInt(record.ID, 10),
+			"gophkeeper_type": string(record.Type),
+		},
+		Version: secretVersion,
+	}
+}
+
+func writeVaultResponse(res http.ResponseWriter, data any) {
+	res.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(res).Encode(vaultResponse{Data: data}); err != nil {
+		logger.Log.Error("json encoder error", zap.Error(err))
+	}
+}
+
+// writeVaultError пишет ошибку в формате Vault. Как и Vault, на
+// отсутствующий секрет отвечает 404 с пустым списком ошибок.
+func writeVaultError(res http.ResponseWriter, status int, messages ...string) {
+	if messages == nil {
+		messages = []string{}
+	}
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(status)
+	json.NewEncoder(res).Encode(map[string][]string{"errors": messages})
+}
@@ -0,0 +1,116 @@
+// VaultTokenHandler выпускает и отзывает токены клиентов API Vault KV v2.
+//
+// Поддерживаемые эндпоинты:
+//
+//   - POST   /api/vault/tokens      — выпуск токена Vault
+//   - GET    /api/vault/tokens      — токены пользователя
+//   - DELETE /api/vault/tokens/{id} — отзыв токена
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/apierror"
+	"github.com/fatkulllin/gophkeeper/internal/server/ctxkeys"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// VaultTokenService определяет интерфейс бизнес-логики для работы с токенами Vault.
+type VaultTokenService interface {
+	Create(ctx context.Context, userID int, input model.VaultTokenInput) (model.VaultTokenResponse, error)
+	List(ctx context.Context, userID int) ([]model.VaultToken, error)
+	Revoke(ctx context.Context, userID int, tokenID int) error
+}
+
+// VaultTokenHandler обрабатывает HTTP-запросы, связанные с токенами Vault.
+type VaultTokenHandler struct {
+	service  VaultTokenService
+	validate *validator.Validate
+}
+
+// NewVaultTokenHandler создаёт новый VaultTokenHandler.
+func NewVaultTokenHandler(service VaultTokenService, validate *validator.Validate) *VaultTokenHandler {
+	return &VaultTokenHandler{service: service, validate: validate}
+}
+
+// Create выпускает токен Vault. Токен возвращается только в этом ответе.
+//
+// POST /api/vault/tokens
+func (h *VaultTokenHandler) Create(res http.ResponseWriter, req *http.Request) {
+	var input model.VaultTokenInput
+
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+
+	if !ok {
+		apierror.Write(res, req, errNoClaims)
+		return
+	}
+
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		apierror.Write(res, req, errInvalidJSON)
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		apierror.Write(res, req, validationError(err))
+		return
+	}
+
+	token, err := h.service.Create(req.Context(), claims.UserID, input)
+	if err != nil {
+		apierror.Write(res, req, err)
+		return
+	}
+
+	writeJSON(res, http.StatusCreated, token)
+}
+
+// List возвращает токены Vault пользователя.
+//
+// GET /api/vault/tokens
+func (h *VaultTokenHandler) List(res http.ResponseWriter, req *http.Request) {
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+
+	if !ok {
+		apierror.Write(res, req, errNoClaims)
+		return
+	}
+
+	tokens, err := h.service.List(req.Context(), claims.UserID)
+	if err != nil {
+		apierror.Write(res, req, err)
+		return
+	}
+
+	writeJSON(res, http.StatusOK, tokens)
+}
+
+// Revoke отзывает токен Vault.
+//
+// DELETE /api/vault/tokens/{id}
+func (h *VaultTokenHandler) Revoke(res http.ResponseWriter, req *http.Request) {
+	claims, ok := req.Context().Value(ctxkeys.UserContextKey).(model.Claims)
+
+	if !ok {
+		apierror.Write(res, req, errNoClaims)
+		return
+	}
+
+	tokenID, err := strconv.Atoi(chi.URLParam(req, "id"))
+	if err != nil {
+		apierror.Write(res, req, model.NewError(model.CodeValidation, "invalid vault token id"))
+		return
+	}
+
+	if err := h.service.Revoke(req.Context(), claims.UserID, tokenID); err != nil {
+		apierror.Write(res, req, err)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,163 @@
+// Package metrics содержит метрики Prometheus серверного приложения GophKeeper:
+// HTTP- и gRPC-запросы, попытки входа, проверку журнала аудита, состояние
+// пула соединений с базой данных и количество записей по типам.
+//
+// Метрики отдаются эндпоинтом /metrics отдельного сервера метрик
+// (--metrics-address) в формате Prometheus.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+const namespace = "gophkeeper"
+
+// unmatchedRoute — значение метки route для запросов, не совпавших ни с одним маршрутом.
+// Используется вместо исходного пути, чтобы не раздувать число временных рядов.
+const unmatchedRoute = "unmatched"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	grpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "requests_total",
+		Help:      "Number of gRPC calls by method and status code.",
+	}, []string{"method", "code"})
+
+	grpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "request_duration_seconds",
+		Help:      "gRPC call latency by method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	loginAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "login_attempts_total",
+		Help:      "Number of login attempts by result.",
+	}, []string{"result"})
+
+	auditChainValid = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "audit",
+		Name:      "chain_valid",
+		Help:      "Result of the last audit log hash chain verification (1 — intact, 0 — broken).",
+	})
+
+	auditVerifiedEvents = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "audit",
+		Name:      "verified_events",
+		Help:      "Number of audit events verified before the chain end or the first broken event.",
+	})
+)
+
+// ObserveHTTPRequest учитывает обработанный HTTP-запрос.
+// route — шаблон маршрута chi (например, /api/records/{id}).
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+	labels := prometheus.Labels{"method": method, "route": route, "status": strconv.Itoa(status)}
+	httpRequests.With(labels).Inc()
+	httpDuration.With(labels).Observe(duration.Seconds())
+}
+
+// ObserveLogin учитывает попытку входа пользователя.
+func ObserveLogin(success bool) {
+	result := "failure"
+	if success {
+		result = "success"
+	}
+	loginAttempts.WithLabelValues(result).Inc()
+}
+
+// ObserveAuditVerification учитывает результат проверки цепочки журнала аудита.
+func ObserveAuditVerification(valid bool, events int) {
+	if valid {
+		auditChainValid.Set(1)
+	} else {
+		auditChainValid.Set(0)
+	}
+	auditVerifiedEvents.Set(float64(events))
+}
+
+// RecordCounter возвращает количество записей в хранилище по типам.
+type RecordCounter interface {
+	CountRecordsByType(ctx context.Context) (map[model.RecordType]int, error)
+}
+
+// Register регистрирует метрики, которые снимаются при каждом запросе /metrics:
+// статистику пула соединений db и количество записей по типам.
+// Если db равен nil (хранилище в памяти), статистика пула не регистрируется.
+func Register(db *sql.DB, counter RecordCounter) error {
+	if db != nil {
+		if err := prometheus.Register(collectors.NewDBStatsCollector(db, namespace)); err != nil {
+			return err
+		}
+	}
+	return prometheus.Register(newRecordCollector(counter))
+}
+
+// recordCollector запрашивает количество записей по типам в момент сбора метрик.
+type recordCollector struct {
+	counter RecordCounter
+	desc    *prometheus.Desc
+}
+
+func newRecordCollector(counter RecordCounter) *recordCollector {
+	return &recordCollector{
+		counter: counter,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "records"),
+			"Number of stored records by type.",
+			[]string{"type"}, nil,
+		),
+	}
+}
+
+func (c *recordCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *recordCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	counts, err := c.counter.CountRecordsByType(ctx)
+	if err != nil {
+		logger.Log.Error("failed to count records", zap.Error(err))
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for recordType, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), string(recordType))
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/fatkulllin/gophkeeper/internal/server/metrics"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -51,13 +54,28 @@ func RequestLogger(next http.Handler) http.Handler {
 		next.ServeHTTP(lw, req)
 		duration := time.Since(start)
 
-		logger.Log.Info("http request",
+		status := responseData.status
+		if status == 0 {
+			// хендлер не вызвал WriteHeader — net/http ответит 200
+			status = http.StatusOK
+		}
+		var route string
+		if rctx := chi.RouteContext(req.Context()); rctx != nil {
+			route = rctx.RoutePattern()
+		}
+		metrics.ObserveHTTPRequest(req.Method, route, status, duration)
+
+		fields := []zap.Field{
 			zap.String("method", req.Method),
 			zap.String("path", req.URL.Path),
 			zap.Int("status", responseData.status),
 			zap.String("remote_addr", req.RemoteAddr),
 			zap.Int64("duration", duration.Milliseconds()),
 			zap.Int("size", responseData.size),
-		)
+		}
+		if spanContext := trace.SpanContextFromContext(req.Context()); spanContext.HasTraceID() {
+			fields = append(fields, zap.String("trace_id", spanContext.TraceID().String()))
+		}
+		logger.Log.Info("http request", fields...)
 	})
 }
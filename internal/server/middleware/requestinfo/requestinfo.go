@@ -0,0 +1,33 @@
+// Package requestinfo содержит middleware, сохраняющий в контексте запроса
+// сведения о клиенте для журнала аудита.
+package requestinfo
+
+import (
+	"context"
+	"encoding/hex"
+	"net"
+	"net/http"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/ctxkeys"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/cryptoutil"
+)
+
+// RequestInfo сохраняет IP-адрес, User-Agent и отпечаток клиентского
+// сертификата (если он предъявлен) в контексте запроса под ключом ctxkeys.RequestInfoKey.
+func RequestInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		ip, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			ip = req.RemoteAddr
+		}
+		info := model.RequestInfo{IP: ip, UserAgent: req.UserAgent()}
+		if req.TLS != nil && len(req.TLS.PeerCertificates) > 0 {
+			cert := req.TLS.PeerCertificates[0]
+			info.CertThumbprint = cryptoutil.CertThumbprint(cert)
+			info.CertSerial = hex.EncodeToString(cert.SerialNumber.Bytes())
+		}
+		ctx := context.WithValue(req.Context(), ctxkeys.RequestInfoKey, info)
+		next.ServeHTTP(res, req.WithContext(ctx))
+	})
+}
@@ -0,0 +1,93 @@
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+func testAudit(t *testing.T, c *Suite) {
+	ctx := t.Context()
+	audit := c.Storage.Audit
+	ownerID, ownerLogin := c.newUser(t, "owner")
+	readerID, readerLogin := c.newUser(t, "reader")
+	record := c.newRecord(t, ownerID, 0, model.TypeText, "ciphertext")
+
+	// Время усекается до микросекунд, как в AuditService: такую точность хранят все базы.
+	base := time.Now().UTC().Truncate(time.Microsecond)
+	events := []model.AuditEvent{
+		{UserID: ownerID, Login: ownerLogin, Action: model.AuditRecordCreate, RecordID: record.ID, Result: model.AuditSuccess, CreatedAt: base},
+		{UserID: readerID, Login: readerLogin, Action: model.AuditUserLogin, IP: "10.0.0.1", UserAgent: "conformance", Result: model.AuditSuccess, CreatedAt: base.Add(time.Second)},
+		{UserID: readerID, Login: readerLogin, Action: model.AuditRecordRead, RecordID: record.ID, Result: model.AuditDenied, CreatedAt: base.Add(2 * time.Second)},
+	}
+	for _, e := range events {
+		if err := audit.AppendEvent(ctx, e); err != nil {
+			t.Fatalf("AppendEvent: %v", err)
+		}
+	}
+
+	list, err := audit.ListEvents(ctx, ownerID, model.AuditFilter{Limit: 100})
+	if err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	expect(t, len(list) == 2 && list[0].Action == model.AuditRecordRead && list[0].UserID == readerID && list[1].Action == model.AuditRecordCreate,
+		"ListEvents(owner) must return own events and events with own records newest first, got %+v", list)
+	expect(t, list[0].CreatedAt.Equal(events[2].CreatedAt) && list[0].RecordID == record.ID && list[0].Result == model.AuditDenied,
+		"ListEvents returned %+v, want %+v", list[0], events[2])
+
+	filters := []struct {
+		filter model.AuditFilter
+		want   int
+	}{
+		{model.AuditFilter{Action: model.AuditRecordRead, Limit: 100}, 1},
+		{model.AuditFilter{Result: model.AuditSuccess, Limit: 100}, 1},
+		{model.AuditFilter{RecordID: record.ID, Limit: 100}, 2},
+		{model.AuditFilter{Since: base.Add(time.Second), Limit: 100}, 1},
+		{model.AuditFilter{Until: base.Add(time.Second), Limit: 100}, 1},
+		{model.AuditFilter{Limit: 1}, 1},
+	}
+	for _, f := range filters {
+		list, err := audit.ListEvents(ctx, ownerID, f.filter)
+		if err != nil {
+			t.Fatalf("ListEvents: %v", err)
+		}
+		expect(t, len(list) == f.want, "ListEvents(%+v) returned %d events, want %d", f.filter, len(list), f.want)
+	}
+
+	list, err = audit.ListEvents(ctx, readerID, model.AuditFilter{Limit: 100})
+	if err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	expect(t, len(list) == 2 && list[0].IP == "" && list[1].IP == "10.0.0.1" && list[1].UserAgent == "conformance",
+		"ListEvents(reader) = %+v", list)
+
+	chain, err := audit.GetEventsAfter(ctx, 0, 1000)
+	if err != nil {
+		t.Fatalf("GetEventsAfter: %v", err)
+	}
+	prevHash := ""
+	found := 0
+	for _, e := range chain {
+		if e.PrevHash != prevHash || e.Hash != e.ChainHash(prevHash) {
+			t.Fatalf("audit chain is broken at event %d", e.ID)
+		}
+		prevHash = e.Hash
+		if e.UserID == ownerID || e.UserID == readerID {
+			found++
+		}
+	}
+	expect(t, found == len(events), "GetEventsAfter contains %d of %d appended events", found, len(events))
+
+	expect(t, len(chain) >= 2, "audit chain has %d events", len(chain))
+	page, err := audit.GetEventsAfter(ctx, chain[0].ID, 1)
+	if err != nil {
+		t.Fatalf("GetEventsAfter: %v", err)
+	}
+	expect(t, len(page) == 1 && page[0].ID == chain[1].ID, "GetEventsAfter(%d, 1) = %+v, want event %d", chain[0].ID, page, chain[1].ID)
+	page, err = audit.GetEventsAfter(ctx, chain[len(chain)-1].ID, 10)
+	if err != nil {
+		t.Fatalf("GetEventsAfter: %v", err)
+	}
+	expect(t, len(page) == 0, "GetEventsAfter(last) must return no events, got %d", len(page))
+}
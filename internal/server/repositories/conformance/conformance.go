@@ -0,0 +1,149 @@
+// Пакет conformance содержит общий набор проверок репозиториев сервера.
+// Каждая реализация хранилища (postgres, sqlite, memory) должна проходить его
+// целиком: сценарии фиксируют семантику выборок, прав доступа и ошибок,
+// на которую опирается пакет service.
+//
+// Сценарии создают пользователей и организации с уникальными именами,
+// поэтому набор можно запускать на непустой базе.
+package conformance
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/storage"
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// Case — сценарий проверки хранилища.
+type Case struct {
+	Name string
+	Run  func(t *testing.T, c *Suite)
+}
+
+// Cases — все сценарии набора.
+var Cases = []Case{
+	{"users", testUsers},
+	{"keypair", testKeyPair},
+	{"records", testRecords},
+	{"expiry", testExpiry},
+	{"sharing", testSharing},
+	{"organizations", testOrganizations},
+	{"audit", testAudit},
+	{"devices", testDevices},
+	{"vault tokens", testVaultTokens},
+}
+
+// Suite — состояние прогона набора на одном хранилище.
+type Suite struct {
+	Storage *storage.Storage
+	suffix  string
+	seq     int
+}
+
+// Run прогоняет все сценарии на хранилище s, каждый — отдельным подтестом.
+func Run(t *testing.T, s *storage.Storage) {
+	c := &Suite{Storage: s, suffix: strconv.FormatInt(time.Now().UnixNano(), 36)}
+	for _, tc := range Cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t, c)
+		})
+	}
+}
+
+// name возвращает уникальное в пределах прогона имя.
+func (c *Suite) name(base string) string {
+	return base + "-" + c.suffix
+}
+
+// newUser создаёт пользователя с уникальным логином и открытым ключом "pk-<логин>".
+func (c *Suite) newUser(t *testing.T, base string) (int, string) {
+	t.Helper()
+	c.seq++
+	login := c.name(fmt.Sprintf("%s%d", base, c.seq))
+	id, err := c.Storage.Users.CreateUser(t.Context(), model.UserCredentials{
+		Username:            login,
+		Password:            "hash-" + login,
+		EncryptedKey:        "key-" + login,
+		PublicKey:           "pk-" + login,
+		EncryptedPrivateKey: "sk-" + login,
+	})
+	if err != nil {
+		t.Fatalf("CreateUser(%s): %v", login, err)
+	}
+	return id, login
+}
+
+// newRecord создаёт запись с уникальными метаданными и возвращает её так,
+// как её видит владелец.
+func (c *Suite) newRecord(t *testing.T, userID int, collectionID int, recordType model.RecordType, data string) model.Record {
+	t.Helper()
+	ctx := t.Context()
+	c.seq++
+	metadata := c.name(fmt.Sprintf("record%d", c.seq))
+	err := c.Storage.Records.CreateRecord(ctx, model.Record{
+		UserID:       userID,
+		CollectionID: collectionID,
+		Type:         recordType,
+		Metadata:     metadata,
+		Data:         []byte(data),
+	})
+	if err != nil {
+		t.Fatalf("CreateRecord: %v", err)
+	}
+
+	var records []model.Record
+	if collectionID == 0 {
+		records, err = c.Storage.Records.GetAllRecords(ctx, userID)
+	} else {
+		key, keyErr := c.Storage.Orgs.GetCollectionKey(ctx, collectionID, userID)
+		if keyErr != nil {
+			t.Fatalf("GetCollectionKey: %v", keyErr)
+		}
+		records, err = c.Storage.Records.GetOrgRecords(ctx, userID, key.OrgID)
+	}
+	if err != nil {
+		t.Fatalf("list records: %v", err)
+	}
+	for _, r := range records {
+		if r.Metadata == metadata {
+			return r
+		}
+	}
+	t.Fatalf("created record %q is not listed", metadata)
+	return model.Record{}
+}
+
+// expect останавливает сценарий с описанием, если условие не выполнено.
+func expect(t *testing.T, ok bool, format string, args ...any) {
+	t.Helper()
+	if !ok {
+		t.Fatalf(format, args...)
+	}
+}
+
+// expectErr проверяет, что операция op завершилась ошибкой target.
+func expectErr(t *testing.T, op string, err error, target error) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Fatalf("%s: got error %v, want %v", op, err, target)
+	}
+}
+
+// findRecord ищет запись по ID в списке.
+func findRecord(records []model.Record, id int64) (model.Record, bool) {
+	for _, r := range records {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return model.Record{}, false
+}
+
+// recordID возвращает идентификатор записи в виде параметра пути запроса.
+func recordID(r model.Record) string {
+	return strconv.FormatInt(r.ID, 10)
+}
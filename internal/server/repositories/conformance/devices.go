@@ -0,0 +1,79 @@
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+func testDevices(t *testing.T, c *Suite) {
+	ctx := t.Context()
+	devices := c.Storage.Devices
+	userID, _ := c.newUser(t, "owner")
+	otherID, _ := c.newUser(t, "other")
+
+	require, err := devices.GetRequireDevice(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetRequireDevice: %v", err)
+	}
+	expect(t, !require, "require_device is enabled for a new user")
+	if err := devices.SetRequireDevice(ctx, userID, true); err != nil {
+		t.Fatalf("SetRequireDevice: %v", err)
+	}
+	require, err = devices.GetRequireDevice(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetRequireDevice: %v", err)
+	}
+	expect(t, require, "SetRequireDevice(true) is not persisted")
+
+	device := model.Device{
+		UserID:     userID,
+		Name:       "laptop",
+		Serial:     c.name("serial"),
+		Thumbprint: c.name("thumbprint"),
+		ExpiresAt:  time.Now().UTC().Add(365 * 24 * time.Hour).Truncate(time.Second),
+	}
+	id, err := devices.CreateDevice(ctx, device)
+	if err != nil {
+		t.Fatalf("CreateDevice: %v", err)
+	}
+	if _, err := devices.CreateDevice(ctx, model.Device{UserID: userID, Name: "copy", Serial: device.Serial, Thumbprint: c.name("other"), ExpiresAt: device.ExpiresAt}); err == nil {
+		t.Fatal("CreateDevice with existing serial succeeded")
+	}
+
+	list, err := devices.ListDevices(ctx, userID)
+	if err != nil {
+		t.Fatalf("ListDevices: %v", err)
+	}
+	expect(t, len(list) == 1 && list[0].ID == id && list[0].Name == "laptop" && list[0].Serial == device.Serial &&
+		list[0].ExpiresAt.Equal(device.ExpiresAt) && list[0].RevokedAt == nil,
+		"ListDevices = %+v", list)
+	list, err = devices.ListDevices(ctx, otherID)
+	if err != nil {
+		t.Fatalf("ListDevices: %v", err)
+	}
+	expect(t, len(list) == 0, "ListDevices(other) = %+v", list)
+
+	found, err := devices.GetDeviceByThumbprint(ctx, device.Thumbprint)
+	if err != nil {
+		t.Fatalf("GetDeviceByThumbprint: %v", err)
+	}
+	expect(t, found.ID == id && found.UserID == userID, "GetDeviceByThumbprint = %+v", found)
+	_, err = devices.GetDeviceByThumbprint(ctx, c.name("unknown"))
+	expectErr(t, "GetDeviceByThumbprint(unknown)", err, model.ErrDeviceNotFound)
+
+	err = devices.RevokeDevice(ctx, otherID, id)
+	expectErr(t, "RevokeDevice by other user", err, model.ErrDeviceNotFound)
+	if err := devices.RevokeDevice(ctx, userID, id); err != nil {
+		t.Fatalf("RevokeDevice: %v", err)
+	}
+	if err := devices.RevokeDevice(ctx, userID, id); err != nil {
+		t.Fatalf("repeated RevokeDevice: %v", err)
+	}
+	found, err = devices.GetDeviceByThumbprint(ctx, device.Thumbprint)
+	if err != nil {
+		t.Fatalf("GetDeviceByThumbprint: %v", err)
+	}
+	expect(t, found.RevokedAt != nil, "revoked device has no revocation time")
+}
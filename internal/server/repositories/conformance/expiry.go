@@ -0,0 +1,80 @@
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+func testExpiry(t *testing.T, c *Suite) {
+	ctx := t.Context()
+	records := c.Storage.Records
+	ownerID, _ := c.newUser(t, "owner")
+	strangerID, _ := c.newUser(t, "stranger")
+
+	// Хранилища держат время с точностью до микросекунд.
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	past, future := now.Add(-time.Hour), now.Add(30*24*time.Hour)
+
+	expired := c.newRecord(t, ownerID, 0, model.TypeText, "ephemeral-expired")
+	pending := c.newRecord(t, ownerID, 0, model.TypeText, "ephemeral-pending")
+	kept := c.newRecord(t, ownerID, 0, model.TypeText, "expired-not-ephemeral")
+	expect(t, expired.ExpiresAt == nil && !expired.Ephemeral,
+		"new record has expires_at %v, ephemeral %v", expired.ExpiresAt, expired.Ephemeral)
+
+	if err := records.UpdateRecord(ctx, strangerID, recordID(expired), model.Record{}, &model.RecordExpiry{ExpiresAt: &past, Ephemeral: true}); err != nil {
+		t.Fatalf("UpdateRecord expiry by stranger: %v", err)
+	}
+	got, err := records.GetRecord(ctx, ownerID, recordID(expired))
+	if err != nil {
+		t.Fatalf("GetRecord: %v", err)
+	}
+	expect(t, got.ExpiresAt == nil, "stranger set expires_at to %v", got.ExpiresAt)
+
+	for _, set := range []struct {
+		record    model.Record
+		expiresAt time.Time
+		ephemeral bool
+	}{{expired, past, true}, {pending, future, true}, {kept, past, false}} {
+		if err := records.UpdateRecord(ctx, ownerID, recordID(set.record), model.Record{}, &model.RecordExpiry{ExpiresAt: &set.expiresAt, Ephemeral: set.ephemeral}); err != nil {
+			t.Fatalf("UpdateRecord expiry: %v", err)
+		}
+	}
+	got, err = records.GetRecord(ctx, ownerID, recordID(pending))
+	if err != nil {
+		t.Fatalf("GetRecord: %v", err)
+	}
+	expect(t, got.ExpiresAt != nil && got.ExpiresAt.Equal(future) && got.Ephemeral,
+		"expiry round trip: got expires_at %v, ephemeral %v, want %v, true", got.ExpiresAt, got.Ephemeral, future)
+	expect(t, got.UpdatedAt.Equal(pending.UpdatedAt), "expiry-only update changed updated_at from %v to %v", pending.UpdatedAt, got.UpdatedAt)
+
+	deleted, err := records.DeleteExpiredRecords(ctx, now)
+	if err != nil {
+		t.Fatalf("DeleteExpiredRecords: %v", err)
+	}
+	gone, ok := findRecord(deleted, expired.ID)
+	expect(t, ok && gone.UserID == ownerID, "expired ephemeral record %d is not reported as deleted with its owner", expired.ID)
+	for _, r := range deleted {
+		if r.ID == pending.ID || r.ID == kept.ID {
+			t.Fatalf("DeleteExpiredRecords deleted record %d that is not an expired ephemeral record", r.ID)
+		}
+	}
+	_, err = records.GetRecord(ctx, ownerID, recordID(expired))
+	expectErr(t, "GetRecord of expired ephemeral record", err, model.ErrRecordNotFound)
+	for _, r := range []model.Record{pending, kept} {
+		if _, err := records.GetRecord(ctx, ownerID, recordID(r)); err != nil {
+			t.Fatalf("GetRecord of record %d kept by DeleteExpiredRecords: %v", r.ID, err)
+		}
+	}
+
+	if err := records.UpdateRecord(ctx, ownerID, recordID(kept), model.Record{Metadata: "renamed"}, &model.RecordExpiry{}); err != nil {
+		t.Fatalf("UpdateRecord with expiry clear: %v", err)
+	}
+	got, err = records.GetRecord(ctx, ownerID, recordID(kept))
+	if err != nil {
+		t.Fatalf("GetRecord: %v", err)
+	}
+	expect(t, got.ExpiresAt == nil && !got.Ephemeral && got.Metadata == "renamed",
+		"update with cleared expiry: got metadata %q, expires_at %v, ephemeral %v", got.Metadata, got.ExpiresAt, got.Ephemeral)
+}
@@ -0,0 +1,152 @@
+package conformance
+
+import (
+	"testing"
+
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+func testOrganizations(t *testing.T, c *Suite) {
+	ctx := t.Context()
+	orgs := c.Storage.Orgs
+	records := c.Storage.Records
+	ownerID, ownerLogin := c.newUser(t, "owner")
+	memberID, memberLogin := c.newUser(t, "member")
+	viewerID, viewerLogin := c.newUser(t, "viewer")
+	outsiderID, _ := c.newUser(t, "outsider")
+
+	name := c.name("org")
+	org, err := orgs.CreateOrg(ctx, name, "default", model.CollectionKey{UserID: ownerID, WrappedKey: "default-owner"})
+	if err != nil {
+		t.Fatalf("CreateOrg: %v", err)
+	}
+	expect(t, org.ID > 0 && org.Name == name && org.Role == model.RoleOwner, "CreateOrg = %+v", org)
+	_, err = orgs.CreateOrg(ctx, name, "default", model.CollectionKey{UserID: memberID, WrappedKey: "x"})
+	expectErr(t, "CreateOrg with existing name", err, model.ErrOrgExists)
+
+	list, err := orgs.GetOrgs(ctx, ownerID)
+	if err != nil {
+		t.Fatalf("GetOrgs: %v", err)
+	}
+	expect(t, len(list) == 1 && list[0].ID == org.ID && list[0].Role == model.RoleOwner, "GetOrgs(owner) = %+v", list)
+	list, err = orgs.GetOrgs(ctx, outsiderID)
+	if err != nil {
+		t.Fatalf("GetOrgs: %v", err)
+	}
+	expect(t, len(list) == 0, "GetOrgs(outsider) = %+v", list)
+
+	role, err := orgs.GetMemberRole(ctx, org.ID, ownerID)
+	if err != nil {
+		t.Fatalf("GetMemberRole: %v", err)
+	}
+	expect(t, role == model.RoleOwner, "GetMemberRole(owner) = %q", role)
+	_, err = orgs.GetMemberRole(ctx, org.ID, outsiderID)
+	expectErr(t, "GetMemberRole(outsider)", err, model.ErrNotOrgMember)
+
+	collections, err := orgs.ListCollections(ctx, org.ID, ownerID)
+	if err != nil {
+		t.Fatalf("ListCollections: %v", err)
+	}
+	expect(t, len(collections) == 1 && collections[0].Name == "default" && collections[0].WrappedKey == "default-owner",
+		"ListCollections after CreateOrg = %+v", collections)
+	defaultID := collections[0].ID
+
+	err = orgs.AddMember(ctx, org.ID, memberID, model.RoleMember, []model.CollectionKey{{CollectionID: defaultID, WrappedKey: "default-member"}})
+	if err != nil {
+		t.Fatalf("AddMember: %v", err)
+	}
+	err = orgs.AddMember(ctx, org.ID, memberID, model.RoleAdmin, nil)
+	expectErr(t, "AddMember twice", err, model.ErrMemberExists)
+	err = orgs.AddMember(ctx, org.ID, viewerID, model.RoleReadOnly, []model.CollectionKey{{CollectionID: defaultID, WrappedKey: "default-viewer"}})
+	if err != nil {
+		t.Fatalf("AddMember: %v", err)
+	}
+
+	members, err := orgs.ListMembers(ctx, org.ID)
+	if err != nil {
+		t.Fatalf("ListMembers: %v", err)
+	}
+	expect(t, len(members) == 3 &&
+		members[0].Username == ownerLogin && members[1].Username == memberLogin && members[2].Username == viewerLogin &&
+		members[1].Role == model.RoleMember && members[2].Role == model.RoleReadOnly && members[2].PublicKey == "pk-"+viewerLogin,
+		"ListMembers must list members in order of joining, got %+v", members)
+
+	ops, err := orgs.CreateCollection(ctx, org.ID, "ops", []model.CollectionKey{{UserID: ownerID, WrappedKey: "ops-owner"}})
+	if err != nil {
+		t.Fatalf("CreateCollection: %v", err)
+	}
+	_, err = orgs.CreateCollection(ctx, org.ID, "ops", nil)
+	expectErr(t, "CreateCollection with existing name", err, model.ErrCollectionExists)
+	collections, err = orgs.ListCollections(ctx, org.ID, memberID)
+	if err != nil {
+		t.Fatalf("ListCollections: %v", err)
+	}
+	expect(t, len(collections) == 2 && collections[0].ID == defaultID && collections[0].WrappedKey == "default-member" &&
+		collections[1].ID == ops.ID && collections[1].WrappedKey == "",
+		"ListCollections(member) = %+v", collections)
+
+	key, err := orgs.GetCollectionKey(ctx, defaultID, memberID)
+	if err != nil {
+		t.Fatalf("GetCollectionKey: %v", err)
+	}
+	expect(t, key.OrgID == org.ID && key.Role == model.RoleMember && key.WrappedKey == "default-member",
+		"GetCollectionKey(member) = %+v", key)
+	_, err = orgs.GetCollectionKey(ctx, ops.ID, memberID)
+	expectErr(t, "GetCollectionKey without key", err, model.ErrNotOrgMember)
+	_, err = orgs.GetCollectionKey(ctx, defaultID, outsiderID)
+	expectErr(t, "GetCollectionKey(outsider)", err, model.ErrNotOrgMember)
+
+	record := c.newRecord(t, ownerID, defaultID, model.TypeText, "collection-ciphertext")
+	expect(t, record.CollectionID == defaultID && record.OrgID == org.ID && record.DataKey == "default-owner",
+		"collection record = %+v", record)
+
+	memberView := orgRecord(t, c, memberID, org.ID, record.ID)
+	expect(t, memberView.Permission == model.PermissionWrite && memberView.DataKey == "default-member",
+		"member sees permission %q, data key %q", memberView.Permission, memberView.DataKey)
+	viewerView := orgRecord(t, c, viewerID, org.ID, record.ID)
+	expect(t, viewerView.Permission == model.PermissionRead && viewerView.DataKey == "default-viewer",
+		"read-only member sees permission %q, data key %q", viewerView.Permission, viewerView.DataKey)
+
+	personal, err := records.GetAllRecords(ctx, memberID)
+	if err != nil {
+		t.Fatalf("GetAllRecords: %v", err)
+	}
+	if _, ok := findRecord(personal, record.ID); ok {
+		t.Fatal("collection record is listed among personal records")
+	}
+	_, err = records.GetRecord(ctx, outsiderID, recordID(record))
+	expectErr(t, "GetRecord(outsider)", err, model.ErrRecordNotFound)
+
+	if err := records.UpdateRecord(ctx, viewerID, recordID(record), model.Record{Metadata: "by-viewer"}, nil); err != nil {
+		t.Fatalf("UpdateRecord by read-only member: %v", err)
+	}
+	if err := records.UpdateRecord(ctx, memberID, recordID(record), model.Record{Metadata: "by-member"}, nil); err != nil {
+		t.Fatalf("UpdateRecord by member: %v", err)
+	}
+	got, err := records.GetRecord(ctx, ownerID, recordID(record))
+	if err != nil {
+		t.Fatalf("GetRecord: %v", err)
+	}
+	expect(t, got.Metadata == "by-member", "collection record metadata = %q, want by-member", got.Metadata)
+
+	if err := records.DeleteRecord(ctx, viewerID, recordID(record)); err == nil {
+		t.Fatal("DeleteRecord by read-only member succeeded")
+	}
+	if err := records.DeleteRecord(ctx, memberID, recordID(record)); err != nil {
+		t.Fatalf("DeleteRecord by member: %v", err)
+	}
+}
+
+// orgRecord ищет запись среди записей организации, доступных пользователю.
+func orgRecord(t *testing.T, c *Suite, userID int, orgID int, id int64) model.Record {
+	t.Helper()
+	list, err := c.Storage.Records.GetOrgRecords(t.Context(), userID, orgID)
+	if err != nil {
+		t.Fatalf("GetOrgRecords: %v", err)
+	}
+	record, ok := findRecord(list, id)
+	if !ok {
+		t.Fatalf("collection record %d is not listed for user %d", id, userID)
+	}
+	return record
+}
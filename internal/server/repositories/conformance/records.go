@@ -0,0 +1,203 @@
+package conformance
+
+import (
+	"testing"
+
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+func testRecords(t *testing.T, c *Suite) {
+	ctx := t.Context()
+	records := c.Storage.Records
+	ownerID, _ := c.newUser(t, "owner")
+	strangerID, _ := c.newUser(t, "stranger")
+
+	countsBefore, err := records.CountRecordsByType(ctx)
+	if err != nil {
+		t.Fatalf("CountRecordsByType: %v", err)
+	}
+
+	first := c.newRecord(t, ownerID, 0, model.TypeText, "ciphertext-1")
+	second := c.newRecord(t, ownerID, 0, model.TypeText, "ciphertext-2")
+	expect(t, first.UserID == ownerID && first.Type == model.TypeText && string(first.Data) == "ciphertext-1",
+		"created record = %+v", first)
+	expect(t, first.Permission == model.PermissionOwner && first.DataKey == "" && first.CollectionID == 0,
+		"owner sees permission %q, data key %q, collection %d", first.Permission, first.DataKey, first.CollectionID)
+
+	all, err := records.GetAllRecords(ctx, ownerID)
+	if err != nil {
+		t.Fatalf("GetAllRecords: %v", err)
+	}
+	expect(t, len(all) == 2 && all[0].ID == second.ID && all[1].ID == first.ID,
+		"GetAllRecords must list the owner's two records newest first, got %d records", len(all))
+
+	countsAfter, err := records.CountRecordsByType(ctx)
+	if err != nil {
+		t.Fatalf("CountRecordsByType: %v", err)
+	}
+	expect(t, countsAfter[model.TypeText]-countsBefore[model.TypeText] == 2,
+		"CountRecordsByType text grew by %d, want 2", countsAfter[model.TypeText]-countsBefore[model.TypeText])
+
+	_, err = records.GetRecord(ctx, strangerID, recordID(first))
+	expectErr(t, "GetRecord by stranger", err, model.ErrRecordNotFound)
+	others, err := records.GetAllRecords(ctx, strangerID)
+	if err != nil {
+		t.Fatalf("GetAllRecords: %v", err)
+	}
+	if _, ok := findRecord(others, first.ID); ok {
+		t.Fatal("stranger lists the owner's record")
+	}
+
+	if err := records.UpdateRecord(ctx, ownerID, recordID(first), model.Record{Metadata: "renamed"}, nil); err != nil {
+		t.Fatalf("UpdateRecord: %v", err)
+	}
+	got, err := records.GetRecord(ctx, ownerID, recordID(first))
+	if err != nil {
+		t.Fatalf("GetRecord: %v", err)
+	}
+	expect(t, got.Metadata == "renamed" && string(got.Data) == "ciphertext-1",
+		"metadata-only update: got metadata %q, data %q", got.Metadata, got.Data)
+	if err := records.UpdateRecord(ctx, ownerID, recordID(first), model.Record{Data: []byte("ciphertext-3")}, nil); err != nil {
+		t.Fatalf("UpdateRecord: %v", err)
+	}
+	got, err = records.GetRecord(ctx, ownerID, recordID(first))
+	if err != nil {
+		t.Fatalf("GetRecord: %v", err)
+	}
+	expect(t, got.Metadata == "renamed" && string(got.Data) == "ciphertext-3",
+		"data-only update: got metadata %q, data %q", got.Metadata, got.Data)
+	expect(t, !first.UpdatedAt.IsZero() && !got.UpdatedAt.Before(first.UpdatedAt),
+		"updated_at must be set and not go back on update: created %v, updated %v", first.UpdatedAt, got.UpdatedAt)
+
+	if err := records.UpdateRecord(ctx, strangerID, recordID(first), model.Record{Metadata: "hijacked"}, nil); err != nil {
+		t.Fatalf("UpdateRecord by stranger: %v", err)
+	}
+	err = records.DeleteRecord(ctx, strangerID, recordID(first))
+	expectErr(t, "DeleteRecord by stranger", err, model.ErrRecordNotFound)
+	got, err = records.GetRecord(ctx, ownerID, recordID(first))
+	if err != nil {
+		t.Fatalf("GetRecord after stranger's changes: %v", err)
+	}
+	expect(t, got.Metadata == "renamed", "stranger changed metadata to %q", got.Metadata)
+
+	if err := records.DeleteRecord(ctx, ownerID, recordID(first)); err != nil {
+		t.Fatalf("DeleteRecord: %v", err)
+	}
+	_, err = records.GetRecord(ctx, ownerID, recordID(first))
+	expectErr(t, "GetRecord after DeleteRecord", err, model.ErrRecordNotFound)
+	err = records.DeleteRecord(ctx, ownerID, recordID(first))
+	expectErr(t, "repeated DeleteRecord", err, model.ErrRecordNotFound)
+}
+
+func testSharing(t *testing.T, c *Suite) {
+	ctx := t.Context()
+	records := c.Storage.Records
+	ownerID, _ := c.newUser(t, "owner")
+	recipientID, recipientLogin := c.newUser(t, "recipient")
+
+	record := c.newRecord(t, ownerID, 0, model.TypeLoginPassword, "ciphertext")
+
+	err := records.PutRecordKey(ctx, model.RecordKey{RecordID: record.ID, UserID: recipientID, Permission: model.PermissionRead, WrappedKey: "wrapped-read"})
+	if err != nil {
+		t.Fatalf("PutRecordKey: %v", err)
+	}
+	shared, err := records.GetRecord(ctx, recipientID, recordID(record))
+	if err != nil {
+		t.Fatalf("GetRecord by recipient: %v", err)
+	}
+	expect(t, shared.Permission == model.PermissionRead && shared.DataKey == "wrapped-read" && shared.UserID == ownerID,
+		"recipient sees permission %q, data key %q, owner %d", shared.Permission, shared.DataKey, shared.UserID)
+	list, err := records.GetAllRecords(ctx, recipientID)
+	if err != nil {
+		t.Fatalf("GetAllRecords: %v", err)
+	}
+	if _, ok := findRecord(list, record.ID); !ok {
+		t.Fatal("shared record is not listed for recipient")
+	}
+
+	if err := records.UpdateRecord(ctx, recipientID, recordID(record), model.Record{Metadata: "by-reader"}, nil); err != nil {
+		t.Fatalf("UpdateRecord by reader: %v", err)
+	}
+	got, err := records.GetRecord(ctx, ownerID, recordID(record))
+	if err != nil {
+		t.Fatalf("GetRecord: %v", err)
+	}
+	expect(t, got.Metadata == record.Metadata, "reader changed metadata to %q", got.Metadata)
+
+	err = records.PutRecordKey(ctx, model.RecordKey{RecordID: record.ID, UserID: recipientID, Permission: model.PermissionWrite, WrappedKey: "wrapped-write"})
+	if err != nil {
+		t.Fatalf("PutRecordKey upgrade: %v", err)
+	}
+	if err := records.UpdateRecord(ctx, recipientID, recordID(record), model.Record{Metadata: "by-writer"}, nil); err != nil {
+		t.Fatalf("UpdateRecord by writer: %v", err)
+	}
+	got, err = records.GetRecord(ctx, ownerID, recordID(record))
+	if err != nil {
+		t.Fatalf("GetRecord: %v", err)
+	}
+	expect(t, got.Metadata == "by-writer", "writer update lost, metadata %q", got.Metadata)
+	if err := records.DeleteRecord(ctx, recipientID, recordID(record)); err == nil {
+		t.Fatal("DeleteRecord by writer succeeded, only the owner may delete")
+	}
+
+	keys, err := records.ListRecordKeys(ctx, record.ID)
+	if err != nil {
+		t.Fatalf("ListRecordKeys: %v", err)
+	}
+	expect(t, len(keys) == 1, "ListRecordKeys returned %d keys, want 1", len(keys))
+	expect(t, keys[0].UserID == recipientID && keys[0].Login == recipientLogin && keys[0].PublicKey == "pk-"+recipientLogin &&
+		keys[0].Permission == model.PermissionWrite && keys[0].WrappedKey == "wrapped-write",
+		"ListRecordKeys = %+v", keys[0])
+
+	before, err := records.GetRecord(ctx, ownerID, recordID(record))
+	if err != nil {
+		t.Fatalf("GetRecord: %v", err)
+	}
+	err = records.ReplaceRecordKeys(ctx, record.ID, []byte("reencrypted"), []model.RecordKey{
+		{UserID: ownerID, Permission: model.PermissionOwner, WrappedKey: "rotated-owner"},
+		{UserID: recipientID, Permission: model.PermissionRead, WrappedKey: "rotated-read"},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceRecordKeys: %v", err)
+	}
+	got, err = records.GetRecord(ctx, ownerID, recordID(record))
+	if err != nil {
+		t.Fatalf("GetRecord: %v", err)
+	}
+	expect(t, string(got.Data) == "reencrypted" && got.DataKey == "rotated-owner" && got.Permission == model.PermissionOwner,
+		"after ReplaceRecordKeys owner sees data %q, key %q, permission %q", got.Data, got.DataKey, got.Permission)
+	expect(t, got.UpdatedAt.Equal(before.UpdatedAt),
+		"ReplaceRecordKeys changed updated_at from %v to %v", before.UpdatedAt, got.UpdatedAt)
+	shared, err = records.GetRecord(ctx, recipientID, recordID(record))
+	if err != nil {
+		t.Fatalf("GetRecord by recipient: %v", err)
+	}
+	expect(t, shared.DataKey == "rotated-read" && shared.Permission == model.PermissionRead,
+		"after ReplaceRecordKeys recipient sees key %q, permission %q", shared.DataKey, shared.Permission)
+	keys, err = records.ListRecordKeys(ctx, record.ID)
+	if err != nil {
+		t.Fatalf("ListRecordKeys: %v", err)
+	}
+	expect(t, len(keys) == 2, "ListRecordKeys after ReplaceRecordKeys returned %d keys, want 2", len(keys))
+
+	err = records.ReplaceRecordKeys(ctx, 1<<40, []byte("x"), nil)
+	expectErr(t, "ReplaceRecordKeys(unknown)", err, model.ErrRecordNotFound)
+
+	err = records.ReplaceRecordKeys(ctx, record.ID, []byte("revoked"), []model.RecordKey{
+		{UserID: ownerID, Permission: model.PermissionOwner, WrappedKey: "owner-only"},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceRecordKeys: %v", err)
+	}
+	_, err = records.GetRecord(ctx, recipientID, recordID(record))
+	expectErr(t, "GetRecord after revocation", err, model.ErrRecordNotFound)
+
+	if err := records.DeleteRecord(ctx, ownerID, recordID(record)); err != nil {
+		t.Fatalf("DeleteRecord: %v", err)
+	}
+	keys, err = records.ListRecordKeys(ctx, record.ID)
+	if err != nil {
+		t.Fatalf("ListRecordKeys: %v", err)
+	}
+	expect(t, len(keys) == 0, "keys of deleted record remain: %d", len(keys))
+}
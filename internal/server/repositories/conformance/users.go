@@ -0,0 +1,113 @@
+package conformance
+
+import (
+	"testing"
+
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+func testUsers(t *testing.T, c *Suite) {
+	ctx := t.Context()
+	users := c.Storage.Users
+	login := c.name("newcomer")
+	creds := model.UserCredentials{
+		Username:            login,
+		Password:            "hash",
+		EncryptedKey:        "encrypted-key",
+		PublicKey:           "public-key",
+		EncryptedPrivateKey: "encrypted-private-key",
+	}
+
+	exists, err := users.ExistUser(ctx, creds)
+	if err != nil {
+		t.Fatalf("ExistUser: %v", err)
+	}
+	expect(t, !exists, "ExistUser before CreateUser = true")
+
+	id, err := users.CreateUser(ctx, creds)
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	expect(t, id > 0, "CreateUser returned id %d", id)
+	if _, err := users.CreateUser(ctx, creds); err == nil {
+		t.Fatal("CreateUser with existing login succeeded")
+	}
+
+	exists, err = users.ExistUser(ctx, creds)
+	if err != nil {
+		t.Fatalf("ExistUser: %v", err)
+	}
+	expect(t, exists, "ExistUser after CreateUser = false")
+
+	user, err := users.GetUser(ctx, model.UserCredentials{Username: login})
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	expect(t, user.ID == id && user.Login == login && user.PasswordHash == "hash",
+		"GetUser = %+v, want id %d login %s", user, id, login)
+	_, err = users.GetUser(ctx, model.UserCredentials{Username: c.name("nobody")})
+	expectErr(t, "GetUser(unknown)", err, model.ErrUserNotFound)
+
+	encryptedKey, err := users.GetEncryptedKeyUser(ctx, id)
+	if err != nil {
+		t.Fatalf("GetEncryptedKeyUser: %v", err)
+	}
+	expect(t, encryptedKey == "encrypted-key", "GetEncryptedKeyUser = %q", encryptedKey)
+	_, err = users.GetEncryptedKeyUser(ctx, -1)
+	expectErr(t, "GetEncryptedKeyUser(unknown)", err, model.ErrUserNotFound)
+
+	byLogin, err := users.GetUserByLogin(ctx, login)
+	if err != nil {
+		t.Fatalf("GetUserByLogin: %v", err)
+	}
+	expect(t, byLogin.ID == id && byLogin.PublicKey == "public-key", "GetUserByLogin = %+v", byLogin)
+	_, err = users.GetUserByLogin(ctx, c.name("nobody"))
+	expectErr(t, "GetUserByLogin(unknown)", err, model.ErrUserNotFound)
+
+	publicKey, privateKey, err := users.GetKeyPair(ctx, id)
+	if err != nil {
+		t.Fatalf("GetKeyPair: %v", err)
+	}
+	expect(t, publicKey == "public-key" && privateKey == "encrypted-private-key",
+		"GetKeyPair = %q, %q", publicKey, privateKey)
+	_, _, err = users.GetKeyPair(ctx, -1)
+	expectErr(t, "GetKeyPair(unknown)", err, model.ErrUserNotFound)
+}
+
+// testKeyPair проверяет, что пара ключей сохраняется только один раз: при
+// гонке первых обращений вторая пара не заменяет первую, которой уже могли
+// быть зашифрованы ключи записей.
+func testKeyPair(t *testing.T, c *Suite) {
+	ctx := t.Context()
+	users := c.Storage.Users
+	login := c.name("keyless")
+	id, err := users.CreateUser(ctx, model.UserCredentials{Username: login, Password: "hash", EncryptedKey: "key"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	publicKey, privateKey, err := users.GetKeyPair(ctx, id)
+	if err != nil {
+		t.Fatalf("GetKeyPair: %v", err)
+	}
+	expect(t, publicKey == "" && privateKey == "", "GetKeyPair without keys = %q, %q", publicKey, privateKey)
+
+	stored, err := users.SetKeyPair(ctx, id, "public-key-1", "encrypted-private-key-1")
+	if err != nil {
+		t.Fatalf("SetKeyPair: %v", err)
+	}
+	expect(t, stored, "first SetKeyPair was not stored")
+
+	stored, err = users.SetKeyPair(ctx, id, "public-key-2", "encrypted-private-key-2")
+	if err != nil {
+		t.Fatalf("SetKeyPair: %v", err)
+	}
+	expect(t, !stored, "second SetKeyPair replaced the stored pair")
+
+	publicKey, privateKey, err = users.GetKeyPair(ctx, id)
+	if err != nil {
+		t.Fatalf("GetKeyPair: %v", err)
+	}
+	expect(t, publicKey == "public-key-1" && privateKey == "encrypted-private-key-1",
+		"GetKeyPair after second SetKeyPair = %q, %q", publicKey, privateKey)
+}
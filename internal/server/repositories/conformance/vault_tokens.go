@@ -0,0 +1,59 @@
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+func testVaultTokens(t *testing.T, c *Suite) {
+	ctx := t.Context()
+	tokens := c.Storage.VaultTokens
+	userID, login := c.newUser(t, "owner")
+	otherID, _ := c.newUser(t, "other")
+
+	token := model.VaultToken{
+		UserID:    userID,
+		Name:      "ci",
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	hash := c.name("hash")
+	id, err := tokens.CreateVaultToken(ctx, token, hash)
+	if err != nil {
+		t.Fatalf("CreateVaultToken: %v", err)
+	}
+	if _, err := tokens.CreateVaultToken(ctx, model.VaultToken{UserID: userID, Name: "copy", CreatedAt: token.CreatedAt}, hash); err == nil {
+		t.Fatal("CreateVaultToken with existing hash succeeded")
+	}
+
+	list, err := tokens.ListVaultTokens(ctx, userID)
+	if err != nil {
+		t.Fatalf("ListVaultTokens: %v", err)
+	}
+	expect(t, len(list) == 1 && list[0].ID == id && list[0].Name == "ci" && list[0].CreatedAt.Equal(token.CreatedAt),
+		"ListVaultTokens = %+v", list)
+	list, err = tokens.ListVaultTokens(ctx, otherID)
+	if err != nil {
+		t.Fatalf("ListVaultTokens: %v", err)
+	}
+	expect(t, len(list) == 0, "ListVaultTokens(other) = %+v", list)
+
+	found, err := tokens.GetVaultTokenByHash(ctx, hash)
+	if err != nil {
+		t.Fatalf("GetVaultTokenByHash: %v", err)
+	}
+	expect(t, found.ID == id && found.UserID == userID && found.UserLogin == login, "GetVaultTokenByHash = %+v", found)
+	_, err = tokens.GetVaultTokenByHash(ctx, c.name("unknown"))
+	expectErr(t, "GetVaultTokenByHash(unknown)", err, model.ErrVaultTokenNotFound)
+
+	err = tokens.DeleteVaultToken(ctx, otherID, id)
+	expectErr(t, "DeleteVaultToken by other user", err, model.ErrVaultTokenNotFound)
+	if err := tokens.DeleteVaultToken(ctx, userID, id); err != nil {
+		t.Fatalf("DeleteVaultToken: %v", err)
+	}
+	_, err = tokens.GetVaultTokenByHash(ctx, hash)
+	expectErr(t, "GetVaultTokenByHash after delete", err, model.ErrVaultTokenNotFound)
+	err = tokens.DeleteVaultToken(ctx, userID, id)
+	expectErr(t, "repeated DeleteVaultToken", err, model.ErrVaultTokenNotFound)
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// AuditRepo хранит журнал аудита. Журнал доступен только на добавление:
+// репозиторий не предоставляет операций изменения и удаления событий.
+type AuditRepo struct {
+	store *Store
+}
+
+func NewAuditRepo(store *Store) *AuditRepo {
+	return &AuditRepo{store: store}
+}
+
+// AppendEvent добавляет событие в конец цепочки. Hash предыдущего события
+// читается под блокировкой хранилища, поэтому параллельные вставки не ветвят цепочку.
+func (s *AuditRepo) AppendEvent(ctx context.Context, event model.AuditEvent) error {
+	_, span := tracing.Start(ctx, "memory.AuditRepo.AppendEvent")
+	defer span.End()
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	var prevHash string
+	if n := len(s.store.audit); n > 0 {
+		prevHash = s.store.audit[n-1].Hash
+	}
+
+	event.ID = s.store.nextID("audit_events")
+	event.PrevHash = prevHash
+	event.Hash = event.ChainHash(prevHash)
+	s.store.audit = append(s.store.audit, event)
+	return nil
+}
+
+// ListEvents возвращает события пользователя и события с его записями,
+// отфильтрованные по условиям filter, от новых к старым.
+func (s *AuditRepo) ListEvents(ctx context.Context, userID int, filter model.AuditFilter) ([]model.AuditEvent, error) {
+	_, span := tracing.Start(ctx, "memory.AuditRepo.ListEvents")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	events := make([]model.AuditEvent, 0)
+	for i := len(s.store.audit) - 1; i >= 0 && len(events) < filter.Limit; i-- {
+		e := s.store.audit[i]
+		if e.UserID != userID && !s.ownsRecord(userID, e.RecordID) {
+			continue
+		}
+		if filter.Action != "" && e.Action != filter.Action ||
+			filter.Result != "" && e.Result != filter.Result ||
+			filter.RecordID != 0 && e.RecordID != filter.RecordID ||
+			!filter.Since.IsZero() && e.CreatedAt.Before(filter.Since) ||
+			!filter.Until.IsZero() && !e.CreatedAt.Before(filter.Until) {
+			continue
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// ownsRecord сообщает, принадлежит ли существующая запись пользователю.
+func (s *AuditRepo) ownsRecord(userID int, recordID int64) bool {
+	r, ok := s.store.records[recordID]
+	return ok && r.userID == userID
+}
+
+// GetEventsAfter возвращает не больше limit событий цепочки с
+// идентификатором больше afterID в порядке добавления.
+func (s *AuditRepo) GetEventsAfter(ctx context.Context, afterID int64, limit int) ([]model.AuditEvent, error) {
+	_, span := tracing.Start(ctx, "memory.AuditRepo.GetEventsAfter")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	start := sort.Search(len(s.store.audit), func(i int) bool { return s.store.audit[i].ID > afterID })
+	end := min(start+limit, len(s.store.audit))
+	events := make([]model.AuditEvent, end-start)
+	copy(events, s.store.audit[start:end])
+	return events, nil
+}
@@ -0,0 +1,18 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/repositories/conformance"
+	"github.com/fatkulllin/gophkeeper/internal/server/storage"
+)
+
+func TestConformance(t *testing.T) {
+	s, err := storage.Open(storage.Memory, "")
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	conformance.Run(t, s)
+}
@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// DeviceRepo хранит устройства пользователей и список отозванных сертификатов.
+type DeviceRepo struct {
+	store *Store
+}
+
+func NewDeviceRepo(store *Store) *DeviceRepo {
+	return &DeviceRepo{store: store}
+}
+
+// CreateDevice сохраняет устройство с выпущенным сертификатом и возвращает его ID.
+func (s *DeviceRepo) CreateDevice(ctx context.Context, device model.Device) (int, error) {
+	_, span := tracing.Start(ctx, "memory.DeviceRepo.CreateDevice")
+	defer span.End()
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	if _, ok := s.store.users[device.UserID]; !ok {
+		return 0, fmt.Errorf("insert device: %w", model.ErrUserNotFound)
+	}
+	for _, existing := range s.store.devices {
+		if existing.Serial == device.Serial || existing.Thumbprint == device.Thumbprint {
+			return 0, fmt.Errorf("insert device: certificate is already registered")
+		}
+	}
+
+	device.ID = int(s.store.nextID("devices"))
+	device.CreatedAt = time.Now()
+	device.RevokedAt = nil
+	s.store.devices[device.ID] = device
+	return device.ID, nil
+}
+
+// ListDevices возвращает устройства пользователя, включая отозванные.
+func (s *DeviceRepo) ListDevices(ctx context.Context, userID int) ([]model.Device, error) {
+	_, span := tracing.Start(ctx, "memory.DeviceRepo.ListDevices")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	devices := make([]model.Device, 0)
+	for _, device := range s.store.devices {
+		if device.UserID == userID {
+			devices = append(devices, s.withRevocation(device))
+		}
+	}
+	slices.SortFunc(devices, func(a, b model.Device) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return devices, nil
+}
+
+// GetDeviceByThumbprint возвращает устройство по отпечатку сертификата.
+func (s *DeviceRepo) GetDeviceByThumbprint(ctx context.Context, thumbprint string) (model.Device, error) {
+	_, span := tracing.Start(ctx, "memory.DeviceRepo.GetDeviceByThumbprint")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	for _, device := range s.store.devices {
+		if device.Thumbprint == thumbprint {
+			return s.withRevocation(device), nil
+		}
+	}
+	return model.Device{}, model.ErrDeviceNotFound
+}
+
+// RevokeDevice добавляет сертификат устройства пользователя в CRL.
+// Повторный отзыв не является ошибкой.
+func (s *DeviceRepo) RevokeDevice(ctx context.Context, userID int, deviceID int) error {
+	_, span := tracing.Start(ctx, "memory.DeviceRepo.RevokeDevice")
+	defer span.End()
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	device, ok := s.store.devices[deviceID]
+	if !ok || device.UserID != userID {
+		return model.ErrDeviceNotFound
+	}
+	if _, ok := s.store.revoked[device.Serial]; !ok {
+		s.store.revoked[device.Serial] = time.Now()
+	}
+	return nil
+}
+
+// GetRequireDevice сообщает, обязателен ли пользователю вход с сертификатом устройства.
+func (s *DeviceRepo) GetRequireDevice(ctx context.Context, userID int) (bool, error) {
+	_, span := tracing.Start(ctx, "memory.DeviceRepo.GetRequireDevice")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	u, ok := s.store.users[userID]
+	if !ok {
+		return false, model.ErrUserNotFound
+	}
+	return u.requireDevice, nil
+}
+
+// SetRequireDevice включает или отключает обязательный вход с сертификатом устройства.
+func (s *DeviceRepo) SetRequireDevice(ctx context.Context, userID int, require bool) error {
+	_, span := tracing.Start(ctx, "memory.DeviceRepo.SetRequireDevice")
+	defer span.End()
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	if u, ok := s.store.users[userID]; ok {
+		u.requireDevice = require
+	}
+	return nil
+}
+
+// withRevocation дополняет устройство датой отзыва его сертификата из CRL.
+func (s *DeviceRepo) withRevocation(device model.Device) model.Device {
+	if revokedAt, ok := s.store.revoked[device.Serial]; ok {
+		device.RevokedAt = &revokedAt
+	}
+	return device
+}
@@ -0,0 +1,4 @@
+// Пакет memory реализует репозитории, хранящие данные в памяти процесса.
+// Семантика выборок и прав доступа повторяет репозитории postgres, поэтому
+// сервер можно запустить без внешней базы данных, а данные теряются при остановке.
+package memory
@@ -0,0 +1,230 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// OrgRepo хранит организации, их участников и коллекции.
+type OrgRepo struct {
+	store *Store
+}
+
+func NewOrgRepo(store *Store) *OrgRepo {
+	return &OrgRepo{store: store}
+}
+
+// CreateOrg атомарно создаёт организацию, назначает создателя владельцем
+// и создаёт первую коллекцию с ключом, выданным владельцу.
+func (s *OrgRepo) CreateOrg(ctx context.Context, name string, collectionName string, ownerKey model.CollectionKey) (model.Organization, error) {
+	_, span := tracing.Start(ctx, "memory.OrgRepo.CreateOrg")
+	defer span.End()
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	for _, existing := range s.store.orgs {
+		if existing == name {
+			return model.Organization{}, model.ErrOrgExists
+		}
+	}
+	if _, ok := s.store.users[ownerKey.UserID]; !ok {
+		return model.Organization{}, fmt.Errorf("failed to insert owner: %w", model.ErrUserNotFound)
+	}
+
+	org := model.Organization{ID: int(s.store.nextID("organizations")), Name: name, Role: model.RoleOwner}
+	s.store.orgs[org.ID] = name
+	s.store.members[memberID{org.ID, ownerKey.UserID}] = &memberRow{role: model.RoleOwner, seq: s.store.nextSeq()}
+
+	collectionID := int(s.store.nextID("collections"))
+	s.store.collections[collectionID] = model.Collection{ID: collectionID, OrgID: org.ID, Name: collectionName}
+	s.store.collectionKeys[memberID{collectionID, ownerKey.UserID}] = ownerKey.WrappedKey
+
+	return org, nil
+}
+
+// GetOrgs возвращает организации пользователя вместе с его ролью.
+func (s *OrgRepo) GetOrgs(ctx context.Context, userID int) ([]model.Organization, error) {
+	_, span := tracing.Start(ctx, "memory.OrgRepo.GetOrgs")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	orgs := make([]model.Organization, 0)
+	for id, name := range s.store.orgs {
+		if member, ok := s.store.members[memberID{id, userID}]; ok {
+			orgs = append(orgs, model.Organization{ID: id, Name: name, Role: member.role})
+		}
+	}
+	slices.SortFunc(orgs, func(a, b model.Organization) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return orgs, nil
+}
+
+// GetMemberRole возвращает роль пользователя в организации.
+func (s *OrgRepo) GetMemberRole(ctx context.Context, orgID int, userID int) (model.OrgRole, error) {
+	_, span := tracing.Start(ctx, "memory.OrgRepo.GetMemberRole")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	member, ok := s.store.members[memberID{orgID, userID}]
+	if !ok {
+		return "", model.ErrNotOrgMember
+	}
+	return member.role, nil
+}
+
+// AddMember атомарно добавляет участника организации и выдаёт ему ключи коллекций.
+func (s *OrgRepo) AddMember(ctx context.Context, orgID int, userID int, role model.OrgRole, keys []model.CollectionKey) error {
+	_, span := tracing.Start(ctx, "memory.OrgRepo.AddMember")
+	defer span.End()
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	if _, ok := s.store.members[memberID{orgID, userID}]; ok {
+		return model.ErrMemberExists
+	}
+	if _, ok := s.store.orgs[orgID]; !ok {
+		return fmt.Errorf("failed to insert member: organization %d does not exist", orgID)
+	}
+	if _, ok := s.store.users[userID]; !ok {
+		return fmt.Errorf("failed to insert member: %w", model.ErrUserNotFound)
+	}
+	for _, key := range keys {
+		if _, ok := s.store.collections[key.CollectionID]; !ok {
+			return fmt.Errorf("failed to insert collection key: collection %d does not exist", key.CollectionID)
+		}
+		if _, ok := s.store.collectionKeys[memberID{key.CollectionID, userID}]; ok {
+			return fmt.Errorf("failed to insert collection key: key for collection %d already exists", key.CollectionID)
+		}
+	}
+
+	s.store.members[memberID{orgID, userID}] = &memberRow{role: role, seq: s.store.nextSeq()}
+	for _, key := range keys {
+		s.store.collectionKeys[memberID{key.CollectionID, userID}] = key.WrappedKey
+	}
+	return nil
+}
+
+// ListMembers возвращает участников организации с их ролями и открытыми ключами.
+func (s *OrgRepo) ListMembers(ctx context.Context, orgID int) ([]model.OrgMember, error) {
+	_, span := tracing.Start(ctx, "memory.OrgRepo.ListMembers")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	type orderedMember struct {
+		member model.OrgMember
+		seq    int64
+	}
+	ordered := make([]orderedMember, 0)
+	for id, row := range s.store.members {
+		if id.id != orgID {
+			continue
+		}
+		u := s.store.users[id.userID]
+		ordered = append(ordered, orderedMember{
+			member: model.OrgMember{UserID: u.ID, Username: u.Login, Role: row.role, PublicKey: u.PublicKey},
+			seq:    row.seq,
+		})
+	}
+	slices.SortFunc(ordered, func(a, b orderedMember) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+
+	members := make([]model.OrgMember, 0, len(ordered))
+	for _, m := range ordered {
+		members = append(members, m.member)
+	}
+	return members, nil
+}
+
+// CreateCollection атомарно создаёт коллекцию и выдаёт её ключ участникам.
+func (s *OrgRepo) CreateCollection(ctx context.Context, orgID int, name string, keys []model.CollectionKey) (model.Collection, error) {
+	_, span := tracing.Start(ctx, "memory.OrgRepo.CreateCollection")
+	defer span.End()
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	if _, ok := s.store.orgs[orgID]; !ok {
+		return model.Collection{}, fmt.Errorf("failed to insert collection: organization %d does not exist", orgID)
+	}
+	for _, existing := range s.store.collections {
+		if existing.OrgID == orgID && existing.Name == name {
+			return model.Collection{}, model.ErrCollectionExists
+		}
+	}
+	for _, key := range keys {
+		if _, ok := s.store.users[key.UserID]; !ok {
+			return model.Collection{}, fmt.Errorf("failed to insert collection key: %w", model.ErrUserNotFound)
+		}
+	}
+
+	collection := model.Collection{ID: int(s.store.nextID("collections")), OrgID: orgID, Name: name}
+	s.store.collections[collection.ID] = collection
+	for _, key := range keys {
+		s.store.collectionKeys[memberID{collection.ID, key.UserID}] = key.WrappedKey
+	}
+	return collection, nil
+}
+
+// ListCollections возвращает коллекции организации вместе с ключами, выданными пользователю.
+func (s *OrgRepo) ListCollections(ctx context.Context, orgID int, userID int) ([]model.Collection, error) {
+	_, span := tracing.Start(ctx, "memory.OrgRepo.ListCollections")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	collections := make([]model.Collection, 0)
+	for _, c := range s.store.collections {
+		if c.OrgID != orgID {
+			continue
+		}
+		c.WrappedKey = s.store.collectionKeys[memberID{c.ID, userID}]
+		collections = append(collections, c)
+	}
+	slices.SortFunc(collections, func(a, b model.Collection) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return collections, nil
+}
+
+// GetCollectionKey возвращает ключ коллекции, выданный пользователю,
+// и его роль в организации коллекции.
+func (s *OrgRepo) GetCollectionKey(ctx context.Context, collectionID int, userID int) (model.CollectionKey, error) {
+	_, span := tracing.Start(ctx, "memory.OrgRepo.GetCollectionKey")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	collection, ok := s.store.collections[collectionID]
+	if !ok {
+		return model.CollectionKey{}, model.ErrNotOrgMember
+	}
+	member, isMember := s.store.members[memberID{collection.OrgID, userID}]
+	wrappedKey, hasKey := s.store.collectionKeys[memberID{collectionID, userID}]
+	if !isMember || !hasKey {
+		return model.CollectionKey{}, model.ErrNotOrgMember
+	}
+	return model.CollectionKey{
+		CollectionID: collectionID,
+		OrgID:        collection.OrgID,
+		UserID:       userID,
+		Role:         member.role,
+		WrappedKey:   wrappedKey,
+	}, nil
+}
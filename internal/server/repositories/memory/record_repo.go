@@ -0,0 +1,349 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// RecordRepo хранит записи пользователей и выданные ключи записей.
+type RecordRepo struct {
+	store *Store
+}
+
+func NewRecordRepo(store *Store) *RecordRepo {
+	return &RecordRepo{store: store}
+}
+
+// CreateRecord добавляет новую запись пользователя.
+func (s *RecordRepo) CreateRecord(ctx context.Context, record model.Record) error {
+	_, span := tracing.Start(ctx, "memory.RecordRepo.CreateRecord")
+	defer span.End()
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	if _, ok := s.store.users[record.UserID]; !ok {
+		return fmt.Errorf("failed to insert record: %w", model.ErrUserNotFound)
+	}
+	if record.CollectionID != 0 {
+		if _, ok := s.store.collections[record.CollectionID]; !ok {
+			return fmt.Errorf("failed to insert record: collection %d does not exist", record.CollectionID)
+		}
+	}
+
+	now := time.Now()
+	id := s.store.nextID("records")
+	s.store.records[id] = &recordRow{
+		id:           id,
+		userID:       record.UserID,
+		collectionID: record.CollectionID,
+		recordType:   record.Type,
+		metadata:     record.Metadata,
+		data:         append([]byte(nil), record.Data...),
+		createdAt:    now,
+		updatedAt:    now,
+		expiresAt:    copyTime(record.ExpiresAt),
+		ephemeral:    record.Ephemeral,
+	}
+	return nil
+}
+
+// GetAllRecords возвращает все личные записи пользователя и записи, открытые ему другими пользователями.
+func (s *RecordRepo) GetAllRecords(ctx context.Context, userID int) ([]model.Record, error) {
+	_, span := tracing.Start(ctx, "memory.RecordRepo.GetAllRecords")
+	defer span.End()
+
+	return s.queryRecords(userID, func(r *recordRow) bool {
+		return r.collectionID == 0
+	}), nil
+}
+
+// GetOrgRecords возвращает записи всех коллекций организации, доступные пользователю.
+func (s *RecordRepo) GetOrgRecords(ctx context.Context, userID int, orgID int) ([]model.Record, error) {
+	_, span := tracing.Start(ctx, "memory.RecordRepo.GetOrgRecords")
+	defer span.End()
+
+	return s.queryRecords(userID, func(r *recordRow) bool {
+		return r.collectionID != 0 && s.store.collections[r.collectionID].OrgID == orgID
+	}), nil
+}
+
+// queryRecords возвращает доступные пользователю записи, удовлетворяющие match,
+// от новых к старым.
+func (s *RecordRepo) queryRecords(userID int, match func(r *recordRow) bool) []model.Record {
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	records := make([]model.Record, 0)
+	for _, r := range s.store.records {
+		if !match(r) {
+			continue
+		}
+		if record, ok := s.store.recordFor(r, userID); ok {
+			records = append(records, record)
+		}
+	}
+	slices.SortFunc(records, func(a, b model.Record) int {
+		return cmp.Compare(b.ID, a.ID)
+	})
+	return records
+}
+
+// DeleteRecord удаляет запись по ID. Личную запись может удалить только владелец,
+// запись коллекции — участник организации с правом записи.
+func (s *RecordRepo) DeleteRecord(ctx context.Context, userID int, idRecord string) error {
+	_, span := tracing.Start(ctx, "memory.RecordRepo.DeleteRecord")
+	defer span.End()
+
+	id, err := parseRecordID(idRecord)
+	if err != nil {
+		return err
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	r, ok := s.store.records[id]
+	if !ok || !(r.collectionID == 0 && r.userID == userID || s.store.canWriteCollection(r.collectionID, userID)) {
+		return fmt.Errorf("delete record id=%s: %w", idRecord, model.ErrRecordNotFound)
+	}
+
+	delete(s.store.records, id)
+	for key := range s.store.recordKeys {
+		if key.recordID == id {
+			delete(s.store.recordKeys, key)
+		}
+	}
+	return nil
+}
+
+// GetRecord возвращает запись по её ID, если пользователь владеет ею,
+// получил к ней доступ или состоит в организации её коллекции.
+func (s *RecordRepo) GetRecord(ctx context.Context, userID int, idRecord string) (model.Record, error) {
+	_, span := tracing.Start(ctx, "memory.RecordRepo.GetRecord")
+	defer span.End()
+
+	id, err := parseRecordID(idRecord)
+	if err != nil {
+		return model.Record{}, err
+	}
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	if r, ok := s.store.records[id]; ok {
+		if record, ok := s.store.recordFor(r, userID); ok {
+			return record, nil
+		}
+	}
+	return model.Record{}, fmt.Errorf("record not found for user %v: %w", userID, model.ErrRecordNotFound)
+}
+
+// UpdateRecord обновляет метаданные и/или данные записи, а если expiry
+// не nil, то и срок действия — в одном запросе, чтобы запись не осталась
+// изменённой наполовину. Время изменения (updated_at) обновляется только
+// вместе с метаданными или данными. Обновление доступно владельцу, пользователям с правом записи
+// и участникам организации, роль которых допускает запись.
+func (s *RecordRepo) UpdateRecord(ctx context.Context, userID int, idRecord string, record model.Record, expiry *model.RecordExpiry) error {
+	_, span := tracing.Start(ctx, "memory.RecordRepo.UpdateRecord")
+	defer span.End()
+
+	if record.Metadata == "" && record.Data == nil && expiry == nil {
+		return nil
+	}
+
+	id, err := parseRecordID(idRecord)
+	if err != nil {
+		return err
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	r, ok := s.store.records[id]
+	if !ok || !s.store.canWriteRecord(r, userID) {
+		return nil
+	}
+
+	if record.Metadata != "" {
+		r.metadata = record.Metadata
+	}
+	if record.Data != nil {
+		r.data = append([]byte(nil), record.Data...)
+	}
+	if record.Metadata != "" || record.Data != nil {
+		r.updatedAt = time.Now()
+	}
+	if expiry != nil {
+		r.expiresAt = copyTime(expiry.ExpiresAt)
+		r.ephemeral = expiry.Ephemeral
+	}
+	return nil
+}
+
+// DeleteExpiredRecords удаляет эфемерные записи, срок действия которых
+// наступил к моменту now, и возвращает их идентификаторы и владельцев.
+func (s *RecordRepo) DeleteExpiredRecords(ctx context.Context, now time.Time) ([]model.Record, error) {
+	_, span := tracing.Start(ctx, "memory.RecordRepo.DeleteExpiredRecords")
+	defer span.End()
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	deleted := make([]model.Record, 0)
+	for id, r := range s.store.records {
+		if !r.ephemeral || r.expiresAt == nil || r.expiresAt.After(now) {
+			continue
+		}
+		deleted = append(deleted, model.Record{ID: id, UserID: r.userID, CollectionID: r.collectionID})
+		delete(s.store.records, id)
+		for key := range s.store.recordKeys {
+			if key.recordID == id {
+				delete(s.store.recordKeys, key)
+			}
+		}
+	}
+	slices.SortFunc(deleted, func(a, b model.Record) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return deleted, nil
+}
+
+// ListRecordKeys возвращает ключи записи, выданные владельцу и получателям,
+// вместе с логинами и открытыми ключами пользователей.
+func (s *RecordRepo) ListRecordKeys(ctx context.Context, idRecord int64) ([]model.RecordKey, error) {
+	_, span := tracing.Start(ctx, "memory.RecordRepo.ListRecordKeys")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	type orderedKey struct {
+		key model.RecordKey
+		seq int64
+	}
+	ordered := make([]orderedKey, 0)
+	for id, row := range s.store.recordKeys {
+		if id.recordID != idRecord {
+			continue
+		}
+		u := s.store.users[id.userID]
+		ordered = append(ordered, orderedKey{
+			key: model.RecordKey{
+				RecordID:   id.recordID,
+				UserID:     id.userID,
+				Login:      u.Login,
+				PublicKey:  u.PublicKey,
+				Permission: row.permission,
+				WrappedKey: row.wrappedKey,
+			},
+			seq: row.seq,
+		})
+	}
+	slices.SortFunc(ordered, func(a, b orderedKey) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+
+	keys := make([]model.RecordKey, 0, len(ordered))
+	for _, k := range ordered {
+		keys = append(keys, k.key)
+	}
+	return keys, nil
+}
+
+// PutRecordKey выдаёт пользователю ключ записи или обновляет его права.
+func (s *RecordRepo) PutRecordKey(ctx context.Context, key model.RecordKey) error {
+	_, span := tracing.Start(ctx, "memory.RecordRepo.PutRecordKey")
+	defer span.End()
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	if err := s.checkRecordKey(key.RecordID, key.UserID); err != nil {
+		return fmt.Errorf("failed to put record key: %w", err)
+	}
+
+	id := recordKeyID{key.RecordID, key.UserID}
+	if row, ok := s.store.recordKeys[id]; ok {
+		row.permission = key.Permission
+		row.wrappedKey = key.WrappedKey
+		return nil
+	}
+	s.store.recordKeys[id] = &recordKeyRow{
+		permission: key.Permission,
+		wrappedKey: key.WrappedKey,
+		seq:        s.store.nextSeq(),
+	}
+	return nil
+}
+
+// ReplaceRecordKeys атомарно перезаписывает зашифрованные данные записи
+// и полностью заменяет набор выданных ключей. Используется при переводе записи
+// на собственный ключ и при ротации ключа после отзыва доступа. Содержимое
+// записи не меняется, поэтому время изменения (updated_at) остаётся прежним.
+func (s *RecordRepo) ReplaceRecordKeys(ctx context.Context, idRecord int64, data []byte, keys []model.RecordKey) error {
+	_, span := tracing.Start(ctx, "memory.RecordRepo.ReplaceRecordKeys")
+	defer span.End()
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	r, ok := s.store.records[idRecord]
+	if !ok {
+		return model.ErrRecordNotFound
+	}
+	for _, key := range keys {
+		if err := s.checkRecordKey(idRecord, key.UserID); err != nil {
+			return fmt.Errorf("failed to insert record key: %w", err)
+		}
+	}
+
+	r.data = append([]byte(nil), data...)
+
+	for id := range s.store.recordKeys {
+		if id.recordID == idRecord {
+			delete(s.store.recordKeys, id)
+		}
+	}
+	for _, key := range keys {
+		s.store.recordKeys[recordKeyID{idRecord, key.UserID}] = &recordKeyRow{
+			permission: key.Permission,
+			wrappedKey: key.WrappedKey,
+			seq:        s.store.nextSeq(),
+		}
+	}
+	return nil
+}
+
+// checkRecordKey проверяет ссылки ключа на запись и пользователя,
+// как внешние ключи таблицы record_keys.
+func (s *RecordRepo) checkRecordKey(recordID int64, userID int) error {
+	if _, ok := s.store.records[recordID]; !ok {
+		return model.ErrRecordNotFound
+	}
+	if _, ok := s.store.users[userID]; !ok {
+		return model.ErrUserNotFound
+	}
+	return nil
+}
+
+// CountRecordsByType возвращает количество записей всех пользователей по типам.
+func (s *RecordRepo) CountRecordsByType(ctx context.Context) (map[model.RecordType]int, error) {
+	_, span := tracing.Start(ctx, "memory.RecordRepo.CountRecordsByType")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	counts := make(map[model.RecordType]int)
+	for _, r := range s.store.records {
+		counts[r.recordType]++
+	}
+	return counts, nil
+}
@@ -0,0 +1,188 @@
+package memory
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// Store — общее состояние репозиториев в памяти. Все репозитории одного
+// хранилища работают с общими таблицами под одной блокировкой, поэтому
+// операции, которые в Postgres выполняются в транзакции, здесь атомарны.
+type Store struct {
+	mu sync.RWMutex
+
+	lastID map[string]int64
+	seq    int64
+
+	users          map[int]*userRow
+	records        map[int64]*recordRow
+	recordKeys     map[recordKeyID]*recordKeyRow
+	orgs           map[int]string
+	members        map[memberID]*memberRow
+	collections    map[int]model.Collection
+	collectionKeys map[memberID]string
+	audit          []model.AuditEvent
+	devices        map[int]model.Device
+	revoked        map[string]time.Time
+	vaultTokens    map[int]*vaultTokenRow
+}
+
+type userRow struct {
+	model.User
+	encryptedPrivateKey string
+	requireDevice       bool
+}
+
+type vaultTokenRow struct {
+	model.VaultToken
+	hash string
+}
+
+type recordRow struct {
+	id           int64
+	userID       int
+	collectionID int
+	recordType   model.RecordType
+	metadata     string
+	data         []byte
+	createdAt    time.Time
+	updatedAt    time.Time
+	expiresAt    *time.Time
+	ephemeral    bool
+}
+
+type recordKeyID struct {
+	recordID int64
+	userID   int
+}
+
+type recordKeyRow struct {
+	permission model.SharePermission
+	wrappedKey string
+	seq        int64
+}
+
+// memberID — пара (организация или коллекция, пользователь).
+type memberID struct {
+	id     int
+	userID int
+}
+
+type memberRow struct {
+	role model.OrgRole
+	seq  int64
+}
+
+// NewStore создаёт пустое хранилище.
+func NewStore() *Store {
+	return &Store{
+		lastID:         make(map[string]int64),
+		users:          make(map[int]*userRow),
+		records:        make(map[int64]*recordRow),
+		recordKeys:     make(map[recordKeyID]*recordKeyRow),
+		orgs:           make(map[int]string),
+		members:        make(map[memberID]*memberRow),
+		collections:    make(map[int]model.Collection),
+		collectionKeys: make(map[memberID]string),
+		devices:        make(map[int]model.Device),
+		revoked:        make(map[string]time.Time),
+		vaultTokens:    make(map[int]*vaultTokenRow),
+	}
+}
+
+// nextID выдаёт следующий идентификатор таблицы, как identity-колонка.
+func (s *Store) nextID(table string) int64 {
+	s.lastID[table]++
+	return s.lastID[table]
+}
+
+// nextSeq выдаёт порядковый номер вставки для строк без собственного ID.
+func (s *Store) nextSeq() int64 {
+	s.seq++
+	return s.seq
+}
+
+// recordFor возвращает запись так, как её видит пользователь userID:
+// с его ключом и уровнем доступа. Второй результат равен false,
+// если запись пользователю недоступна.
+func (s *Store) recordFor(r *recordRow, userID int) (model.Record, bool) {
+	record := model.Record{
+		ID:           r.id,
+		UserID:       r.userID,
+		CollectionID: r.collectionID,
+		Type:         r.recordType,
+		Metadata:     r.metadata,
+		Data:         append([]byte(nil), r.data...),
+		UpdatedAt:    r.updatedAt,
+		ExpiresAt:    copyTime(r.expiresAt),
+		Ephemeral:    r.ephemeral,
+	}
+	key, hasKey := s.recordKeys[recordKeyID{r.id, userID}]
+	if hasKey {
+		record.DataKey = key.wrappedKey
+	}
+
+	if r.collectionID == 0 {
+		record.Permission = model.PermissionOwner
+		if hasKey {
+			record.Permission = key.permission
+		}
+		return record, r.userID == userID || hasKey
+	}
+
+	collection := s.collections[r.collectionID]
+	record.OrgID = collection.OrgID
+	member, isMember := s.members[memberID{collection.OrgID, userID}]
+	collectionKey, hasCollectionKey := s.collectionKeys[memberID{r.collectionID, userID}]
+	if !hasKey {
+		record.DataKey = collectionKey
+	}
+	record.Permission = model.PermissionWrite
+	if isMember && member.role == model.RoleReadOnly {
+		record.Permission = model.PermissionRead
+	}
+	return record, isMember && hasCollectionKey
+}
+
+// canWriteRecord сообщает, может ли пользователь изменять запись: личную —
+// владелец и получатель с правом записи, запись коллекции — участник
+// организации с правом записи.
+func (s *Store) canWriteRecord(r *recordRow, userID int) bool {
+	if r.collectionID != 0 {
+		return s.canWriteCollection(r.collectionID, userID)
+	}
+	key, hasKey := s.recordKeys[recordKeyID{r.id, userID}]
+	return r.userID == userID || hasKey && key.permission == model.PermissionWrite
+}
+
+// copyTime возвращает копию времени, чтобы вызывающий не менял строку хранилища.
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := t.UTC()
+	return &c
+}
+
+// canWriteCollection сообщает, может ли пользователь изменять записи коллекции.
+func (s *Store) canWriteCollection(collectionID int, userID int) bool {
+	collection, ok := s.collections[collectionID]
+	if !ok {
+		return false
+	}
+	member, ok := s.members[memberID{collection.OrgID, userID}]
+	return ok && member.role != model.RoleReadOnly
+}
+
+// parseRecordID разбирает идентификатор записи из пути запроса.
+func parseRecordID(idRecord string) (int64, error) {
+	id, err := strconv.ParseInt(idRecord, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid record id %q: %w", idRecord, model.ErrRecordNotFound)
+	}
+	return id, nil
+}
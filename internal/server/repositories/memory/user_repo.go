@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// UserRepo хранит пользователей и их ключи.
+type UserRepo struct {
+	store *Store
+}
+
+func NewUserRepo(store *Store) *UserRepo {
+	return &UserRepo{store: store}
+}
+
+// findByLogin ищет пользователя по логину. Вызывается под блокировкой хранилища.
+func (s *UserRepo) findByLogin(login string) (*userRow, bool) {
+	for _, u := range s.store.users {
+		if u.Login == login {
+			return u, true
+		}
+	}
+	return nil, false
+}
+
+// ExistUser проверяет наличие пользователя по логину.
+// Возвращает true, если пользователь существует.
+func (s *UserRepo) ExistUser(ctx context.Context, user model.UserCredentials) (bool, error) {
+	_, span := tracing.Start(ctx, "memory.UserRepo.ExistUser")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	_, ok := s.findByLogin(user.Username)
+	return ok, nil
+}
+
+// CreateUser создаёт нового пользователя и возвращает его ID.
+func (s *UserRepo) CreateUser(ctx context.Context, user model.UserCredentials) (int, error) {
+	_, span := tracing.Start(ctx, "memory.UserRepo.CreateUser")
+	defer span.End()
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	if _, ok := s.findByLogin(user.Username); ok {
+		return 0, fmt.Errorf("failed to insert new user: %w", model.ErrUserExists)
+	}
+
+	id := int(s.store.nextID("users"))
+	s.store.users[id] = &userRow{
+		User: model.User{
+			ID:           id,
+			Login:        user.Username,
+			PasswordHash: user.Password,
+			EncryptedKey: user.EncryptedKey,
+			PublicKey:    user.PublicKey,
+		},
+		encryptedPrivateKey: user.EncryptedPrivateKey,
+	}
+	return id, nil
+}
+
+// GetUser возвращает пользователя по логину.
+func (s *UserRepo) GetUser(ctx context.Context, user model.UserCredentials) (model.User, error) {
+	_, span := tracing.Start(ctx, "memory.UserRepo.GetUser")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	u, ok := s.findByLogin(user.Username)
+	if !ok {
+		return model.User{}, model.ErrUserNotFound
+	}
+	return model.User{ID: u.ID, Login: u.Login, PasswordHash: u.PasswordHash}, nil
+}
+
+// GetEncryptedKeyUser возвращает зашифрованный ключ пользователя.
+func (s *UserRepo) GetEncryptedKeyUser(ctx context.Context, userID int) (string, error) {
+	_, span := tracing.Start(ctx, "memory.UserRepo.GetEncryptedKeyUser")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	u, ok := s.store.users[userID]
+	if !ok {
+		return "", model.ErrUserNotFound
+	}
+	return u.EncryptedKey, nil
+}
+
+// GetUserByLogin возвращает пользователя и его открытый ключ по логину.
+func (s *UserRepo) GetUserByLogin(ctx context.Context, login string) (model.User, error) {
+	_, span := tracing.Start(ctx, "memory.UserRepo.GetUserByLogin")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	u, ok := s.findByLogin(login)
+	if !ok {
+		return model.User{}, model.ErrUserNotFound
+	}
+	return model.User{ID: u.ID, Login: u.Login, PublicKey: u.PublicKey}, nil
+}
+
+// GetKeyPair возвращает открытый ключ пользователя и закрытый ключ,
+// зашифрованный master-key. Для пользователей без пары ключей возвращаются пустые строки.
+func (s *UserRepo) GetKeyPair(ctx context.Context, userID int) (string, string, error) {
+	_, span := tracing.Start(ctx, "memory.UserRepo.GetKeyPair")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	u, ok := s.store.users[userID]
+	if !ok {
+		return "", "", model.ErrUserNotFound
+	}
+	return u.PublicKey, u.encryptedPrivateKey, nil
+}
+
+// SetKeyPair сохраняет пару ключей пользователя, если у него её ещё нет,
+// и сообщает, сохранена ли она. Существующая пара не перезаписывается: ею
+// уже могут быть зашифрованы ключи записей и коллекций.
+func (s *UserRepo) SetKeyPair(ctx context.Context, userID int, publicKey string, encryptedPrivateKey string) (bool, error) {
+	_, span := tracing.Start(ctx, "memory.UserRepo.SetKeyPair")
+	defer span.End()
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	u, ok := s.store.users[userID]
+	if !ok || u.PublicKey != "" {
+		return false, nil
+	}
+	u.PublicKey = publicKey
+	u.encryptedPrivateKey = encryptedPrivateKey
+	return true, nil
+}
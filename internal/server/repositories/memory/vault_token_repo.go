@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// VaultTokenRepo хранит токены клиентов API Vault KV v2.
+type VaultTokenRepo struct {
+	store *Store
+}
+
+func NewVaultTokenRepo(store *Store) *VaultTokenRepo {
+	return &VaultTokenRepo{store: store}
+}
+
+// CreateVaultToken сохраняет токен с хешем hash и возвращает его ID.
+func (s *VaultTokenRepo) CreateVaultToken(ctx context.Context, token model.VaultToken, hash string) (int, error) {
+	_, span := tracing.Start(ctx, "memory.VaultTokenRepo.CreateVaultToken")
+	defer span.End()
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	if _, ok := s.store.users[token.UserID]; !ok {
+		return 0, fmt.Errorf("insert vault token: %w", model.ErrUserNotFound)
+	}
+	for _, existing := range s.store.vaultTokens {
+		if existing.hash == hash {
+			return 0, fmt.Errorf("insert vault token: token is already registered")
+		}
+	}
+
+	token.ID = int(s.store.nextID("vault_tokens"))
+	token.UserLogin = ""
+	s.store.vaultTokens[token.ID] = &vaultTokenRow{VaultToken: token, hash: hash}
+	return token.ID, nil
+}
+
+// ListVaultTokens возвращает токены пользователя.
+func (s *VaultTokenRepo) ListVaultTokens(ctx context.Context, userID int) ([]model.VaultToken, error) {
+	_, span := tracing.Start(ctx, "memory.VaultTokenRepo.ListVaultTokens")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	tokens := make([]model.VaultToken, 0)
+	for _, row := range s.store.vaultTokens {
+		if row.UserID == userID {
+			tokens = append(tokens, row.VaultToken)
+		}
+	}
+	slices.SortFunc(tokens, func(a, b model.VaultToken) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return tokens, nil
+}
+
+// GetVaultTokenByHash возвращает токен по хешу вместе с логином владельца.
+func (s *VaultTokenRepo) GetVaultTokenByHash(ctx context.Context, hash string) (model.VaultToken, error) {
+	_, span := tracing.Start(ctx, "memory.VaultTokenRepo.GetVaultTokenByHash")
+	defer span.End()
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	for _, row := range s.store.vaultTokens {
+		if row.hash != hash {
+			continue
+		}
+		u, ok := s.store.users[row.UserID]
+		if !ok {
+			break
+		}
+		token := row.VaultToken
+		token.UserLogin = u.Login
+		return token, nil
+	}
+	return model.VaultToken{}, model.ErrVaultTokenNotFound
+}
+
+// DeleteVaultToken удаляет токен пользователя.
+func (s *VaultTokenRepo) DeleteVaultToken(ctx context.Context, userID int, tokenID int) error {
+	_, span := tracing.Start(ctx, "memory.VaultTokenRepo.DeleteVaultToken")
+	defer span.End()
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	row, ok := s.store.vaultTokens[tokenID]
+	if !ok || row.UserID != userID {
+		return model.ErrVaultTokenNotFound
+	}
+	delete(s.store.vaultTokens, tokenID)
+	return nil
+}
@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// auditLockKey — ключ advisory-блокировки, сериализующей добавление событий
+// в цепочку журнала аудита.
+const auditLockKey = 0x6175646974
+
+// AuditRepo хранит журнал аудита в таблице audit_events.
+type AuditRepo struct {
+	db *sql.DB
+}
+
+func NewAuditRepo(db *sql.DB) *AuditRepo {
+	return &AuditRepo{db: db}
+}
+
+// AppendEvent добавляет событие в конец цепочки. Hash предыдущего события
+// читается под advisory-блокировкой, поэтому параллельные вставки не ветвят цепочку.
+func (s *AuditRepo) AppendEvent(ctx context.Context, event model.AuditEvent) error {
+	ctx, span := tracing.Start(ctx, "postgres.AuditRepo.AppendEvent", dbSystem)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", auditLockKey); err != nil {
+		return fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+
+	var prevHash string
+	err = tx.QueryRowContext(ctx, "SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1").Scan(&prevHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to read last audit hash: %w", err)
+	}
+
+	event.PrevHash = prevHash
+	event.Hash = event.ChainHash(prevHash)
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO audit_events (user_id, login, action, record_id, ip, user_agent, result, created_at, prev_hash, hash)
+		VALUES (NULLIF($1, 0), $2, $3, NULLIF($4, 0), $5, $6, $7, $8, $9, $10)
+		`, event.UserID, event.Login, event.Action, event.RecordID, event.IP, event.UserAgent, event.Result, event.CreatedAt, event.PrevHash, event.Hash)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit event: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ListEvents возвращает события пользователя и события с его записями,
+// отфильтрованные по условиям filter, от новых к старым.
+func (s *AuditRepo) ListEvents(ctx context.Context, userID int, filter model.AuditFilter) ([]model.AuditEvent, error) {
+	ctx, span := tracing.Start(ctx, "postgres.AuditRepo.ListEvents", dbSystem)
+	defer span.End()
+
+	query := `
+		SELECT id, COALESCE(user_id, 0), login, action, COALESCE(record_id, 0), ip, user_agent, result, created_at, prev_hash, hash
+		FROM audit_events
+		WHERE (user_id = $1 OR record_id IN (SELECT id FROM records WHERE user_id = $1))`
+	args := []any{userID}
+	idx := 2
+
+	if filter.Action != "" {
+		query += fmt.Sprintf(" AND action = $%d", idx)
+		args = append(args, filter.Action)
+		idx++
+	}
+	if filter.Result != "" {
+		query += fmt.Sprintf(" AND result = $%d", idx)
+		args = append(args, filter.Result)
+		idx++
+	}
+	if filter.RecordID != 0 {
+		query += fmt.Sprintf(" AND record_id = $%d", idx)
+		args = append(args, filter.RecordID)
+		idx++
+	}
+	if !filter.Since.IsZero() {
+		query += fmt.Sprintf(" AND created_at >= $%d", idx)
+		args = append(args, filter.Since)
+		idx++
+	}
+	if !filter.Until.IsZero() {
+		query += fmt.Sprintf(" AND created_at < $%d", idx)
+		args = append(args, filter.Until)
+		idx++
+	}
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", idx)
+	args = append(args, filter.Limit)
+
+	return s.queryEvents(ctx, query, args...)
+}
+
+// GetEventsAfter возвращает не больше limit событий цепочки с
+// идентификатором больше afterID в порядке добавления.
+func (s *AuditRepo) GetEventsAfter(ctx context.Context, afterID int64, limit int) ([]model.AuditEvent, error) {
+	ctx, span := tracing.Start(ctx, "postgres.AuditRepo.GetEventsAfter", dbSystem)
+	defer span.End()
+
+	return s.queryEvents(ctx, `
+		SELECT id, COALESCE(user_id, 0), login, action, COALESCE(record_id, 0), ip, user_agent, result, created_at, prev_hash, hash
+		FROM audit_events
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2`, afterID, limit)
+}
+
+func (s *AuditRepo) queryEvents(ctx context.Context, query string, args ...any) ([]model.AuditEvent, error) {
+	events := make([]model.AuditEvent, 0)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e model.AuditEvent
+		err := rows.Scan(&e.ID, &e.UserID, &e.Login, &e.Action, &e.RecordID, &e.IP, &e.UserAgent, &e.Result, &e.CreatedAt, &e.PrevHash, &e.Hash)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,27 @@
+package postgres_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/repositories/conformance"
+	"github.com/fatkulllin/gophkeeper/internal/server/storage"
+)
+
+// TestConformance прогоняет набор на базе из TEST_DATABASE_URI; без неё
+// тест пропускается. Сценарии используют уникальные имена, поэтому база
+// может быть непустой.
+func TestConformance(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URI")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URI is not set")
+	}
+
+	s, err := storage.Open(storage.Postgres, dsn)
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	conformance.Run(t, s)
+}
@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// deviceSelect выбирает устройство вместе с датой отзыва его сертификата из CRL.
+const deviceSelect = `
+	SELECT d.id, d.user_id, d.name, d.serial, d.thumbprint, d.created_at, d.expires_at, r.revoked_at
+	FROM devices d
+	LEFT JOIN revoked_certificates r ON r.serial = d.serial`
+
+// DeviceRepo хранит устройства пользователей и список отозванных сертификатов.
+type DeviceRepo struct {
+	db *sql.DB
+}
+
+func NewDeviceRepo(db *sql.DB) *DeviceRepo {
+	return &DeviceRepo{db: db}
+}
+
+// CreateDevice сохраняет устройство с выпущенным сертификатом и возвращает его ID.
+func (s *DeviceRepo) CreateDevice(ctx context.Context, device model.Device) (int, error) {
+	ctx, span := tracing.Start(ctx, "postgres.DeviceRepo.CreateDevice", dbSystem)
+	defer span.End()
+
+	var id int
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO devices (user_id, name, serial, thumbprint, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+		`, device.UserID, device.Name, device.Serial, device.Thumbprint, device.ExpiresAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("insert device: %w", err)
+	}
+	return id, nil
+}
+
+// ListDevices возвращает устройства пользователя, включая отозванные.
+func (s *DeviceRepo) ListDevices(ctx context.Context, userID int) ([]model.Device, error) {
+	ctx, span := tracing.Start(ctx, "postgres.DeviceRepo.ListDevices", dbSystem)
+	defer span.End()
+
+	devices := make([]model.Device, 0)
+	rows, err := s.db.QueryContext(ctx, deviceSelect+" WHERE d.user_id = $1 ORDER BY d.id", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		device, err := scanDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// GetDeviceByThumbprint возвращает устройство по отпечатку сертификата.
+func (s *DeviceRepo) GetDeviceByThumbprint(ctx context.Context, thumbprint string) (model.Device, error) {
+	ctx, span := tracing.Start(ctx, "postgres.DeviceRepo.GetDeviceByThumbprint", dbSystem)
+	defer span.End()
+
+	device, err := scanDevice(s.db.QueryRowContext(ctx, deviceSelect+" WHERE d.thumbprint = $1", thumbprint))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Device{}, model.ErrDeviceNotFound
+		}
+		return model.Device{}, err
+	}
+	return device, nil
+}
+
+// RevokeDevice добавляет сертификат устройства пользователя в CRL.
+// Повторный отзыв не является ошибкой.
+func (s *DeviceRepo) RevokeDevice(ctx context.Context, userID int, deviceID int) error {
+	ctx, span := tracing.Start(ctx, "postgres.DeviceRepo.RevokeDevice", dbSystem)
+	defer span.End()
+
+	var serial string
+	err := s.db.QueryRowContext(ctx, "SELECT serial FROM devices WHERE id = $1 AND user_id = $2", deviceID, userID).Scan(&serial)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrDeviceNotFound
+		}
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, "INSERT INTO revoked_certificates (serial) VALUES ($1) ON CONFLICT (serial) DO NOTHING", serial)
+	if err != nil {
+		return fmt.Errorf("revoke device: %w", err)
+	}
+	return nil
+}
+
+// GetRequireDevice сообщает, обязателен ли пользователю вход с сертификатом устройства.
+func (s *DeviceRepo) GetRequireDevice(ctx context.Context, userID int) (bool, error) {
+	ctx, span := tracing.Start(ctx, "postgres.DeviceRepo.GetRequireDevice", dbSystem)
+	defer span.End()
+
+	var require bool
+	err := s.db.QueryRowContext(ctx, "SELECT require_device FROM users WHERE id = $1", userID).Scan(&require)
+	if err != nil {
+		return false, err
+	}
+	return require, nil
+}
+
+// SetRequireDevice включает или отключает обязательный вход с сертификатом устройства.
+func (s *DeviceRepo) SetRequireDevice(ctx context.Context, userID int, require bool) error {
+	ctx, span := tracing.Start(ctx, "postgres.DeviceRepo.SetRequireDevice", dbSystem)
+	defer span.End()
+
+	_, err := s.db.ExecContext(ctx, "UPDATE users SET require_device = $1 WHERE id = $2", require, userID)
+	return err
+}
+
+func scanDevice(row rowScanner) (model.Device, error) {
+	var device model.Device
+	var revokedAt sql.NullTime
+	err := row.Scan(&device.ID, &device.UserID, &device.Name, &device.Serial, &device.Thumbprint, &device.CreatedAt, &device.ExpiresAt, &revokedAt)
+	if err != nil {
+		return model.Device{}, err
+	}
+	if revokedAt.Valid {
+		device.RevokedAt = &revokedAt.Time
+	}
+	return device, nil
+}
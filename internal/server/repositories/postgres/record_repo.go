@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
 	"github.com/fatkulllin/gophkeeper/model"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -18,6 +20,54 @@ type RecordRepo struct {
 	db *sql.DB
 }
 
+// recordSelect выбирает записи вместе с ключом и уровнем доступа пользователя $1.
+// Для личных записей ключ и права берутся из record_keys, для записей коллекций —
+// из collection_keys и роли пользователя в организации.
+const recordSelect = `
+	SELECT r.id, r.user_id, COALESCE(r.collection_id, 0), COALESCE(c.org_id, 0),
+		r.type, r.metadata, r.data,
+		COALESCE(k.wrapped_key, ck.wrapped_key, ''),
+		CASE
+			WHEN r.collection_id IS NULL THEN COALESCE(k.permission, 'owner')
+			WHEN m.role = 'read-only' THEN 'read'
+			ELSE 'write'
+		END,
+		r.updated_at, r.expires_at, r.ephemeral
+	FROM records r
+	LEFT JOIN record_keys k ON k.record_id = r.id AND k.user_id = $1
+	LEFT JOIN collections c ON c.id = r.collection_id
+	LEFT JOIN org_members m ON m.org_id = c.org_id AND m.user_id = $1
+	LEFT JOIN collection_keys ck ON ck.collection_id = r.collection_id AND ck.user_id = $1
+	`
+
+// personalRecords — личные записи пользователя $1 и записи, открытые ему владельцами.
+const personalRecords = `(r.collection_id IS NULL AND (r.user_id = $1 OR k.user_id IS NOT NULL))`
+
+// collectionRecords — записи коллекций организаций, в которых состоит пользователь $1.
+const collectionRecords = `(r.collection_id IS NOT NULL AND m.user_id IS NOT NULL AND ck.user_id IS NOT NULL)`
+
+// writableCollections — коллекции, записи которых пользователь может изменять.
+const writableCollections = `SELECT c.id FROM collections c
+	JOIN org_members m ON m.org_id = c.org_id
+	WHERE m.user_id = $%d AND m.role <> 'read-only'`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRecord(row rowScanner, r *model.Record) error {
+	var expiresAt sql.NullTime
+	err := row.Scan(&r.ID, &r.UserID, &r.CollectionID, &r.OrgID, &r.Type, &r.Metadata, &r.Data, &r.DataKey, &r.Permission, &r.UpdatedAt, &expiresAt, &r.Ephemeral)
+	if err != nil {
+		return err
+	}
+	if expiresAt.Valid {
+		t := expiresAt.Time.UTC()
+		r.ExpiresAt = &t
+	}
+	return nil
+}
+
 // NewPGRepo создаёт подключение к базе данных Postgres по переданному DSN.
 // Выполняется проверка соединения через PingContext.
 // Возвращает репозиторий или ошибку при инициализации.
@@ -28,8 +78,15 @@ func NewRecordRepo(db *sql.DB) *RecordRepo {
 
 // CreateRecord добавляет новую запись пользователя.
 func (s *RecordRepo) CreateRecord(ctx context.Context, record model.Record) error {
+	ctx, span := tracing.Start(ctx, "postgres.RecordRepo.CreateRecord", dbSystem)
+	defer span.End()
 
-	_, err := s.db.ExecContext(ctx, "INSERT INTO records (user_id, type, metadata, data) VALUES ($1, $2, $3, $4)", record.UserID, record.Type, record.Metadata, record.Data)
+	var expiresAt any
+	if record.ExpiresAt != nil {
+		expiresAt = record.ExpiresAt.UTC()
+	}
+	_, err := s.db.ExecContext(ctx, "INSERT INTO records (user_id, type, metadata, data, collection_id, expires_at, ephemeral) VALUES ($1, $2, $3, $4, NULLIF($5, 0), $6, $7)",
+		record.UserID, record.Type, record.Metadata, record.Data, record.CollectionID, expiresAt, record.Ephemeral)
 
 	if err != nil {
 		return fmt.Errorf("failed to insert record: %w", err)
@@ -38,15 +95,25 @@ func (s *RecordRepo) CreateRecord(ctx context.Context, record model.Record) erro
 	return nil
 }
 
-// GetAllRecords возвращает все записи пользователя.
+// GetAllRecords возвращает все личные записи пользователя и записи, открытые ему другими пользователями.
 func (s *RecordRepo) GetAllRecords(ctx context.Context, userID int) ([]model.Record, error) {
+	ctx, span := tracing.Start(ctx, "postgres.RecordRepo.GetAllRecords", dbSystem)
+	defer span.End()
+
+	return s.queryRecords(ctx, recordSelect+"WHERE "+personalRecords+" ORDER BY r.created_at DESC", userID)
+}
+
+// GetOrgRecords возвращает записи всех коллекций организации, доступные пользователю.
+func (s *RecordRepo) GetOrgRecords(ctx context.Context, userID int, orgID int) ([]model.Record, error) {
+	ctx, span := tracing.Start(ctx, "postgres.RecordRepo.GetOrgRecords", dbSystem)
+	defer span.End()
+
+	return s.queryRecords(ctx, recordSelect+"WHERE "+collectionRecords+" AND c.org_id = $2 ORDER BY r.created_at DESC", userID, orgID)
+}
+
+func (s *RecordRepo) queryRecords(ctx context.Context, query string, args ...any) ([]model.Record, error) {
 	records := make([]model.Record, 0)
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, user_id, type, metadata, data
-		FROM records
-		WHERE user_id = $1
-		ORDER BY created_at DESC
-		`, userID)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 
 	if err != nil {
 		return nil, err
@@ -54,7 +121,7 @@ func (s *RecordRepo) GetAllRecords(ctx context.Context, userID int) ([]model.Rec
 	defer rows.Close()
 	for rows.Next() {
 		var r model.Record
-		err = rows.Scan(&r.ID, &r.UserID, &r.Type, &r.Metadata, &r.Data)
+		err = scanRecord(rows, &r)
 		if err != nil {
 			return nil, err
 		}
@@ -69,32 +136,37 @@ func (s *RecordRepo) GetAllRecords(ctx context.Context, userID int) ([]model.Rec
 	return records, nil
 }
 
-// DeleteRecord удаляет запись по ID.
+// DeleteRecord удаляет запись по ID. Личную запись может удалить только владелец,
+// запись коллекции — участник организации с правом записи.
 func (s *RecordRepo) DeleteRecord(ctx context.Context, userID int, idRecord string) error {
-	result, err := s.db.ExecContext(ctx, "DELETE FROM records WHERE id = $1 AND user_id = $2 ", idRecord, userID)
+	ctx, span := tracing.Start(ctx, "postgres.RecordRepo.DeleteRecord", dbSystem)
+	defer span.End()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM records WHERE id = $1 AND (
+		(collection_id IS NULL AND user_id = $2) OR collection_id IN (`+fmt.Sprintf(writableCollections, 2)+`))`, idRecord, userID)
 
 	if err != nil {
 		return err
 	}
 	rows, _ := result.RowsAffected()
 	if rows == 0 {
-		return fmt.Errorf("record not found: id=%s", idRecord)
+		return fmt.Errorf("delete record id=%s: %w", idRecord, model.ErrRecordNotFound)
 	}
 	return nil
 }
 
-// GetRecord возвращает запись по её ID.
+// GetRecord возвращает запись по её ID, если пользователь владеет ею,
+// получил к ней доступ или состоит в организации её коллекции.
 func (s *RecordRepo) GetRecord(ctx context.Context, userID int, idRecord string) (model.Record, error) {
+	ctx, span := tracing.Start(ctx, "postgres.RecordRepo.GetRecord", dbSystem)
+	defer span.End()
+
 	var record model.Record
-	row := s.db.QueryRowContext(ctx, `
-		SELECT id, type, metadata, data
-		FROM records
-		WHERE user_id = $1 AND id = $2
-		`, userID, idRecord)
-	err := row.Scan(&record.ID, &record.Type, &record.Metadata, &record.Data)
+	row := s.db.QueryRowContext(ctx, recordSelect+"WHERE r.id = $2 AND ("+personalRecords+" OR "+collectionRecords+")", userID, idRecord)
+	err := scanRecord(row, &record)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return model.Record{}, fmt.Errorf("record not found for user %v", userID)
+			return model.Record{}, fmt.Errorf("record not found for user %v: %w", userID, model.ErrRecordNotFound)
 		}
 		return model.Record{}, err
 	}
@@ -102,10 +174,16 @@ func (s *RecordRepo) GetRecord(ctx context.Context, userID int, idRecord string)
 	return record, nil
 }
 
-// UpdateRecord обновляет метаданные и/или данные записи.
-func (s *RecordRepo) UpdateRecord(ctx context.Context, userID int, idRecord string, record model.Record) error {
+// UpdateRecord обновляет метаданные и/или данные записи, а если expiry
+// не nil, то и срок действия — в одном запросе, чтобы запись не осталась
+// изменённой наполовину. Время изменения (updated_at) обновляется только
+// вместе с метаданными или данными. Обновление доступно владельцу, пользователям с правом записи
+// и участникам организации, роль которых допускает запись.
+func (s *RecordRepo) UpdateRecord(ctx context.Context, userID int, idRecord string, record model.Record, expiry *model.RecordExpiry) error {
+	ctx, span := tracing.Start(ctx, "postgres.RecordRepo.UpdateRecord", dbSystem)
+	defer span.End()
 
-	if record.Metadata == "" && record.Data == nil {
+	if record.Metadata == "" && record.Data == nil && expiry == nil {
 		return nil
 	}
 
@@ -128,7 +206,27 @@ func (s *RecordRepo) UpdateRecord(ctx context.Context, userID int, idRecord stri
 		idx++
 	}
 
-	query += fmt.Sprintf(", updated_at = NOW() WHERE id = $%d AND user_id = $%d", idx, idx+1)
+	if len(args) > 0 {
+		query += ", updated_at = NOW()"
+	}
+
+	if expiry != nil {
+		var expires any
+		if expiry.ExpiresAt != nil {
+			expires = expiry.ExpiresAt.UTC()
+		}
+		if len(args) > 0 {
+			query += ", "
+		}
+		query += fmt.Sprintf("expires_at = $%d, ephemeral = $%d", idx, idx+1)
+		args = append(args, expires, expiry.Ephemeral)
+		idx += 2
+	}
+
+	query += fmt.Sprintf(` WHERE id = $%d AND (
+		(collection_id IS NULL AND (user_id = $%d OR EXISTS (
+			SELECT 1 FROM record_keys k WHERE k.record_id = records.id AND k.user_id = $%d AND k.permission = 'write')))
+		OR collection_id IN (`+writableCollections+`))`, idx, idx+1, idx+1, idx+1)
 	args = append(args, idRecord, userID)
 	logger.Log.Debug("run query update", zap.String("query", query), zap.Any("args", args))
 
@@ -138,3 +236,140 @@ func (s *RecordRepo) UpdateRecord(ctx context.Context, userID int, idRecord stri
 	}
 	return nil
 }
+
+// DeleteExpiredRecords удаляет эфемерные записи, срок действия которых
+// наступил к моменту now, и возвращает их идентификаторы и владельцев.
+func (s *RecordRepo) DeleteExpiredRecords(ctx context.Context, now time.Time) ([]model.Record, error) {
+	ctx, span := tracing.Start(ctx, "postgres.RecordRepo.DeleteExpiredRecords", dbSystem)
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "DELETE FROM records WHERE ephemeral AND expires_at <= $1 RETURNING id, user_id, COALESCE(collection_id, 0)", now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete expired records: %w", err)
+	}
+	defer rows.Close()
+
+	deleted := make([]model.Record, 0)
+	for rows.Next() {
+		var r model.Record
+		if err := rows.Scan(&r.ID, &r.UserID, &r.CollectionID); err != nil {
+			return nil, err
+		}
+		deleted = append(deleted, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// ListRecordKeys возвращает ключи записи, выданные владельцу и получателям,
+// вместе с логинами и открытыми ключами пользователей.
+func (s *RecordRepo) ListRecordKeys(ctx context.Context, idRecord int64) ([]model.RecordKey, error) {
+	ctx, span := tracing.Start(ctx, "postgres.RecordRepo.ListRecordKeys", dbSystem)
+	defer span.End()
+
+	keys := make([]model.RecordKey, 0)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT k.record_id, k.user_id, u.login, COALESCE(u.public_key, ''), k.permission, k.wrapped_key
+		FROM record_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE k.record_id = $1
+		ORDER BY k.created_at
+		`, idRecord)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var k model.RecordKey
+		if err := rows.Scan(&k.RecordID, &k.UserID, &k.Login, &k.PublicKey, &k.Permission, &k.WrappedKey); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// PutRecordKey выдаёт пользователю ключ записи или обновляет его права.
+func (s *RecordRepo) PutRecordKey(ctx context.Context, key model.RecordKey) error {
+	ctx, span := tracing.Start(ctx, "postgres.RecordRepo.PutRecordKey", dbSystem)
+	defer span.End()
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO record_keys (record_id, user_id, permission, wrapped_key)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (record_id, user_id) DO UPDATE
+		SET permission = EXCLUDED.permission, wrapped_key = EXCLUDED.wrapped_key
+		`, key.RecordID, key.UserID, key.Permission, key.WrappedKey)
+	if err != nil {
+		return fmt.Errorf("failed to put record key: %w", err)
+	}
+	return nil
+}
+
+// ReplaceRecordKeys в одной транзакции перезаписывает зашифрованные данные записи
+// и полностью заменяет набор выданных ключей. Используется при переводе записи
+// на собственный ключ и при ротации ключа после отзыва доступа. Содержимое
+// записи не меняется, поэтому время изменения (updated_at) остаётся прежним.
+func (s *RecordRepo) ReplaceRecordKeys(ctx context.Context, idRecord int64, data []byte, keys []model.RecordKey) error {
+	ctx, span := tracing.Start(ctx, "postgres.RecordRepo.ReplaceRecordKeys", dbSystem)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "UPDATE records SET data = $1 WHERE id = $2", data, idRecord)
+	if err != nil {
+		return fmt.Errorf("failed to update record data: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return model.ErrRecordNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM record_keys WHERE record_id = $1", idRecord); err != nil {
+		return fmt.Errorf("failed to delete record keys: %w", err)
+	}
+
+	for _, key := range keys {
+		_, err := tx.ExecContext(ctx, "INSERT INTO record_keys (record_id, user_id, permission, wrapped_key) VALUES ($1, $2, $3, $4)",
+			idRecord, key.UserID, key.Permission, key.WrappedKey)
+		if err != nil {
+			return fmt.Errorf("failed to insert record key: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CountRecordsByType возвращает количество записей всех пользователей по типам.
+func (s *RecordRepo) CountRecordsByType(ctx context.Context) (map[model.RecordType]int, error) {
+	ctx, span := tracing.Start(ctx, "postgres.RecordRepo.CountRecordsByType", dbSystem)
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT type, COUNT(*) FROM records GROUP BY type")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[model.RecordType]int)
+	for rows.Next() {
+		var recordType model.RecordType
+		var count int
+		if err := rows.Scan(&recordType, &count); err != nil {
+			return nil, err
+		}
+		counts[recordType] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
@@ -0,0 +1,6 @@
+package postgres
+
+import "go.opentelemetry.io/otel/attribute"
+
+// dbSystem помечает спаны запросов репозиториев как обращения к PostgreSQL.
+var dbSystem = attribute.String("db.system", "postgresql")
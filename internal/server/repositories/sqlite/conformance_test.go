@@ -0,0 +1,19 @@
+package sqlite_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/repositories/conformance"
+	"github.com/fatkulllin/gophkeeper/internal/server/storage"
+)
+
+func TestConformance(t *testing.T) {
+	s, err := storage.Open(storage.SQLite, filepath.Join(t.TempDir(), "gophkeeper.db"))
+	if err != nil {
+		t.Fatalf("open storage: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	conformance.Run(t, s)
+}
@@ -0,0 +1,4 @@
+// Пакет sqlite реализует репозитории поверх встроенной базы SQLite.
+// Запросы повторяют репозитории postgres; отличаются схема, работа со временем
+// и сериализация цепочки журнала аудита.
+package sqlite
@@ -0,0 +1,270 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
+	"github.com/fatkulllin/gophkeeper/model"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// OrgRepo предоставляет методы для работы с организациями, их участниками
+// и коллекциями.
+type OrgRepo struct {
+	db *sql.DB
+}
+
+func NewOrgRepo(db *sql.DB) *OrgRepo {
+	return &OrgRepo{db: db}
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code()
+	return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+// CreateOrg в одной транзакции создаёт организацию, назначает создателя владельцем
+// и создаёт первую коллекцию с ключом, выданным владельцу.
+func (s *OrgRepo) CreateOrg(ctx context.Context, name string, collectionName string, ownerKey model.CollectionKey) (model.Organization, error) {
+	ctx, span := tracing.Start(ctx, "sqlite.OrgRepo.CreateOrg", dbSystem)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.Organization{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	org := model.Organization{Name: name, Role: model.RoleOwner}
+
+	err = tx.QueryRowContext(ctx, "INSERT INTO organizations (name) VALUES ($1) RETURNING id", name).Scan(&org.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return model.Organization{}, model.ErrOrgExists
+		}
+		return model.Organization{}, fmt.Errorf("failed to insert organization: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO org_members (org_id, user_id, role) VALUES ($1, $2, $3)", org.ID, ownerKey.UserID, model.RoleOwner)
+	if err != nil {
+		return model.Organization{}, fmt.Errorf("failed to insert owner: %w", err)
+	}
+
+	var collectionID int
+	err = tx.QueryRowContext(ctx, "INSERT INTO collections (org_id, name) VALUES ($1, $2) RETURNING id", org.ID, collectionName).Scan(&collectionID)
+	if err != nil {
+		return model.Organization{}, fmt.Errorf("failed to insert collection: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO collection_keys (collection_id, user_id, wrapped_key) VALUES ($1, $2, $3)", collectionID, ownerKey.UserID, ownerKey.WrappedKey)
+	if err != nil {
+		return model.Organization{}, fmt.Errorf("failed to insert collection key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.Organization{}, err
+	}
+	return org, nil
+}
+
+// GetOrgs возвращает организации пользователя вместе с его ролью.
+func (s *OrgRepo) GetOrgs(ctx context.Context, userID int) ([]model.Organization, error) {
+	ctx, span := tracing.Start(ctx, "sqlite.OrgRepo.GetOrgs", dbSystem)
+	defer span.End()
+
+	orgs := make([]model.Organization, 0)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT o.id, o.name, m.role
+		FROM organizations o
+		JOIN org_members m ON m.org_id = o.id
+		WHERE m.user_id = $1
+		ORDER BY o.id
+		`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var o model.Organization
+		if err := rows.Scan(&o.ID, &o.Name, &o.Role); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+// GetMemberRole возвращает роль пользователя в организации.
+func (s *OrgRepo) GetMemberRole(ctx context.Context, orgID int, userID int) (model.OrgRole, error) {
+	ctx, span := tracing.Start(ctx, "sqlite.OrgRepo.GetMemberRole", dbSystem)
+	defer span.End()
+
+	var role model.OrgRole
+	row := s.db.QueryRowContext(ctx, "SELECT role FROM org_members WHERE org_id = $1 AND user_id = $2", orgID, userID)
+	err := row.Scan(&role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", model.ErrNotOrgMember
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+// AddMember в одной транзакции добавляет участника организации
+// и выдаёт ему ключи коллекций.
+func (s *OrgRepo) AddMember(ctx context.Context, orgID int, userID int, role model.OrgRole, keys []model.CollectionKey) error {
+	ctx, span := tracing.Start(ctx, "sqlite.OrgRepo.AddMember", dbSystem)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO org_members (org_id, user_id, role) VALUES ($1, $2, $3)", orgID, userID, role)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return model.ErrMemberExists
+		}
+		return fmt.Errorf("failed to insert member: %w", err)
+	}
+
+	for _, key := range keys {
+		_, err := tx.ExecContext(ctx, "INSERT INTO collection_keys (collection_id, user_id, wrapped_key) VALUES ($1, $2, $3)", key.CollectionID, userID, key.WrappedKey)
+		if err != nil {
+			return fmt.Errorf("failed to insert collection key: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListMembers возвращает участников организации с их ролями и открытыми ключами.
+func (s *OrgRepo) ListMembers(ctx context.Context, orgID int) ([]model.OrgMember, error) {
+	ctx, span := tracing.Start(ctx, "sqlite.OrgRepo.ListMembers", dbSystem)
+	defer span.End()
+
+	members := make([]model.OrgMember, 0)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT u.id, u.login, m.role, COALESCE(u.public_key, '')
+		FROM org_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.org_id = $1
+		ORDER BY m.created_at, m.rowid
+		`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m model.OrgMember
+		if err := rows.Scan(&m.UserID, &m.Username, &m.Role, &m.PublicKey); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// CreateCollection в одной транзакции создаёт коллекцию и выдаёт её ключ участникам.
+func (s *OrgRepo) CreateCollection(ctx context.Context, orgID int, name string, keys []model.CollectionKey) (model.Collection, error) {
+	ctx, span := tracing.Start(ctx, "sqlite.OrgRepo.CreateCollection", dbSystem)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.Collection{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	collection := model.Collection{OrgID: orgID, Name: name}
+	err = tx.QueryRowContext(ctx, "INSERT INTO collections (org_id, name) VALUES ($1, $2) RETURNING id", orgID, name).Scan(&collection.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return model.Collection{}, model.ErrCollectionExists
+		}
+		return model.Collection{}, fmt.Errorf("failed to insert collection: %w", err)
+	}
+
+	for _, key := range keys {
+		_, err := tx.ExecContext(ctx, "INSERT INTO collection_keys (collection_id, user_id, wrapped_key) VALUES ($1, $2, $3)", collection.ID, key.UserID, key.WrappedKey)
+		if err != nil {
+			return model.Collection{}, fmt.Errorf("failed to insert collection key: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.Collection{}, err
+	}
+	return collection, nil
+}
+
+// ListCollections возвращает коллекции организации вместе с ключами, выданными пользователю.
+func (s *OrgRepo) ListCollections(ctx context.Context, orgID int, userID int) ([]model.Collection, error) {
+	ctx, span := tracing.Start(ctx, "sqlite.OrgRepo.ListCollections", dbSystem)
+	defer span.End()
+
+	collections := make([]model.Collection, 0)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT c.id, c.org_id, c.name, COALESCE(ck.wrapped_key, '')
+		FROM collections c
+		LEFT JOIN collection_keys ck ON ck.collection_id = c.id AND ck.user_id = $2
+		WHERE c.org_id = $1
+		ORDER BY c.id
+		`, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c model.Collection
+		if err := rows.Scan(&c.ID, &c.OrgID, &c.Name, &c.WrappedKey); err != nil {
+			return nil, err
+		}
+		collections = append(collections, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
+
+// GetCollectionKey возвращает ключ коллекции, выданный пользователю,
+// и его роль в организации коллекции.
+func (s *OrgRepo) GetCollectionKey(ctx context.Context, collectionID int, userID int) (model.CollectionKey, error) {
+	ctx, span := tracing.Start(ctx, "sqlite.OrgRepo.GetCollectionKey", dbSystem)
+	defer span.End()
+
+	key := model.CollectionKey{CollectionID: collectionID, UserID: userID}
+	row := s.db.QueryRowContext(ctx, `
+		SELECT c.org_id, m.role, ck.wrapped_key
+		FROM collections c
+		JOIN org_members m ON m.org_id = c.org_id AND m.user_id = $2
+		JOIN collection_keys ck ON ck.collection_id = c.id AND ck.user_id = $2
+		WHERE c.id = $1
+		`, collectionID, userID)
+	err := row.Scan(&key.OrgID, &key.Role, &key.WrappedKey)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.CollectionKey{}, model.ErrNotOrgMember
+		}
+		return model.CollectionKey{}, err
+	}
+	return key, nil
+}
@@ -0,0 +1,22 @@
+package sqlite
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// dbSystem помечает спаны запросов репозиториев как обращения к SQLite.
+var dbSystem = attribute.String("db.system", "sqlite")
+
+// now — текущее время UTC в формате столбцов TIMESTAMP схемы SQLite.
+const now = "strftime('%Y-%m-%d %H:%M:%f', 'now')"
+
+// timeLayout — формат хранения времени. SQLite сравнивает TIMESTAMP как строки,
+// поэтому время хранится в UTC с фиксированной шириной дробной части.
+const timeLayout = "2006-01-02 15:04:05.000000"
+
+// formatTime приводит время к формату timeLayout.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
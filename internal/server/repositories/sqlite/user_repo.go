@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+type UserRepo struct {
+	db *sql.DB
+}
+
+func NewUserRepo(db *sql.DB) *UserRepo {
+	return &UserRepo{db: db}
+}
+
+// ExistUser проверяет наличие пользователя по логину.
+// Возвращает true, если пользователь существует.
+func (s *UserRepo) ExistUser(ctx context.Context, user model.UserCredentials) (bool, error) {
+	ctx, span := tracing.Start(ctx, "sqlite.UserRepo.ExistUser", dbSystem)
+	defer span.End()
+
+	row := s.db.QueryRowContext(ctx, "SELECT login FROM users WHERE login = $1", user.Username)
+	var userScan string
+	err := row.Scan(&userScan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("check existing user: %w", err)
+	}
+	return true, nil
+}
+
+// CreateUser создаёт нового пользователя и возвращает его ID.
+func (s *UserRepo) CreateUser(ctx context.Context, user model.UserCredentials) (int, error) {
+	ctx, span := tracing.Start(ctx, "sqlite.UserRepo.CreateUser", dbSystem)
+	defer span.End()
+
+	var id int
+
+	row := s.db.QueryRowContext(ctx, "INSERT INTO users (login, password_hash, encrypted_key, public_key, encrypted_private_key) VALUES ($1, $2, $3, $4, $5) RETURNING id", user.Username, user.Password, user.EncryptedKey, user.PublicKey, user.EncryptedPrivateKey)
+
+	err := row.Scan(&id)
+
+	if err != nil {
+		return 0, fmt.Errorf("pg failed to insert new user: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetUser возвращает пользователя по логину.
+func (s *UserRepo) GetUser(ctx context.Context, user model.UserCredentials) (model.User, error) {
+	ctx, span := tracing.Start(ctx, "sqlite.UserRepo.GetUser", dbSystem)
+	defer span.End()
+
+	var foundUser model.User
+	row := s.db.QueryRowContext(ctx, "SELECT id, login, password_hash FROM users WHERE login = $1", user.Username)
+	err := row.Scan(&foundUser.ID, &foundUser.Login, &foundUser.PasswordHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.User{}, model.ErrUserNotFound
+		}
+		return model.User{}, err
+	}
+
+	return foundUser, nil
+}
+
+// GetEncryptedKeyUser возвращает зашифрованный ключ пользователя.
+func (s *UserRepo) GetEncryptedKeyUser(ctx context.Context, userID int) (string, error) {
+	ctx, span := tracing.Start(ctx, "sqlite.UserRepo.GetEncryptedKeyUser", dbSystem)
+	defer span.End()
+
+	var encryptedKey string
+	row := s.db.QueryRowContext(ctx, "SELECT encrypted_key FROM users WHERE id = $1;", userID)
+	err := row.Scan(&encryptedKey)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", model.ErrUserNotFound
+		}
+		return "", err
+	}
+	return encryptedKey, nil
+}
+
+// GetUserByLogin возвращает пользователя и его открытый ключ по логину.
+func (s *UserRepo) GetUserByLogin(ctx context.Context, login string) (model.User, error) {
+	ctx, span := tracing.Start(ctx, "sqlite.UserRepo.GetUserByLogin", dbSystem)
+	defer span.End()
+
+	var foundUser model.User
+	row := s.db.QueryRowContext(ctx, "SELECT id, login, COALESCE(public_key, '') FROM users WHERE login = $1", login)
+	err := row.Scan(&foundUser.ID, &foundUser.Login, &foundUser.PublicKey)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.User{}, model.ErrUserNotFound
+		}
+		return model.User{}, err
+	}
+	return foundUser, nil
+}
+
+// GetKeyPair возвращает открытый ключ пользователя и закрытый ключ,
+// зашифрованный master-key. Для пользователей без пары ключей возвращаются пустые строки.
+func (s *UserRepo) GetKeyPair(ctx context.Context, userID int) (string, string, error) {
+	ctx, span := tracing.Start(ctx, "sqlite.UserRepo.GetKeyPair", dbSystem)
+	defer span.End()
+
+	var publicKey, encryptedPrivateKey string
+	row := s.db.QueryRowContext(ctx, "SELECT COALESCE(public_key, ''), COALESCE(encrypted_private_key, '') FROM users WHERE id = $1", userID)
+	err := row.Scan(&publicKey, &encryptedPrivateKey)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", "", model.ErrUserNotFound
+		}
+		return "", "", err
+	}
+	return publicKey, encryptedPrivateKey, nil
+}
+
+// SetKeyPair сохраняет пару ключей пользователя, если у него её ещё нет,
+// и сообщает, сохранена ли она. Существующая пара не перезаписывается: ею
+// уже могут быть зашифрованы ключи записей и коллекций.
+func (s *UserRepo) SetKeyPair(ctx context.Context, userID int, publicKey string, encryptedPrivateKey string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "sqlite.UserRepo.SetKeyPair", dbSystem)
+	defer span.End()
+
+	result, err := s.db.ExecContext(ctx, "UPDATE users SET public_key = $1, encrypted_private_key = $2 WHERE id = $3 AND COALESCE(public_key, '') = ''", publicKey, encryptedPrivateKey, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update key pair: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to update key pair: %w", err)
+	}
+	return affected > 0, nil
+}
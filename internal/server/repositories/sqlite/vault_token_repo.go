@@ -0,0 +1,101 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// VaultTokenRepo хранит токены клиентов API Vault KV v2.
+type VaultTokenRepo struct {
+	db *sql.DB
+}
+
+func NewVaultTokenRepo(db *sql.DB) *VaultTokenRepo {
+	return &VaultTokenRepo{db: db}
+}
+
+// CreateVaultToken сохраняет токен с хешем hash и возвращает его ID.
+func (s *VaultTokenRepo) CreateVaultToken(ctx context.Context, token model.VaultToken, hash string) (int, error) {
+	ctx, span := tracing.Start(ctx, "sqlite.VaultTokenRepo.CreateVaultToken", dbSystem)
+	defer span.End()
+
+	var id int
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO vault_tokens (user_id, name, token_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+		`, token.UserID, token.Name, hash, formatTime(token.CreatedAt)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("insert vault token: %w", err)
+	}
+	return id, nil
+}
+
+// ListVaultTokens возвращает токены пользователя.
+func (s *VaultTokenRepo) ListVaultTokens(ctx context.Context, userID int) ([]model.VaultToken, error) {
+	ctx, span := tracing.Start(ctx, "sqlite.VaultTokenRepo.ListVaultTokens", dbSystem)
+	defer span.End()
+
+	tokens := make([]model.VaultToken, 0)
+	rows, err := s.db.QueryContext(ctx, "SELECT id, user_id, name, created_at FROM vault_tokens WHERE user_id = $1 ORDER BY id", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var token model.VaultToken
+		if err := rows.Scan(&token.ID, &token.UserID, &token.Name, &token.CreatedAt); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// GetVaultTokenByHash возвращает токен по хешу вместе с логином владельца.
+func (s *VaultTokenRepo) GetVaultTokenByHash(ctx context.Context, hash string) (model.VaultToken, error) {
+	ctx, span := tracing.Start(ctx, "sqlite.VaultTokenRepo.GetVaultTokenByHash", dbSystem)
+	defer span.End()
+
+	var token model.VaultToken
+	err := s.db.QueryRowContext(ctx, `
+		SELECT t.id, t.user_id, u.login, t.name, t.created_at
+		FROM vault_tokens t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.token_hash = $1
+		`, hash).Scan(&token.ID, &token.UserID, &token.UserLogin, &token.Name, &token.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.VaultToken{}, model.ErrVaultTokenNotFound
+		}
+		return model.VaultToken{}, err
+	}
+	return token, nil
+}
+
+// DeleteVaultToken удаляет токен пользователя.
+func (s *VaultTokenRepo) DeleteVaultToken(ctx context.Context, userID int, tokenID int) error {
+	ctx, span := tracing.Start(ctx, "sqlite.VaultTokenRepo.DeleteVaultToken", dbSystem)
+	defer span.End()
+
+	result, err := s.db.ExecContext(ctx, "DELETE FROM vault_tokens WHERE id = $1 AND user_id = $2", tokenID, userID)
+	if err != nil {
+		return fmt.Errorf("delete vault token: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete vault token: %w", err)
+	}
+	if n == 0 {
+		return model.ErrVaultTokenNotFound
+	}
+	return nil
+}
@@ -5,9 +5,14 @@ import (
 	"fmt"
 	"net"
 
+	"github.com/fatkulllin/gophkeeper/internal/server/apierror"
+	"github.com/fatkulllin/gophkeeper/internal/server/auth"
+	"github.com/fatkulllin/gophkeeper/internal/server/metrics"
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 )
@@ -18,13 +23,32 @@ func (server *Server) StartGRPC(ctx context.Context) error {
 		return fmt.Errorf("failed to listen on gRPC address: %w", err)
 	}
 
-	serverGRPC := grpc.NewServer()
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			tracing.UnaryServerInterceptor,
+			metrics.UnaryServerInterceptor,
+			auth.UnaryServerInterceptor(server.config.JWTSecret, server.devices),
+			// ближайший к обработчику: метрики и трассировка видят итоговый код
+			apierror.UnaryServerInterceptor,
+		),
+		grpc.ChainStreamInterceptor(
+			tracing.StreamServerInterceptor,
+			metrics.StreamServerInterceptor,
+			auth.StreamServerInterceptor(server.config.JWTSecret, server.devices),
+			apierror.StreamServerInterceptor,
+		),
+	}
+	if server.tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(server.tlsConfig)))
+	}
+
+	serverGRPC := grpc.NewServer(opts...)
 
 	healthServer := health.NewServer()
 	grpc_health_v1.RegisterHealthServer(serverGRPC, healthServer)
 	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
 
-	logger.Log.Info("gRPC server is running on", zap.String("address", server.config.GRPCAddress))
+	logger.Log.Info("gRPC server is running on", zap.String("address", server.config.GRPCAddress), zap.Bool("tls", server.tlsConfig != nil))
 
 	go func() {
 		<-ctx.Done()
@@ -4,6 +4,7 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"time"
@@ -12,22 +13,39 @@ import (
 	"github.com/fatkulllin/gophkeeper/internal/server/config"
 	"github.com/fatkulllin/gophkeeper/internal/server/handlers"
 	logging "github.com/fatkulllin/gophkeeper/internal/server/middleware/logger"
+	"github.com/fatkulllin/gophkeeper/internal/server/middleware/requestinfo"
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"go.uber.org/zap"
 )
 
+// methodList — метод LIST, которым клиенты Vault запрашивают списки ключей.
+const methodList = "LIST"
+
+func init() {
+	chi.RegisterMethod(methodList)
+}
+
 type Server struct {
 	config     config.Config
+	tlsConfig  *tls.Config
+	devices    auth.DeviceChecker
 	httpServer *http.Server
+	// metricsServer равен nil, если адрес метрик не задан.
+	metricsServer *http.Server
 }
 
 // NewRouter создаёт и настраивает HTTP-роутер с хендлерами и middleware.
 // Использует chi.Router и возвращает готовый маршрутизатор.
-func NewRouter(jwtSecret string, healthHandler *handlers.HealthHandler, loggerHandler *handlers.LoggerHandler, authHandler *handlers.AuthHandler, recordHandler *handlers.RecordHandler) chi.Router {
+// Если vaultHandler не nil, подключается API, совместимый с Vault KV v2,
+// и выпуск токенов для него.
+func NewRouter(jwtSecret string, orgResolver auth.OrgRoleResolver, devices auth.DeviceChecker, vaultTokens auth.VaultTokenAuthenticator, healthHandler *handlers.HealthHandler, loggerHandler *handlers.LoggerHandler, authHandler *handlers.AuthHandler, recordHandler *handlers.RecordHandler, orgHandler *handlers.OrgHandler, auditHandler *handlers.AuditHandler, deviceHandler *handlers.DeviceHandler, vaultHandler *handlers.VaultHandler, vaultTokenHandler *handlers.VaultTokenHandler) chi.Router {
 	r := chi.NewRouter()
+	r.Use(tracing.Middleware)
 	r.Use(logging.RequestLogger)
+	r.Use(requestinfo.RequestInfo)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Compress(5))
 
@@ -38,31 +56,73 @@ func NewRouter(jwtSecret string, healthHandler *handlers.HealthHandler, loggerHa
 	r.Post("/api/user/login", authHandler.UserLogin)
 	r.Post("/api/user/logout", authHandler.UserLogout)
 	r.Group(func(r chi.Router) {
-		r.Use(auth.AuthMiddleware(jwtSecret))
+		r.Use(auth.AuthMiddleware(jwtSecret, devices))
+		r.Use(auth.OrgMiddleware(orgResolver))
 		r.Post("/api/record", recordHandler.CreateRecord)
 		r.Get("/api/records", recordHandler.ListRecords)
 		r.Get("/api/records/{id}", recordHandler.GetRecord)
 		r.Delete("/api/records/{id}", recordHandler.Delete)
 		r.Patch("/api/records/{id}", recordHandler.Update)
-
+		r.Post("/api/records/{id}/shares", recordHandler.ShareRecord)
+		r.Get("/api/records/{id}/shares", recordHandler.ListShares)
+		r.Delete("/api/records/{id}/shares/{username}", recordHandler.UnshareRecord)
+		r.Post("/api/orgs", orgHandler.Create)
+		r.Get("/api/orgs", orgHandler.List)
+		r.Route("/api/orgs/{orgID}", func(r chi.Router) {
+			r.Post("/members", orgHandler.Invite)
+			r.Get("/members", orgHandler.Members)
+			r.Post("/collections", orgHandler.CreateCollection)
+			r.Get("/collections", orgHandler.Collections)
+		})
+		r.Get("/api/audit", auditHandler.List)
+		r.Post("/api/devices/enroll", deviceHandler.Enroll)
+		r.Get("/api/devices", deviceHandler.List)
+		r.Put("/api/devices/policy", deviceHandler.SetPolicy)
+		r.Delete("/api/devices/{id}", deviceHandler.Revoke)
+		if vaultHandler != nil {
+			r.Post("/api/vault/tokens", vaultTokenHandler.Create)
+			r.Get("/api/vault/tokens", vaultTokenHandler.List)
+			r.Delete("/api/vault/tokens/{id}", vaultTokenHandler.Revoke)
+		}
 	})
+	if vaultHandler != nil {
+		r.Group(func(r chi.Router) {
+			r.Use(auth.VaultTokenMiddleware(vaultTokens))
+			r.Get("/v1/sys/internal/ui/mounts/*", vaultHandler.MountInfo)
+			r.Get("/v1/auth/token/lookup-self", vaultHandler.LookupSelf)
+			r.Route("/v1/"+vaultHandler.Mount(), func(r chi.Router) {
+				r.Get("/data/*", vaultHandler.ReadSecret)
+				r.Get("/metadata/*", vaultHandler.ReadMetadata)
+				r.Method(methodList, "/metadata/*", http.HandlerFunc(vaultHandler.ListSecrets))
+			})
+		})
+	}
 
 	return r
 }
 
 // NewServer создаёт HTTP-сервер с заданной конфигурацией и зарегистрированными хендлерами.
-func NewServer(cfg config.Config, healthHandler *handlers.HealthHandler, loggerHandler *handlers.LoggerHandler, authHandler *handlers.AuthHandler, recordHandler *handlers.RecordHandler) *Server {
-	router := NewRouter(cfg.JWTSecret, healthHandler, loggerHandler, authHandler, recordHandler)
-	return &Server{
-		config: cfg,
+// Если tlsConfig не nil, HTTP и gRPC принимают только TLS-соединения.
+// Метрики Prometheus отдаются отдельным сервером на cfg.MetricsAddress.
+func NewServer(cfg config.Config, tlsConfig *tls.Config, orgResolver auth.OrgRoleResolver, devices auth.DeviceChecker, vaultTokens auth.VaultTokenAuthenticator, healthHandler *handlers.HealthHandler, loggerHandler *handlers.LoggerHandler, authHandler *handlers.AuthHandler, recordHandler *handlers.RecordHandler, orgHandler *handlers.OrgHandler, auditHandler *handlers.AuditHandler, deviceHandler *handlers.DeviceHandler, vaultHandler *handlers.VaultHandler, vaultTokenHandler *handlers.VaultTokenHandler) *Server {
+	router := NewRouter(cfg.JWTSecret, orgResolver, devices, vaultTokens, healthHandler, loggerHandler, authHandler, recordHandler, orgHandler, auditHandler, deviceHandler, vaultHandler, vaultTokenHandler)
+	server := &Server{
+		config:    cfg,
+		tlsConfig: tlsConfig,
+		devices:   devices,
 		httpServer: &http.Server{
 			Addr:         cfg.HTTPAddress,
 			Handler:      router,
 			ReadTimeout:  5 * time.Second,
 			WriteTimeout: 10 * time.Second,
 			IdleTimeout:  120 * time.Second,
+			TLSConfig:    tlsConfig,
 		},
 	}
+	if cfg.MetricsEnabled() {
+		server.metricsServer = newMetricsServer(cfg.MetricsAddress)
+	}
+	return server
 }
 
 // Start запускает HTTP-сервер и останавливает его при отмене контекста.
@@ -83,9 +143,15 @@ func (server *Server) Start(ctx context.Context) error {
 		}
 	}()
 
-	logger.Log.Info("HTTP server started on", zap.String("address", server.httpServer.Addr))
+	logger.Log.Info("HTTP server started on", zap.String("address", server.httpServer.Addr), zap.Bool("tls", server.tlsConfig != nil))
 
-	err := server.httpServer.ListenAndServe()
+	var err error
+	if server.tlsConfig != nil {
+		// сертификат берётся из TLSConfig.GetCertificate, поэтому пути не передаются
+		err = server.httpServer.ListenAndServeTLS("", "")
+	} else {
+		err = server.httpServer.ListenAndServe()
+	}
 	if err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("HTTP server error: %w", err)
 	}
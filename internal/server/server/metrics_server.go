@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// newMetricsServer создаёт HTTP-сервер, который отдаёт только /metrics.
+// Метрики раскрывают маршруты, число записей и попыток входа, поэтому
+// они не публикуются на адресе API и слушаются отдельно.
+func newMetricsServer(address string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.Handler())
+	return &http.Server{
+		Addr:         address,
+		Handler:      mux,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  120 * time.Second,
+	}
+}
+
+// StartMetrics запускает сервер метрик и останавливает его при отмене
+// контекста. Если адрес метрик не задан, сразу возвращает nil.
+func (server *Server) StartMetrics(ctx context.Context) error {
+	if server.metricsServer == nil {
+		return nil
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := server.metricsServer.Shutdown(shutdownCtx); err != nil {
+			logger.Log.Error("metrics server shutdown failed", zap.Error(err))
+		}
+	}()
+
+	logger.Log.Info("metrics server started on", zap.String("address", server.metricsServer.Addr))
+
+	if err := server.metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("metrics server error: %w", err)
+	}
+	return nil
+}
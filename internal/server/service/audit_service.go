@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/ctxkeys"
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultAuditLimit — число событий, возвращаемых по умолчанию.
+	DefaultAuditLimit = 100
+	// MaxAuditLimit — максимальное число событий в одном ответе.
+	MaxAuditLimit = 1000
+	// auditVerifyBatch — число событий, читаемых за один запрос при проверке цепочки.
+	auditVerifyBatch = 1000
+)
+
+// Auditor записывает события в журнал аудита.
+type Auditor interface {
+	Log(ctx context.Context, event model.AuditEvent)
+}
+
+// AuditService ведёт журнал аудита: добавляет события в цепочку,
+// выдаёт их пользователю и проверяет целостность цепочки.
+type AuditService struct {
+	repo AuditRepositories
+
+	// mu защищает checkpoint и не даёт проверкам идти параллельно.
+	mu         sync.Mutex
+	checkpoint auditCheckpoint
+	// broken — полная проверка нашла нарушение до checkpoint: пока оно
+	// не устранено, каждая проверка проходит цепочку от начала.
+	broken bool
+}
+
+// auditCheckpoint — последнее событие, до которого цепочка уже проверена.
+type auditCheckpoint struct {
+	id     int64
+	hash   string
+	events int
+}
+
+// NewAuditService создаёт новый сервис журнала аудита.
+func NewAuditService(repo AuditRepositories) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Log дополняет событие сведениями о клиенте и пользователе из контекста
+// и добавляет его в журнал. Ошибка записи журнала не прерывает операцию
+// пользователя и только логируется.
+func (s *AuditService) Log(ctx context.Context, event model.AuditEvent) {
+	ctx, span := tracing.Start(ctx, "AuditService.Log")
+	defer span.End()
+
+	if info, ok := ctx.Value(ctxkeys.RequestInfoKey).(model.RequestInfo); ok {
+		event.IP = info.IP
+		event.UserAgent = info.UserAgent
+	}
+	if claims, ok := ctx.Value(ctxkeys.UserContextKey).(model.Claims); ok && event.Login == "" {
+		event.Login = claims.UserLogin
+	}
+	// Postgres хранит время с точностью до микросекунд: обрезаем заранее,
+	// чтобы hash события совпадал при проверке цепочки.
+	event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+
+	if err := s.repo.AppendEvent(context.WithoutCancel(ctx), event); err != nil {
+		logger.Log.Error("failed to write audit event", zap.String("action", string(event.Action)), zap.Error(err))
+	}
+}
+
+// List возвращает события, выполненные пользователем или затрагивающие его записи.
+func (s *AuditService) List(ctx context.Context, userID int, filter model.AuditFilter) ([]model.AuditEvent, error) {
+	ctx, span := tracing.Start(ctx, "AuditService.List")
+	defer span.End()
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultAuditLimit
+	}
+	if filter.Limit > MaxAuditLimit {
+		filter.Limit = MaxAuditLimit
+	}
+	events, err := s.repo.ListEvents(ctx, userID, filter)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return nil, fmt.Errorf("list audit events: %w", err)
+	}
+	return events, nil
+}
+
+// Verify проверяет события, добавленные после предыдущей проверки: каждое
+// событие должно ссылаться на hash предыдущего, а его собственный hash не
+// должен измениться. События читаются пачками, проверенная часть цепочки
+// запоминается и при следующем вызове не перечитывается. Первая проверка
+// после запуска сервера проходит цепочку от начала. Изменения уже
+// проверенных событий обнаруживает VerifyFull; пока она видит нарушение,
+// Verify тоже проходит цепочку от начала.
+func (s *AuditService) Verify(ctx context.Context) (model.AuditVerification, error) {
+	ctx, span := tracing.Start(ctx, "AuditService.Verify")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.broken {
+		return s.verifyFull(ctx)
+	}
+	checkpoint, result, err := s.verifyChain(ctx, s.checkpoint, auditCheckpoint{})
+	s.checkpoint = checkpoint
+	return result, err
+}
+
+// VerifyFull проверяет цепочку от первого события, а не от запомненной
+// точки, и обнаруживает изменение и удаление событий, уже проверенных
+// раньше. Hash ранее проверенного события сверяется с запомненным: так
+// обнаруживается и цепочка, пересчитанная целиком после правки события.
+func (s *AuditService) VerifyFull(ctx context.Context) (model.AuditVerification, error) {
+	ctx, span := tracing.Start(ctx, "AuditService.VerifyFull")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.verifyFull(ctx)
+}
+
+// verifyFull выполняет полную проверку; вызывается под s.mu.
+func (s *AuditService) verifyFull(ctx context.Context) (model.AuditVerification, error) {
+	checkpoint, result, err := s.verifyChain(ctx, auditCheckpoint{}, s.checkpoint)
+	if err != nil {
+		return result, err
+	}
+	s.broken = !result.Valid
+	if s.broken {
+		// запомненная точка остаётся, чтобы нарушение не «исчезло» после
+		// пересчёта цепочки
+		return result, nil
+	}
+	s.checkpoint = checkpoint
+	logger.Log.Info("audit chain fully verified", zap.Int64("event id", checkpoint.id), zap.String("hash", checkpoint.hash))
+	return result, nil
+}
+
+// verifyChain проверяет события после from и возвращает последнее
+// событие, до которого цепочка цела. Если anchor задан, событие anchor.id
+// должно остаться в журнале с тем же hash.
+func (s *AuditService) verifyChain(ctx context.Context, from auditCheckpoint, anchor auditCheckpoint) (auditCheckpoint, model.AuditVerification, error) {
+	checkpoint := from
+	for {
+		events, err := s.repo.GetEventsAfter(ctx, checkpoint.id, auditVerifyBatch)
+		if err != nil {
+			logger.Log.Error("", zap.Error(err))
+			return checkpoint, model.AuditVerification{}, fmt.Errorf("get audit events after %d: %w", checkpoint.id, err)
+		}
+
+		for _, event := range events {
+			if event.PrevHash != checkpoint.hash || event.ChainHash(checkpoint.hash) != event.Hash ||
+				event.ID == anchor.id && event.Hash != anchor.hash ||
+				checkpoint.id < anchor.id && event.ID > anchor.id {
+				logger.Log.Warn("audit chain broken", zap.Int64("event id", event.ID))
+				return checkpoint, model.AuditVerification{Valid: false, Events: checkpoint.events, BrokenAt: event.ID}, nil
+			}
+			checkpoint = auditCheckpoint{id: event.ID, hash: event.Hash, events: checkpoint.events + 1}
+		}
+
+		if len(events) < auditVerifyBatch {
+			if checkpoint.id < anchor.id {
+				logger.Log.Warn("audit chain truncated", zap.Int64("event id", anchor.id))
+				return checkpoint, model.AuditVerification{Valid: false, Events: checkpoint.events, BrokenAt: anchor.id}, nil
+			}
+			return checkpoint, model.AuditVerification{Valid: true, Events: checkpoint.events}, nil
+		}
+	}
+}
+
+// auditEvent формирует событие журнала по итогам операции над записью.
+func auditEvent(action model.AuditAction, userID int, idRecord string, err error) model.AuditEvent {
+	recordID, _ := strconv.ParseInt(idRecord, 10, 64)
+	return model.AuditEvent{
+		UserID:   userID,
+		Action:   action,
+		RecordID: recordID,
+		Result:   auditResult(err),
+	}
+}
+
+// auditResult определяет итог операции по возвращённой ошибке.
+func auditResult(err error) model.AuditResult {
+	if err == nil {
+		return model.AuditSuccess
+	}
+	switch model.AsError(err).Code {
+	case model.CodeForbidden, model.CodeUnauthorized:
+		return model.AuditDenied
+	default:
+		return model.AuditFailure
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/ctxkeys"
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/cryptoutil"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// DeviceService выпускает сертификаты устройств, ведёт их список и CRL
+// и проверяет сертификаты, к которым привязаны токены.
+type DeviceService struct {
+	repo  DeviceRepositories
+	ca    DeviceCA
+	audit Auditor
+}
+
+// NewDeviceService создаёт новый сервис устройств.
+// Если ca равен nil, выпуск сертификатов отключён.
+func NewDeviceService(repo DeviceRepositories, ca DeviceCA, audit Auditor) *DeviceService {
+	return &DeviceService{repo: repo, ca: ca, audit: audit}
+}
+
+// Enroll выпускает сертификат устройства по CSR в формате PEM.
+// Подпись CSR проверяется, субъект сертификата задаётся по логину пользователя.
+func (s *DeviceService) Enroll(ctx context.Context, userID int, login string, input model.EnrollInput) (_ model.EnrollResponse, err error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.Enroll")
+	defer span.End()
+
+	defer func() { s.audit.Log(ctx, auditEvent(model.AuditDeviceEnroll, userID, "", err)) }()
+
+	if s.ca == nil {
+		return model.EnrollResponse{}, model.ErrDeviceAuthDisabled
+	}
+
+	block, _ := pem.Decode([]byte(input.CSR))
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return model.EnrollResponse{}, model.ErrInvalidCSR
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return model.EnrollResponse{}, fmt.Errorf("%w: %v", model.ErrInvalidCSR, err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return model.EnrollResponse{}, fmt.Errorf("%w: %v", model.ErrInvalidCSR, err)
+	}
+
+	cert, err := s.ca.Sign(csr, login)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return model.EnrollResponse{}, err
+	}
+
+	device := model.Device{
+		UserID:     userID,
+		Name:       input.Name,
+		Serial:     hex.EncodeToString(cert.SerialNumber.Bytes()),
+		Thumbprint: cryptoutil.CertThumbprint(cert),
+		CreatedAt:  cert.NotBefore,
+		ExpiresAt:  cert.NotAfter,
+	}
+	device.ID, err = s.repo.CreateDevice(ctx, device)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return model.EnrollResponse{}, fmt.Errorf("create device: %w", err)
+	}
+
+	logger.Log.Debug("device enrolled", zap.Int("device id", device.ID), zap.String("serial", device.Serial))
+	return model.EnrollResponse{
+		Device:        device,
+		Certificate:   string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})),
+		CACertificate: string(s.ca.CertPEM()),
+	}, nil
+}
+
+// List возвращает устройства пользователя.
+func (s *DeviceService) List(ctx context.Context, userID int) ([]model.Device, error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.List")
+	defer span.End()
+
+	devices, err := s.repo.ListDevices(ctx, userID)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return nil, fmt.Errorf("list devices: %w", err)
+	}
+	return devices, nil
+}
+
+// Revoke отзывает сертификат устройства: токены, привязанные к нему,
+// перестают приниматься, вход с ним больше не привязывает сессию.
+func (s *DeviceService) Revoke(ctx context.Context, userID int, deviceID int) (err error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.Revoke")
+	defer span.End()
+
+	defer func() { s.audit.Log(ctx, auditEvent(model.AuditDeviceRevoke, userID, "", err)) }()
+
+	if err := s.repo.RevokeDevice(ctx, userID, deviceID); err != nil {
+		return fmt.Errorf("revoke device %d: %w", deviceID, err)
+	}
+	return nil
+}
+
+// SetPolicy включает или отключает обязательный вход с сертификатом устройства.
+// Включить требование можно только с зарегистрированного устройства,
+// чтобы пользователь не потерял доступ к хранилищу.
+func (s *DeviceService) SetPolicy(ctx context.Context, userID int, policy model.DevicePolicy) error {
+	ctx, span := tracing.Start(ctx, "DeviceService.SetPolicy")
+	defer span.End()
+
+	if policy.RequireDevice {
+		if _, err := presentedDevice(ctx, s.repo, userID); err != nil {
+			return model.ErrDeviceRequired
+		}
+	}
+	return s.repo.SetRequireDevice(ctx, userID, policy.RequireDevice)
+}
+
+// CheckDevice проверяет, что сертификат с отпечатком thumbprint выпущен
+// сервером и не отозван.
+func (s *DeviceService) CheckDevice(ctx context.Context, thumbprint string) error {
+	ctx, span := tracing.Start(ctx, "DeviceService.CheckDevice")
+	defer span.End()
+
+	device, err := s.repo.GetDeviceByThumbprint(ctx, thumbprint)
+	if err != nil {
+		return err
+	}
+	if device.RevokedAt != nil {
+		return model.ErrDeviceRevoked
+	}
+	return nil
+}
+
+// RequireDevice сообщает, обязателен ли пользователю вход с сертификатом устройства.
+func (s *DeviceService) RequireDevice(ctx context.Context, userID int) (bool, error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.RequireDevice")
+	defer span.End()
+
+	return s.repo.GetRequireDevice(ctx, userID)
+}
+
+// presentedDevice возвращает действующее устройство пользователя,
+// сертификат которого предъявлен в текущем соединении.
+func presentedDevice(ctx context.Context, repo DeviceRepositories, userID int) (model.Device, error) {
+	info, ok := ctx.Value(ctxkeys.RequestInfoKey).(model.RequestInfo)
+	if !ok || info.CertThumbprint == "" {
+		return model.Device{}, model.ErrDeviceNotFound
+	}
+
+	device, err := repo.GetDeviceByThumbprint(ctx, info.CertThumbprint)
+	if err != nil {
+		return model.Device{}, err
+	}
+	if device.UserID != userID {
+		return model.Device{}, model.ErrDeviceNotFound
+	}
+	if device.RevokedAt != nil {
+		return model.Device{}, model.ErrDeviceRevoked
+	}
+	return device, nil
+}
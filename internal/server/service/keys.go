@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
+	"github.com/fatkulllin/gophkeeper/pkg/cryptoutil"
+)
+
+// newKeyPair генерирует пару ключей X25519 и возвращает открытый ключ
+// и закрытый ключ, зашифрованный master-key, в виде base64-строк.
+func newKeyPair(cryptoUtil CryptoUtil) (string, string, error) {
+	publicKey, privateKey, err := cryptoutil.GenerateKeyPair()
+	if err != nil {
+		return "", "", err
+	}
+	encryptedPrivateKey, err := cryptoUtil.EncryptWithMasterKey(privateKey)
+	if err != nil {
+		return "", "", fmt.Errorf("encrypt private key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(publicKey), encryptedPrivateKey, nil
+}
+
+// ensureKeyPair возвращает открытый и закрытый ключи пользователя.
+// Пользователям, зарегистрированным до появления общего доступа,
+// пара ключей создаётся при первом обращении; при гонке двух первых
+// обращений сохраняется только одна пара.
+func ensureKeyPair(ctx context.Context, repo UserRepositories, cryptoUtil CryptoUtil, userID int) ([]byte, []byte, error) {
+	publicKey, encryptedPrivateKey, err := repo.GetKeyPair(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get key pair: %w", err)
+	}
+
+	if publicKey == "" || encryptedPrivateKey == "" {
+		publicKey, encryptedPrivateKey, err = newKeyPair(cryptoUtil)
+		if err != nil {
+			return nil, nil, err
+		}
+		stored, err := repo.SetKeyPair(ctx, userID, publicKey, encryptedPrivateKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		// пару уже создал параллельный запрос: используется сохранённая
+		if !stored {
+			publicKey, encryptedPrivateKey, err = repo.GetKeyPair(ctx, userID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("get key pair: %w", err)
+			}
+		}
+	}
+
+	rawPublicKey, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decode public key: %w", err)
+	}
+	privateKey, err := unwrapWithMasterKey(ctx, cryptoUtil, encryptedPrivateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decrypt private key: %w", err)
+	}
+	return rawPublicKey, privateKey, nil
+}
+
+// unwrapWithMasterKey расшифровывает ключ master-key в отдельном спане трассировки.
+func unwrapWithMasterKey(ctx context.Context, cryptoUtil CryptoUtil, encryptedKey string) ([]byte, error) {
+	_, span := tracing.Start(ctx, "masterkey.Unwrap")
+	defer span.End()
+	return cryptoUtil.DecryptWithMasterKey(encryptedKey)
+}
+
+// publicKeyOf возвращает открытый ключ пользователя, при необходимости создавая пару ключей.
+func publicKeyOf(ctx context.Context, repo UserRepositories, cryptoUtil CryptoUtil, userID int) ([]byte, error) {
+	publicKey, _, err := ensureKeyPair(ctx, repo, cryptoUtil, userID)
+	return publicKey, err
+}
+
+// openKey расшифровывает ключ в base64, зашифрованный открытым ключом пользователя.
+func openKey(wrappedKey string, privateKey []byte) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(wrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode wrapped key: %w", err)
+	}
+	return cryptoutil.Open(raw, privateKey)
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/cryptoutil"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// DefaultCollectionName — имя коллекции, создаваемой вместе с организацией.
+const DefaultCollectionName = "default"
+
+// OrgService отвечает за организации, их участников и коллекции.
+// Ключ каждой коллекции шифруется открытыми ключами всех участников организации.
+type OrgService struct {
+	orgRepo    OrgRepositories
+	userRepo   UserRepositories
+	cryptoUtil CryptoUtil
+	audit      Auditor
+}
+
+// NewOrgService создаёт новый сервис для работы с организациями.
+func NewOrgService(orgRepo OrgRepositories, userRepo UserRepositories, cryptoUtil CryptoUtil, audit Auditor) *OrgService {
+	return &OrgService{
+		orgRepo:    orgRepo,
+		userRepo:   userRepo,
+		cryptoUtil: cryptoUtil,
+		audit:      audit,
+	}
+}
+
+// Create создаёт организацию с коллекцией по умолчанию.
+// Создатель становится владельцем организации.
+func (s *OrgService) Create(ctx context.Context, userID int, input model.OrgInput) (_ model.Organization, err error) {
+	ctx, span := tracing.Start(ctx, "OrgService.Create")
+	defer span.End()
+
+	defer func() { s.audit.Log(ctx, auditEvent(model.AuditOrgCreate, userID, "", err)) }()
+
+	collectionKey, err := cryptoutil.GenerateRandom(32)
+	if err != nil {
+		return model.Organization{}, err
+	}
+
+	wrappedKey, err := s.wrapFor(ctx, collectionKey, userID)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return model.Organization{}, err
+	}
+
+	org, err := s.orgRepo.CreateOrg(ctx, input.Name, DefaultCollectionName, model.CollectionKey{
+		UserID:     userID,
+		WrappedKey: wrappedKey,
+	})
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return model.Organization{}, fmt.Errorf("create organization: %w", err)
+	}
+
+	logger.Log.Debug("organization created", zap.Int("org id", org.ID), zap.String("name", org.Name))
+	return org, nil
+}
+
+// List возвращает организации, в которых состоит пользователь.
+func (s *OrgService) List(ctx context.Context, userID int) ([]model.Organization, error) {
+	ctx, span := tracing.Start(ctx, "OrgService.List")
+	defer span.End()
+
+	orgs, err := s.orgRepo.GetOrgs(ctx, userID)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return nil, fmt.Errorf("get organizations: %w", err)
+	}
+	return orgs, nil
+}
+
+// MemberRole возвращает роль пользователя в организации.
+func (s *OrgService) MemberRole(ctx context.Context, orgID int, userID int) (model.OrgRole, error) {
+	ctx, span := tracing.Start(ctx, "OrgService.MemberRole")
+	defer span.End()
+
+	return s.orgRepo.GetMemberRole(ctx, orgID, userID)
+}
+
+// Invite добавляет пользователя в организацию с указанной ролью и выдаёт ему
+// ключи всех коллекций. Приглашать могут владельцы и администраторы,
+// назначать владельцев — только владельцы.
+func (s *OrgService) Invite(ctx context.Context, userID int, orgID int, role model.OrgRole, input model.InviteInput) (err error) {
+	ctx, span := tracing.Start(ctx, "OrgService.Invite")
+	defer span.End()
+
+	defer func() { s.audit.Log(ctx, auditEvent(model.AuditOrgInvite, userID, "", err)) }()
+
+	if !role.CanManage() || (input.Role == model.RoleOwner && role != model.RoleOwner) {
+		return model.ErrInsufficientRole
+	}
+
+	invitee, err := s.userRepo.GetUserByLogin(ctx, input.Username)
+	if err != nil {
+		return fmt.Errorf("get invitee: %w", err)
+	}
+
+	collections, err := s.orgRepo.ListCollections(ctx, orgID, userID)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return fmt.Errorf("list collections: %w", err)
+	}
+
+	_, privateKey, err := ensureKeyPair(ctx, s.userRepo, s.cryptoUtil, userID)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return err
+	}
+
+	keys := make([]model.CollectionKey, 0, len(collections))
+	for _, collection := range collections {
+		if collection.WrappedKey == "" {
+			continue
+		}
+		collectionKey, err := openKey(collection.WrappedKey, privateKey)
+		if err != nil {
+			return fmt.Errorf("open key of collection %d: %w", collection.ID, err)
+		}
+		wrappedKey, err := s.wrapFor(ctx, collectionKey, invitee.ID)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, model.CollectionKey{
+			CollectionID: collection.ID,
+			OrgID:        orgID,
+			UserID:       invitee.ID,
+			WrappedKey:   wrappedKey,
+		})
+	}
+
+	if err := s.orgRepo.AddMember(ctx, orgID, invitee.ID, input.Role, keys); err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return fmt.Errorf("add member: %w", err)
+	}
+
+	logger.Log.Debug("member invited", zap.Int("org id", orgID), zap.String("login", invitee.Login), zap.String("role", string(input.Role)))
+	return nil
+}
+
+// Members возвращает участников организации.
+func (s *OrgService) Members(ctx context.Context, orgID int) ([]model.OrgMember, error) {
+	ctx, span := tracing.Start(ctx, "OrgService.Members")
+	defer span.End()
+
+	members, err := s.orgRepo.ListMembers(ctx, orgID)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return nil, fmt.Errorf("list members: %w", err)
+	}
+	return members, nil
+}
+
+// CreateCollection создаёт коллекцию организации и выдаёт её ключ всем участникам.
+// Доступно владельцам и администраторам.
+func (s *OrgService) CreateCollection(ctx context.Context, orgID int, role model.OrgRole, input model.CollectionInput) (model.Collection, error) {
+	ctx, span := tracing.Start(ctx, "OrgService.CreateCollection")
+	defer span.End()
+
+	if !role.CanManage() {
+		return model.Collection{}, model.ErrInsufficientRole
+	}
+
+	members, err := s.orgRepo.ListMembers(ctx, orgID)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return model.Collection{}, fmt.Errorf("list members: %w", err)
+	}
+
+	collectionKey, err := cryptoutil.GenerateRandom(32)
+	if err != nil {
+		return model.Collection{}, err
+	}
+
+	keys := make([]model.CollectionKey, 0, len(members))
+	for _, member := range members {
+		wrappedKey, err := s.wrapFor(ctx, collectionKey, member.UserID)
+		if err != nil {
+			return model.Collection{}, err
+		}
+		keys = append(keys, model.CollectionKey{
+			OrgID:      orgID,
+			UserID:     member.UserID,
+			WrappedKey: wrappedKey,
+		})
+	}
+
+	collection, err := s.orgRepo.CreateCollection(ctx, orgID, input.Name, keys)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return model.Collection{}, fmt.Errorf("create collection: %w", err)
+	}
+	return collection, nil
+}
+
+// Collections возвращает коллекции организации.
+func (s *OrgService) Collections(ctx context.Context, userID int, orgID int) ([]model.Collection, error) {
+	ctx, span := tracing.Start(ctx, "OrgService.Collections")
+	defer span.End()
+
+	collections, err := s.orgRepo.ListCollections(ctx, orgID, userID)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return nil, fmt.Errorf("list collections: %w", err)
+	}
+	return collections, nil
+}
+
+// wrapFor шифрует ключ открытым ключом пользователя и возвращает результат в base64.
+func (s *OrgService) wrapFor(ctx context.Context, key []byte, userID int) (string, error) {
+	publicKey, err := publicKeyOf(ctx, s.userRepo, s.cryptoUtil, userID)
+	if err != nil {
+		return "", err
+	}
+	wrappedKey, err := cryptoutil.Seal(key, publicKey)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(wrappedKey), nil
+}
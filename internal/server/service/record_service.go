@@ -4,10 +4,14 @@ package service
 
 import (
 	"context"
-	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
 	"github.com/fatkulllin/gophkeeper/model"
 	"github.com/fatkulllin/gophkeeper/pkg/cryptoutil"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
@@ -20,38 +24,71 @@ import (
 type RecordService struct {
 	recordRepo RecordRepositories
 	userRepo   UserRepositories
+	orgRepo    OrgRepositories
 	cryptoUtil CryptoUtil
+	audit      Auditor
 }
 
 // NewRecordService создаёт новый сервис для работы с записями.
-func NewRecordService(recordRepo RecordRepositories, userRepo UserRepositories, cryptoUtil CryptoUtil) *RecordService {
+func NewRecordService(recordRepo RecordRepositories, userRepo UserRepositories, orgRepo OrgRepositories, cryptoUtil CryptoUtil, audit Auditor) *RecordService {
 	return &RecordService{
 		recordRepo: recordRepo,
 		userRepo:   userRepo,
+		orgRepo:    orgRepo,
 		cryptoUtil: cryptoUtil,
+		audit:      audit,
 	}
 }
 
-func (s *RecordService) Create(ctx context.Context, userID int, input model.RecordInput) error {
+// Create создаёт запись. Если задана организация или коллекция, запись
+// создаётся в коллекции (по умолчанию — в первой коллекции организации)
+// и шифруется ключом коллекции.
+func (s *RecordService) Create(ctx context.Context, userID int, orgID int, input model.RecordInput) (err error) {
+	ctx, span := tracing.Start(ctx, "RecordService.Create")
+	defer span.End()
+
+	defer func() { s.audit.Log(ctx, auditEvent(model.AuditRecordCreate, userID, "", err)) }()
+
+	if input.Ephemeral && input.ExpiresAt == nil {
+		return model.ErrEphemeralWithoutExpiry
+	}
+
 	record := model.Record{
-		UserID:   userID,
-		Type:     input.Type,
-		Metadata: input.Metadata,
+		UserID:    userID,
+		Type:      input.Type,
+		Metadata:  input.Metadata,
+		ExpiresAt: input.ExpiresAt,
+		Ephemeral: input.Ephemeral,
 	}
 
-	encryptedKey, err := s.userRepo.GetEncryptedKeyUser(ctx, userID)
-	if err != nil {
-		logger.Log.Error("", zap.Error(err))
-		return err
+	var recordKey []byte
+	if orgID != 0 || input.CollectionID != 0 {
+		collectionID, collectionKey, err := s.collectionKey(ctx, userID, orgID, input.CollectionID)
+		if err != nil {
+			logger.Log.Error("", zap.Error(err))
+			return err
+		}
+		record.CollectionID = collectionID
+		recordKey = collectionKey
+	} else {
+		encryptedKey, err := s.userRepo.GetEncryptedKeyUser(ctx, userID)
+		if err != nil {
+			logger.Log.Error("", zap.Error(err))
+			return err
+		}
+
+		recordKey, err = unwrapWithMasterKey(ctx, s.cryptoUtil, encryptedKey)
+		if err != nil {
+			logger.Log.Error("", zap.Error(err))
+			return err
+		}
 	}
 
-	decryptUserKey, err := s.cryptoUtil.DecryptWithMasterKey(encryptedKey)
+	data, err := stampPasswordChange(input.Type, input.Data, nil, time.Now())
 	if err != nil {
-		logger.Log.Error("", zap.Error(err))
 		return err
 	}
-
-	encryptData, err := cryptoutil.Encrypt(input.Data, decryptUserKey)
+	encryptData, err := cryptoutil.Encrypt(data, recordKey)
 	if err != nil {
 		logger.Log.Error("", zap.Error(err))
 		return err
@@ -69,55 +106,99 @@ func (s *RecordService) Create(ctx context.Context, userID int, input model.Reco
 	return nil
 }
 
-func (s *RecordService) GetAll(ctx context.Context, userID int) ([]model.Record, error) {
+// GetAll возвращает личные и общие записи пользователя,
+// а при заданной организации — записи её коллекций.
+// Фильтр по сроку действия применяется к уже выбранным записям.
+func (s *RecordService) GetAll(ctx context.Context, userID int, orgID int, filter model.RecordFilter) (_ []model.Record, err error) {
+	ctx, span := tracing.Start(ctx, "RecordService.GetAll")
+	defer span.End()
 
-	records, err := s.recordRepo.GetAllRecords(ctx, userID)
+	var records []model.Record
+	defer func() { s.audit.Log(ctx, auditEvent(model.AuditRecordList, userID, "", err)) }()
+
+	if orgID != 0 {
+		records, err = s.recordRepo.GetOrgRecords(ctx, userID, orgID)
+	} else {
+		records, err = s.recordRepo.GetAllRecords(ctx, userID)
+	}
 	if err != nil {
 		logger.Log.Error("error", zap.Error(err))
 		return nil, fmt.Errorf("get records: %w", err)
 	}
+	if filter.ExpiringWithin > 0 {
+		records = expiringWithin(records, time.Now().Add(filter.ExpiringWithin))
+	}
 	logger.Log.Debug("records", zap.Any("records", records))
 	return records, nil
 }
 
-func (s *RecordService) Get(ctx context.Context, userID int, idRecord string) (model.RecordResponse, error) {
-	record, err := s.recordRepo.GetRecord(ctx, userID, idRecord)
+// expiringWithin оставляет записи со сроком действия не позже deadline.
+func expiringWithin(records []model.Record, deadline time.Time) []model.Record {
+	expiring := make([]model.Record, 0)
+	for _, r := range records {
+		if r.ExpiresAt != nil && !r.ExpiresAt.After(deadline) {
+			expiring = append(expiring, r)
+		}
+	}
+	return expiring
+}
 
-	if err != nil {
-		logger.Log.Error("get record error", zap.Error(err))
-		return model.RecordResponse{}, fmt.Errorf("get record: %w", err)
+func (s *RecordService) Get(ctx context.Context, userID int, idRecord string) (_ model.RecordResponse, err error) {
+	ctx, span := tracing.Start(ctx, "RecordService.Get")
+	defer span.End()
+
+	defer func() { s.audit.Log(ctx, auditEvent(model.AuditRecordRead, userID, idRecord, err)) }()
+
+	if err := validRecordID(idRecord); err != nil {
+		return model.RecordResponse{}, err
 	}
 
-	encryptedKey, err := s.userRepo.GetEncryptedKeyUser(ctx, userID)
+	record, err := s.recordRepo.GetRecord(ctx, userID, idRecord)
+
 	if err != nil {
-		logger.Log.Error("", zap.Error(err))
-		return model.RecordResponse{}, err
+		logger.Log.Debug("get record error", zap.Error(err))
+		return model.RecordResponse{}, fmt.Errorf("get record: %w", err)
 	}
 
-	decryptUserKey, err := s.cryptoUtil.DecryptWithMasterKey(encryptedKey)
+	recordKey, err := s.recordKey(ctx, userID, record)
 	if err != nil {
 		logger.Log.Error("", zap.Error(err))
 		return model.RecordResponse{}, err
 	}
-	decryptData, err := cryptoutil.Decrypt(record.Data, decryptUserKey)
+	decryptData, err := cryptoutil.Decrypt(record.Data, recordKey)
 	if err != nil {
 		logger.Log.Error("", zap.Error(err))
 		return model.RecordResponse{}, err
 	}
 
 	return model.RecordResponse{
-		ID:       record.ID,
-		Type:     record.Type,
-		Metadata: record.Metadata,
-		Data:     decryptData,
+		ID:           record.ID,
+		CollectionID: record.CollectionID,
+		OrgID:        record.OrgID,
+		Type:         record.Type,
+		Metadata:     record.Metadata,
+		Data:         decryptData,
+		Permission:   record.Permission,
+		UpdatedAt:    record.UpdatedAt,
+		ExpiresAt:    record.ExpiresAt,
+		Ephemeral:    record.Ephemeral,
 	}, nil
 }
 
-func (s *RecordService) Delete(ctx context.Context, userID int, idRecord string) error {
+func (s *RecordService) Delete(ctx context.Context, userID int, idRecord string) (err error) {
+	ctx, span := tracing.Start(ctx, "RecordService.Delete")
+	defer span.End()
+
+	defer func() { s.audit.Log(ctx, auditEvent(model.AuditRecordDelete, userID, idRecord, err)) }()
+
+	if err := validRecordID(idRecord); err != nil {
+		return err
+	}
+
 	if err := s.recordRepo.DeleteRecord(ctx, userID, idRecord); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, model.ErrRecordNotFound) {
 			logger.Log.Debug("no rows for delete", zap.String("record id", idRecord), zap.Int("user id", userID))
-			return sql.ErrNoRows
+			return err
 		}
 		logger.Log.Error("", zap.Error(err))
 		return fmt.Errorf("delete record: %w", err)
@@ -125,26 +206,50 @@ func (s *RecordService) Delete(ctx context.Context, userID int, idRecord string)
 	return nil
 }
 
-func (s *RecordService) Update(ctx context.Context, userID int, idRecord string, input model.RecordUpdateInput) error {
+func (s *RecordService) Update(ctx context.Context, userID int, idRecord string, input model.RecordUpdateInput) (err error) {
+	ctx, span := tracing.Start(ctx, "RecordService.Update")
+	defer span.End()
+
+	defer func() { s.audit.Log(ctx, auditEvent(model.AuditRecordUpdate, userID, idRecord, err)) }()
+
 	var record model.Record
-	if input.Metadata == nil && input.Data == nil {
-		return errors.New("nothing to update: both metadata and data are nil")
+	if input.Empty() {
+		return model.ErrNothingToUpdate
+	}
+	if err := validRecordID(idRecord); err != nil {
+		return err
+	}
+
+	current, err := s.recordRepo.GetRecord(ctx, userID, idRecord)
+	if err != nil {
+		logger.Log.Error("get record error", zap.Error(err))
+		return fmt.Errorf("get record: %w", err)
+	}
+
+	if current.Permission == model.PermissionRead {
+		return model.ErrReadOnly
 	}
 
 	if input.Data != nil {
-		encryptedKey, err := s.userRepo.GetEncryptedKeyUser(ctx, userID)
+		recordKey, err := s.recordKey(ctx, userID, current)
 		if err != nil {
 			logger.Log.Error("", zap.Error(err))
 			return err
 		}
 
-		decryptUserKey, err := s.cryptoUtil.DecryptWithMasterKey(encryptedKey)
+		var previous []byte
+		if current.Type == model.TypeLoginPassword {
+			if previous, err = cryptoutil.Decrypt(current.Data, recordKey); err != nil {
+				logger.Log.Error("", zap.Error(err))
+				return err
+			}
+		}
+		data, err := stampPasswordChange(current.Type, *input.Data, previous, time.Now())
 		if err != nil {
-			logger.Log.Error("", zap.Error(err))
 			return err
 		}
 
-		encryptData, err := cryptoutil.Encrypt(*input.Data, decryptUserKey)
+		encryptData, err := cryptoutil.Encrypt(data, recordKey)
 		if err != nil {
 			logger.Log.Error("", zap.Error(err))
 			return err
@@ -154,10 +259,381 @@ func (s *RecordService) Update(ctx context.Context, userID int, idRecord string,
 	if input.Metadata != nil {
 		record.Metadata = *input.Metadata
 	}
-	err := s.recordRepo.UpdateRecord(ctx, userID, idRecord, record)
+	var expiry *model.RecordExpiry
+	if input.ChangesExpiry() {
+		// эфемерная запись с наступившим сроком удаляется, поэтому срок
+		// меняют только те, кому доступно удаление: владелец личной записи
+		// и участники с правом записи в коллекцию, но не получатели доступа
+		if current.CollectionID == 0 && current.UserID != userID {
+			return model.ErrNotOwner
+		}
+		expiresAt, ephemeral := current.ExpiresAt, current.Ephemeral
+		if input.ClearExpiry {
+			expiresAt, ephemeral = nil, false
+		}
+		if input.ExpiresAt != nil {
+			expiresAt = input.ExpiresAt
+		}
+		if input.Ephemeral != nil {
+			ephemeral = *input.Ephemeral
+		}
+		if ephemeral && expiresAt == nil {
+			return model.ErrEphemeralWithoutExpiry
+		}
+		expiry = &model.RecordExpiry{ExpiresAt: expiresAt, Ephemeral: ephemeral}
+	}
+	err = s.recordRepo.UpdateRecord(ctx, userID, idRecord, record, expiry)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// PurgeExpired удаляет эфемерные записи с наступившим сроком действия.
+// Удаление каждой записи фиксируется в журнале аудита её владельца.
+func (s *RecordService) PurgeExpired(ctx context.Context, now time.Time) (int, error) {
+	ctx, span := tracing.Start(ctx, "RecordService.PurgeExpired")
+	defer span.End()
+
+	deleted, err := s.recordRepo.DeleteExpiredRecords(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired records: %w", err)
+	}
+	for _, r := range deleted {
+		s.audit.Log(ctx, model.AuditEvent{
+			UserID:   r.UserID,
+			Action:   model.AuditRecordExpire,
+			RecordID: r.ID,
+			Result:   model.AuditSuccess,
+		})
+	}
+	return len(deleted), nil
+}
+
+// Share открывает пользователю доступ к записи с правами чтения или записи.
+// При первом предоставлении доступа запись перешифровывается собственным ключом,
+// который затем шифруется открытыми ключами владельца и получателей.
+func (s *RecordService) Share(ctx context.Context, ownerID int, idRecord string, input model.ShareInput) (err error) {
+	ctx, span := tracing.Start(ctx, "RecordService.Share")
+	defer span.End()
+
+	defer func() { s.audit.Log(ctx, auditEvent(model.AuditRecordShare, ownerID, idRecord, err)) }()
+
+	record, err := s.ownRecord(ctx, ownerID, idRecord)
+	if err != nil {
+		return err
+	}
+
+	recipient, err := s.userRepo.GetUserByLogin(ctx, input.Username)
+	if err != nil {
+		return fmt.Errorf("get recipient: %w", err)
+	}
+
+	if recipient.ID == ownerID {
+		return model.ErrShareWithOwner
+	}
+
+	dataKey, err := s.ensureDataKey(ctx, ownerID, record)
 	if err != nil {
 		logger.Log.Error("", zap.Error(err))
 		return err
 	}
+
+	recipientPublicKey, err := publicKeyOf(ctx, s.userRepo, s.cryptoUtil, recipient.ID)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return err
+	}
+
+	wrappedKey, err := cryptoutil.Seal(dataKey, recipientPublicKey)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return err
+	}
+
+	err = s.recordRepo.PutRecordKey(ctx, model.RecordKey{
+		RecordID:   record.ID,
+		UserID:     recipient.ID,
+		Permission: input.Permission,
+		WrappedKey: base64.StdEncoding.EncodeToString(wrappedKey),
+	})
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return err
+	}
+
+	logger.Log.Debug("record shared", zap.Int64("record id", record.ID), zap.String("recipient", recipient.Login))
 	return nil
 }
+
+// Unshare отзывает доступ пользователя к записи. Ключ записи ротируется:
+// данные перешифровываются новым ключом, который выдаётся только
+// оставшимся участникам, поэтому ранее полученный ключ становится бесполезным.
+func (s *RecordService) Unshare(ctx context.Context, ownerID int, idRecord string, username string) (err error) {
+	ctx, span := tracing.Start(ctx, "RecordService.Unshare")
+	defer span.End()
+
+	defer func() { s.audit.Log(ctx, auditEvent(model.AuditRecordUnshare, ownerID, idRecord, err)) }()
+
+	record, err := s.ownRecord(ctx, ownerID, idRecord)
+	if err != nil {
+		return err
+	}
+
+	keys, err := s.recordRepo.ListRecordKeys(ctx, record.ID)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return fmt.Errorf("list record keys: %w", err)
+	}
+
+	remaining := make([]model.RecordKey, 0, len(keys))
+	revoked := false
+	for _, key := range keys {
+		if key.Login == username && key.Permission != model.PermissionOwner {
+			revoked = true
+			continue
+		}
+		remaining = append(remaining, key)
+	}
+
+	if !revoked {
+		return fmt.Errorf("share for %s: %w", username, model.ErrUserNotFound)
+	}
+
+	dataKey, err := s.recordKey(ctx, ownerID, record)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return err
+	}
+
+	plain, err := cryptoutil.Decrypt(record.Data, dataKey)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return err
+	}
+
+	newDataKey, err := cryptoutil.GenerateRandom(32)
+	if err != nil {
+		return err
+	}
+
+	data, err := cryptoutil.Encrypt(plain, newDataKey)
+	if err != nil {
+		return err
+	}
+
+	for i, key := range remaining {
+		publicKey, err := base64.StdEncoding.DecodeString(key.PublicKey)
+		if err != nil {
+			return fmt.Errorf("decode public key of %s: %w", key.Login, err)
+		}
+		wrappedKey, err := cryptoutil.Seal(newDataKey, publicKey)
+		if err != nil {
+			return err
+		}
+		remaining[i].WrappedKey = base64.StdEncoding.EncodeToString(wrappedKey)
+	}
+
+	if err := s.recordRepo.ReplaceRecordKeys(ctx, record.ID, data, remaining); err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return fmt.Errorf("rotate record key: %w", err)
+	}
+
+	logger.Log.Debug("record share revoked", zap.Int64("record id", record.ID), zap.String("recipient", username))
+	return nil
+}
+
+// ListShares возвращает пользователей, которым владелец открыл доступ к записи.
+func (s *RecordService) ListShares(ctx context.Context, ownerID int, idRecord string) ([]model.Share, error) {
+	ctx, span := tracing.Start(ctx, "RecordService.ListShares")
+	defer span.End()
+
+	record, err := s.ownRecord(ctx, ownerID, idRecord)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.recordRepo.ListRecordKeys(ctx, record.ID)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return nil, fmt.Errorf("list record keys: %w", err)
+	}
+
+	shares := make([]model.Share, 0, len(keys))
+	for _, key := range keys {
+		if key.Permission == model.PermissionOwner {
+			continue
+		}
+		shares = append(shares, model.Share{Username: key.Login, Permission: key.Permission})
+	}
+	return shares, nil
+}
+
+// ownRecord возвращает запись, если пользователь является её владельцем.
+func (s *RecordService) ownRecord(ctx context.Context, userID int, idRecord string) (model.Record, error) {
+	if err := validRecordID(idRecord); err != nil {
+		return model.Record{}, err
+	}
+	record, err := s.recordRepo.GetRecord(ctx, userID, idRecord)
+	if err != nil {
+		logger.Log.Error("get record error", zap.Error(err))
+		return model.Record{}, fmt.Errorf("get record: %w", err)
+	}
+	if record.UserID != userID || record.CollectionID != 0 {
+		return model.Record{}, model.ErrNotOwner
+	}
+	return record, nil
+}
+
+// stampPasswordChange проставляет в данных записи login_password время смены
+// пароля password_changed_at: now, если записи ещё нет (previous == nil) или
+// пароль изменился, иначе — время из прежних данных previous. По нему отчёт
+// о гигиене считает возраст пароля: updated_at меняется и при
+// переименовании записи и при ротации её ключа. Данные других типов и
+// данные не в виде объекта JSON возвращаются без изменений.
+func stampPasswordChange(recordType model.RecordType, data, previous []byte, now time.Time) ([]byte, error) {
+	if recordType != model.TypeLoginPassword {
+		return data, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return data, nil
+	}
+
+	var next, prev model.LoginPassword
+	if err := json.Unmarshal(data, &next); err != nil {
+		return data, nil
+	}
+	changedAt := now.UTC()
+	if previous != nil && json.Unmarshal(previous, &prev) == nil && prev.Password == next.Password {
+		if prev.PasswordChangedAt == nil {
+			// записи, созданные до появления поля, остаются без него
+			delete(fields, "password_changed_at")
+			return json.Marshal(fields)
+		}
+		changedAt = *prev.PasswordChangedAt
+	}
+
+	raw, err := json.Marshal(changedAt)
+	if err != nil {
+		return nil, err
+	}
+	fields["password_changed_at"] = raw
+	return json.Marshal(fields)
+}
+
+// validRecordID проверяет идентификатор записи из запроса, чтобы хранилище
+// не получило нечисловой id.
+func validRecordID(idRecord string) error {
+	if id, err := strconv.ParseInt(idRecord, 10, 64); err != nil || id <= 0 {
+		return fmt.Errorf("%w: %q", model.ErrInvalidRecordID, idRecord)
+	}
+	return nil
+}
+
+// collectionKey проверяет, что пользователь может создавать записи в коллекции,
+// и возвращает идентификатор коллекции и её ключ. Если коллекция не указана,
+// используется первая коллекция организации.
+func (s *RecordService) collectionKey(ctx context.Context, userID int, orgID int, collectionID int) (int, []byte, error) {
+	if collectionID == 0 {
+		collections, err := s.orgRepo.ListCollections(ctx, orgID, userID)
+		if err != nil {
+			return 0, nil, fmt.Errorf("list collections: %w", err)
+		}
+		if len(collections) == 0 {
+			return 0, nil, model.ErrNoCollection
+		}
+		collectionID = collections[0].ID
+	}
+
+	key, err := s.orgRepo.GetCollectionKey(ctx, collectionID, userID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("get collection key: %w", err)
+	}
+
+	if (orgID != 0 && key.OrgID != orgID) || !key.Role.CanWrite() {
+		return 0, nil, model.ErrNoWriteAccess
+	}
+
+	_, privateKey, err := ensureKeyPair(ctx, s.userRepo, s.cryptoUtil, userID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	collectionKey, err := openKey(key.WrappedKey, privateKey)
+	if err != nil {
+		return 0, nil, fmt.Errorf("open collection key: %w", err)
+	}
+	return collectionID, collectionKey, nil
+}
+
+// recordKey возвращает ключ, которым зашифрованы данные записи:
+// ключ записи или коллекции, расшифрованный закрытым ключом пользователя,
+// либо user-key владельца для записей без собственного ключа.
+func (s *RecordService) recordKey(ctx context.Context, userID int, record model.Record) ([]byte, error) {
+	if record.DataKey == "" {
+		encryptedKey, err := s.userRepo.GetEncryptedKeyUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		return unwrapWithMasterKey(ctx, s.cryptoUtil, encryptedKey)
+	}
+
+	_, privateKey, err := ensureKeyPair(ctx, s.userRepo, s.cryptoUtil, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return openKey(record.DataKey, privateKey)
+}
+
+// ensureDataKey возвращает ключ записи владельца. Если запись ещё зашифрована
+// user-key, генерирует для неё собственный ключ и перешифровывает данные.
+func (s *RecordService) ensureDataKey(ctx context.Context, ownerID int, record model.Record) ([]byte, error) {
+	if record.DataKey != "" {
+		return s.recordKey(ctx, ownerID, record)
+	}
+
+	userKey, err := s.recordKey(ctx, ownerID, record)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := cryptoutil.Decrypt(record.Data, userKey)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := cryptoutil.GenerateRandom(32)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := cryptoutil.Encrypt(plain, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerPublicKey, err := publicKeyOf(ctx, s.userRepo, s.cryptoUtil, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	wrappedKey, err := cryptoutil.Seal(dataKey, ownerPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.recordRepo.ReplaceRecordKeys(ctx, record.ID, data, []model.RecordKey{{
+		RecordID:   record.ID,
+		UserID:     ownerID,
+		Permission: model.PermissionOwner,
+		WrappedKey: base64.StdEncoding.EncodeToString(wrappedKey),
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("convert record to data key: %w", err)
+	}
+
+	return dataKey, nil
+}
@@ -2,14 +2,21 @@ package service
 
 import (
 	"context"
+	"crypto/x509"
+	"time"
 
 	"github.com/fatkulllin/gophkeeper/model"
 )
 
-// Service агрегирует все сервисы доменной логики — работу с пользователями и записями.
+// Service агрегирует все сервисы доменной логики — работу с пользователями,
+// записями, организациями и журналом аудита.
 type Service struct {
-	User   *UserService
-	Record *RecordService
+	User       *UserService
+	Record     *RecordService
+	Org        *OrgService
+	Audit      *AuditService
+	Device     *DeviceService
+	VaultToken *VaultTokenService
 }
 
 // UserRepositories определяет методы для работы с пользователями в хранилище.
@@ -18,6 +25,9 @@ type UserRepositories interface {
 	CreateUser(ctx context.Context, user model.UserCredentials) (int, error)
 	GetUser(ctx context.Context, user model.UserCredentials) (model.User, error)
 	GetEncryptedKeyUser(ctx context.Context, userID int) (string, error)
+	GetUserByLogin(ctx context.Context, login string) (model.User, error)
+	GetKeyPair(ctx context.Context, userID int) (string, string, error)
+	SetKeyPair(ctx context.Context, userID int, publicKey string, encryptedPrivateKey string) (bool, error)
 }
 
 // RecordRepository определяет методы работы с записями пользователя.
@@ -25,13 +35,62 @@ type RecordRepositories interface {
 	CreateRecord(ctx context.Context, record model.Record) error
 	DeleteRecord(ctx context.Context, userID int, idRecord string) error
 	GetAllRecords(ctx context.Context, userID int) ([]model.Record, error)
+	GetOrgRecords(ctx context.Context, userID int, orgID int) ([]model.Record, error)
 	GetRecord(ctx context.Context, userID int, idRecord string) (model.Record, error)
-	UpdateRecord(ctx context.Context, userID int, idRecord string, record model.Record) error
+	UpdateRecord(ctx context.Context, userID int, idRecord string, record model.Record, expiry *model.RecordExpiry) error
+	ListRecordKeys(ctx context.Context, idRecord int64) ([]model.RecordKey, error)
+	PutRecordKey(ctx context.Context, key model.RecordKey) error
+	ReplaceRecordKeys(ctx context.Context, idRecord int64, data []byte, keys []model.RecordKey) error
+	DeleteExpiredRecords(ctx context.Context, now time.Time) ([]model.Record, error)
+}
+
+// OrgRepositories определяет методы работы с организациями, участниками и коллекциями.
+type OrgRepositories interface {
+	CreateOrg(ctx context.Context, name string, collectionName string, ownerKey model.CollectionKey) (model.Organization, error)
+	GetOrgs(ctx context.Context, userID int) ([]model.Organization, error)
+	GetMemberRole(ctx context.Context, orgID int, userID int) (model.OrgRole, error)
+	AddMember(ctx context.Context, orgID int, userID int, role model.OrgRole, keys []model.CollectionKey) error
+	ListMembers(ctx context.Context, orgID int) ([]model.OrgMember, error)
+	CreateCollection(ctx context.Context, orgID int, name string, keys []model.CollectionKey) (model.Collection, error)
+	ListCollections(ctx context.Context, orgID int, userID int) ([]model.Collection, error)
+	GetCollectionKey(ctx context.Context, collectionID int, userID int) (model.CollectionKey, error)
+}
+
+// AuditRepositories определяет методы работы с журналом аудита.
+type AuditRepositories interface {
+	AppendEvent(ctx context.Context, event model.AuditEvent) error
+	ListEvents(ctx context.Context, userID int, filter model.AuditFilter) ([]model.AuditEvent, error)
+	GetEventsAfter(ctx context.Context, afterID int64, limit int) ([]model.AuditEvent, error)
+}
+
+// DeviceRepositories определяет методы работы с устройствами и списком отозванных сертификатов.
+type DeviceRepositories interface {
+	CreateDevice(ctx context.Context, device model.Device) (int, error)
+	ListDevices(ctx context.Context, userID int) ([]model.Device, error)
+	GetDeviceByThumbprint(ctx context.Context, thumbprint string) (model.Device, error)
+	RevokeDevice(ctx context.Context, userID int, deviceID int) error
+	GetRequireDevice(ctx context.Context, userID int) (bool, error)
+	SetRequireDevice(ctx context.Context, userID int, require bool) error
+}
+
+// VaultTokenRepositories определяет методы работы с токенами API Vault KV v2.
+type VaultTokenRepositories interface {
+	CreateVaultToken(ctx context.Context, token model.VaultToken, hash string) (int, error)
+	ListVaultTokens(ctx context.Context, userID int) ([]model.VaultToken, error)
+	GetVaultTokenByHash(ctx context.Context, hash string) (model.VaultToken, error)
+	DeleteVaultToken(ctx context.Context, userID int, tokenID int) error
+}
+
+// DeviceCA выпускает сертификаты устройств.
+type DeviceCA interface {
+	Sign(csr *x509.CertificateRequest, login string) (*x509.Certificate, error)
+	CertPEM() []byte
 }
 
 // TokenManager предоставляет методы генерации JWT-токенов.
+// Непустой certThumbprint привязывает токен к сертификату устройства.
 type TokenManager interface {
-	Generate(userID int, userLogin string) (string, int, error)
+	Generate(userID int, userLogin string, certThumbprint string) (string, int, error)
 }
 
 // Password предоставляет функции хеширования и проверки паролей.
@@ -48,9 +107,15 @@ type CryptoUtil interface {
 
 // NewService создаёт контейнер сервисов и связывает бизнес-логику
 // с реализациями репозиториев, менеджером токенов, хешированием паролей и криптографией.
-func NewService(userRepo UserRepositories, recordRepo RecordRepositories, tokenManager TokenManager, password Password, cryptoUtil CryptoUtil) *Service {
+// Если deviceCA равен nil, выпуск сертификатов устройств отключён.
+func NewService(userRepo UserRepositories, recordRepo RecordRepositories, orgRepo OrgRepositories, auditRepo AuditRepositories, deviceRepo DeviceRepositories, vaultTokenRepo VaultTokenRepositories, tokenManager TokenManager, password Password, cryptoUtil CryptoUtil, deviceCA DeviceCA) *Service {
+	audit := NewAuditService(auditRepo)
 	return &Service{
-		User:   NewUserService(userRepo, tokenManager, password, cryptoUtil),
-		Record: NewRecordService(recordRepo, userRepo, cryptoUtil),
+		User:       NewUserService(userRepo, deviceRepo, tokenManager, password, cryptoUtil, audit),
+		Record:     NewRecordService(recordRepo, userRepo, orgRepo, cryptoUtil, audit),
+		Org:        NewOrgService(orgRepo, userRepo, cryptoUtil, audit),
+		Audit:      audit,
+		Device:     NewDeviceService(deviceRepo, deviceCA, audit),
+		VaultToken: NewVaultTokenService(vaultTokenRepo, audit),
 	}
 }
@@ -6,8 +6,11 @@ package service
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 
+	"github.com/fatkulllin/gophkeeper/internal/server/metrics"
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
 	"github.com/fatkulllin/gophkeeper/model"
 	"github.com/fatkulllin/gophkeeper/pkg/cryptoutil"
 	"github.com/fatkulllin/gophkeeper/pkg/logger"
@@ -17,20 +20,32 @@ import (
 // UserService содержит бизнес-логику регистрации и авторизации пользователей.
 type UserService struct {
 	repo         UserRepositories
+	devices      DeviceRepositories
 	password     Password
 	tokenManager TokenManager
 	cryptoUtil   CryptoUtil
+	audit        Auditor
 }
 
 // NewUserService создаёт новый сервис для работы с пользователями
-func NewUserService(repo UserRepositories, tokenManager TokenManager, password Password, cryptoUtil CryptoUtil) *UserService {
-	return &UserService{repo: repo, tokenManager: tokenManager, password: password, cryptoUtil: cryptoUtil}
+func NewUserService(repo UserRepositories, devices DeviceRepositories, tokenManager TokenManager, password Password, cryptoUtil CryptoUtil, audit Auditor) *UserService {
+	return &UserService{repo: repo, devices: devices, tokenManager: tokenManager, password: password, cryptoUtil: cryptoUtil, audit: audit}
 }
 
 // UserRegister выполняет регистрацию нового пользователя.
 // Генерируется user-key (32 байта), который шифруется master-key’ем,
+// и пара ключей X25519 для общего доступа к записям,
 // пароль хешируется с использованием scrypt, затем создаётся JWT.
-func (s *UserService) UserRegister(ctx context.Context, user model.UserCredentials) (string, int, error) {
+func (s *UserService) UserRegister(ctx context.Context, user model.UserCredentials) (_ string, _ int, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.UserRegister")
+	defer span.End()
+
+	var userID int
+	defer func() {
+		event := auditEvent(model.AuditUserRegister, userID, "", err)
+		event.Login = user.Username
+		s.audit.Log(ctx, event)
+	}()
 
 	userExists, err := s.repo.ExistUser(ctx, user)
 
@@ -42,7 +57,9 @@ func (s *UserService) UserRegister(ctx context.Context, user model.UserCredentia
 		return "", 0, model.ErrUserExists
 	}
 
+	_, hashSpan := tracing.Start(ctx, "scrypt.Hash")
 	hashPassword, err := s.password.Hash(user.Password)
+	hashSpan.End()
 	if err != nil {
 		return "", 0, fmt.Errorf("hash password: %w", err)
 	}
@@ -60,12 +77,18 @@ func (s *UserService) UserRegister(ctx context.Context, user model.UserCredentia
 		return "", 0, err
 	}
 
-	userID, err := s.repo.CreateUser(ctx, user)
+	user.PublicKey, user.EncryptedPrivateKey, err = newKeyPair(s.cryptoUtil)
+
 	if err != nil {
 		return "", 0, err
 	}
 
-	tokenString, tokenExpires, err := s.tokenManager.Generate(userID, user.Username)
+	userID, err = s.repo.CreateUser(ctx, user)
+	if err != nil {
+		return "", 0, err
+	}
+
+	tokenString, tokenExpires, err := s.tokenManager.Generate(userID, user.Username, "")
 
 	if err != nil {
 		return "", 0, err
@@ -75,40 +98,80 @@ func (s *UserService) UserRegister(ctx context.Context, user model.UserCredentia
 }
 
 // UserLogin выполняет авторизацию.
-// При wantUserKey = true дополнительно расшифровывает user-key и возвращает его в base64.
-func (s *UserService) UserLogin(ctx context.Context, user model.UserCredentials, wantUserKey bool) (string, int, string, error) {
-	var userKeyBase64 string
+// При wantUserKey = true дополнительно расшифровывает user-key и закрытый ключ
+// пользователя и возвращает их в base64.
+// Если клиент предъявил сертификат своего устройства, токен привязывается к нему;
+// пользователям с обязательным входом с устройства без сертификата отказывается.
+func (s *UserService) UserLogin(ctx context.Context, user model.UserCredentials, wantUserKey bool) (_ string, _ int, _ model.UserKeyRespone, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.UserLogin")
+	defer span.End()
+
+	var keys model.UserKeyRespone
 	getUser, err := s.repo.GetUser(ctx, user)
+	defer func() {
+		event := auditEvent(model.AuditUserLogin, getUser.ID, "", err)
+		event.Login = user.Username
+		s.audit.Log(ctx, event)
+		metrics.ObserveLogin(err == nil)
+	}()
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			// не раскрываем, существует ли пользователь
+			return "", 0, model.UserKeyRespone{}, fmt.Errorf("login %s: %w", user.Username, model.ErrIncorrectPassword)
+		}
+		return "", 0, model.UserKeyRespone{}, err
+	}
+	_, compareSpan := tracing.Start(ctx, "scrypt.Compare")
+	resultPassword, err := s.password.Compare(getUser.PasswordHash, user.Password)
+	compareSpan.End()
+
+	if err != nil {
+		return "", 0, model.UserKeyRespone{}, err
+	}
+
+	if !resultPassword {
+		return "", 0, model.UserKeyRespone{}, model.ErrIncorrectPassword
+	}
+
 	if wantUserKey {
 		encryptedKey, err := s.repo.GetEncryptedKeyUser(ctx, getUser.ID)
 		if err != nil {
 			logger.Log.Error("", zap.Error(err))
-			return "", 0, "", err
+			return "", 0, model.UserKeyRespone{}, err
 		}
-		decryptUserKey, err := s.cryptoUtil.DecryptWithMasterKey(encryptedKey)
+		decryptUserKey, err := unwrapWithMasterKey(ctx, s.cryptoUtil, encryptedKey)
 		if err != nil {
 			logger.Log.Error("", zap.Error(err))
-			return "", 0, "", err
+			return "", 0, model.UserKeyRespone{}, err
 		}
-		userKeyBase64 = base64.StdEncoding.EncodeToString(decryptUserKey)
-	}
-	if err != nil {
-		return "", 0, "", err
+		keys.UserKey = base64.StdEncoding.EncodeToString(decryptUserKey)
+
+		_, privateKey, err := ensureKeyPair(ctx, s.repo, s.cryptoUtil, getUser.ID)
+		if err != nil {
+			logger.Log.Error("", zap.Error(err))
+			return "", 0, model.UserKeyRespone{}, err
+		}
+		keys.PrivateKey = base64.StdEncoding.EncodeToString(privateKey)
 	}
-	resultPassword, err := s.password.Compare(getUser.PasswordHash, user.Password)
 
+	var certThumbprint string
+	device, deviceErr := presentedDevice(ctx, s.devices, getUser.ID)
+	if deviceErr == nil {
+		certThumbprint = device.Thumbprint
+	}
+	requireDevice, err := s.devices.GetRequireDevice(ctx, getUser.ID)
 	if err != nil {
-		return "", 0, "", err
+		return "", 0, model.UserKeyRespone{}, err
 	}
-
-	if !resultPassword {
-		return "", 0, "", model.ErrIncorrectPassword
+	if requireDevice && certThumbprint == "" {
+		logger.Log.Debug("login without enrolled device refused", zap.String("login", getUser.Login), zap.Error(deviceErr))
+		return "", 0, model.UserKeyRespone{}, model.ErrDeviceRequired
 	}
 
-	tokenString, tokenExpires, err := s.tokenManager.Generate(getUser.ID, getUser.Login)
+	tokenString, tokenExpires, err := s.tokenManager.Generate(getUser.ID, getUser.Login, certThumbprint)
 
 	if err != nil {
-		return "", 0, "", err
+		return "", 0, model.UserKeyRespone{}, err
 	}
-	return tokenString, tokenExpires, userKeyBase64, nil
+	return tokenString, tokenExpires, keys, nil
 }
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
+	"github.com/fatkulllin/gophkeeper/model"
+)
+
+// ReadSecret возвращает расшифрованную запись по пути секрета в API,
+// совместимом с Vault KV v2. Путь — метаданные записи (app/db); если записи с
+// такими метаданными нет, числовой путь считается идентификатором записи.
+// Учитываются личные и общие записи пользователя.
+func (s *RecordService) ReadSecret(ctx context.Context, userID int, path string) (model.RecordResponse, error) {
+	ctx, span := tracing.Start(ctx, "RecordService.ReadSecret")
+	defer span.End()
+
+	records, err := s.GetAll(ctx, userID, 0, model.RecordFilter{})
+	if err != nil {
+		return model.RecordResponse{}, err
+	}
+
+	idRecord := ""
+	for _, r := range records {
+		if r.Metadata != path {
+			continue
+		}
+		if idRecord != "" {
+			return model.RecordResponse{}, fmt.Errorf("%w: %s", model.ErrAmbiguousSecretPath, path)
+		}
+		idRecord = strconv.FormatInt(r.ID, 10)
+	}
+	if idRecord == "" {
+		if validRecordID(path) != nil {
+			return model.RecordResponse{}, model.ErrRecordNotFound
+		}
+		idRecord = path
+	}
+
+	return s.Get(ctx, userID, idRecord)
+}
+
+// ListSecrets возвращает ключи на уровне prefix так же, как LIST в Vault:
+// имена секретов и вложенных «каталогов» с завершающим "/", без повторов и
+// по алфавиту. Если ключей нет, возвращается model.ErrRecordNotFound.
+func (s *RecordService) ListSecrets(ctx context.Context, userID int, prefix string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "RecordService.ListSecrets")
+	defer span.End()
+
+	records, err := s.GetAll(ctx, userID, 0, model.RecordFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	if prefix != "" {
+		prefix += "/"
+	}
+	seen := make(map[string]struct{})
+	for _, r := range records {
+		rest, ok := strings.CutPrefix(r.Metadata, prefix)
+		if !ok || rest == "" {
+			continue
+		}
+		if i := strings.Index(rest, "/"); i >= 0 {
+			rest = rest[:i+1]
+		}
+		seen[rest] = struct{}{}
+	}
+	if len(seen) == 0 {
+		return nil, model.ErrRecordNotFound
+	}
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/tracing"
+	"github.com/fatkulllin/gophkeeper/model"
+	"github.com/fatkulllin/gophkeeper/pkg/logger"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// vaultTokenPrefix отличает токены Vault от JWT сессии.
+const vaultTokenPrefix = "gkv_"
+
+// vaultTokenSize — число случайных байт токена Vault.
+const vaultTokenSize = 32
+
+// VaultTokenService выпускает, перечисляет и отзывает токены клиентов
+// API Vault KV v2 и проверяет их. Токен Vault не заменяет сессию: с ним
+// доступно только чтение секретов через /v1, а JWT сессии там не принимается.
+type VaultTokenService struct {
+	repo  VaultTokenRepositories
+	audit Auditor
+}
+
+// NewVaultTokenService создаёт новый сервис токенов Vault.
+func NewVaultTokenService(repo VaultTokenRepositories, audit Auditor) *VaultTokenService {
+	return &VaultTokenService{repo: repo, audit: audit}
+}
+
+// Create выпускает токен Vault пользователя. Сервер сохраняет только хеш
+// токена, поэтому сам токен возвращается один раз.
+func (s *VaultTokenService) Create(ctx context.Context, userID int, input model.VaultTokenInput) (_ model.VaultTokenResponse, err error) {
+	ctx, span := tracing.Start(ctx, "VaultTokenService.Create")
+	defer span.End()
+
+	defer func() { s.audit.Log(ctx, auditEvent(model.AuditVaultTokenCreate, userID, "", err)) }()
+
+	secret := make([]byte, vaultTokenSize)
+	if _, err := rand.Read(secret); err != nil {
+		return model.VaultTokenResponse{}, fmt.Errorf("generate vault token: %w", err)
+	}
+	token := vaultTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	vaultToken := model.VaultToken{UserID: userID, Name: input.Name, CreatedAt: time.Now().UTC().Truncate(time.Millisecond)}
+	vaultToken.ID, err = s.repo.CreateVaultToken(ctx, vaultToken, hashVaultToken(token))
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return model.VaultTokenResponse{}, fmt.Errorf("create vault token: %w", err)
+	}
+
+	logger.Log.Debug("vault token created", zap.Int("token id", vaultToken.ID))
+	return model.VaultTokenResponse{VaultToken: vaultToken, Token: token}, nil
+}
+
+// List возвращает токены Vault пользователя без самих токенов.
+func (s *VaultTokenService) List(ctx context.Context, userID int) ([]model.VaultToken, error) {
+	ctx, span := tracing.Start(ctx, "VaultTokenService.List")
+	defer span.End()
+
+	tokens, err := s.repo.ListVaultTokens(ctx, userID)
+	if err != nil {
+		logger.Log.Error("", zap.Error(err))
+		return nil, fmt.Errorf("list vault tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// Revoke отзывает токен Vault: запросы с ним сразу перестают приниматься.
+func (s *VaultTokenService) Revoke(ctx context.Context, userID int, tokenID int) (err error) {
+	ctx, span := tracing.Start(ctx, "VaultTokenService.Revoke")
+	defer span.End()
+
+	defer func() { s.audit.Log(ctx, auditEvent(model.AuditVaultTokenRevoke, userID, "", err)) }()
+
+	if err := s.repo.DeleteVaultToken(ctx, userID, tokenID); err != nil {
+		return fmt.Errorf("revoke vault token %d: %w", tokenID, err)
+	}
+	return nil
+}
+
+// Authenticate проверяет токен Vault и возвращает claims его владельца.
+// Токены без префикса gkv_, в том числе JWT сессии, не принимаются.
+func (s *VaultTokenService) Authenticate(ctx context.Context, token string) (model.Claims, error) {
+	ctx, span := tracing.Start(ctx, "VaultTokenService.Authenticate")
+	defer span.End()
+
+	if !strings.HasPrefix(token, vaultTokenPrefix) {
+		return model.Claims{}, model.ErrVaultTokenNotFound
+	}
+	vaultToken, err := s.repo.GetVaultTokenByHash(ctx, hashVaultToken(token))
+	if err != nil {
+		return model.Claims{}, err
+	}
+	return model.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(vaultToken.CreatedAt)},
+		UserID:           vaultToken.UserID,
+		UserLogin:        vaultToken.UserLogin,
+	}, nil
+}
+
+// hashVaultToken возвращает hex(sha256(token)) — под этим ключом токен
+// хранится в базе. Токен случайный, поэтому соль не нужна.
+func hashVaultToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,106 @@
+// Пакет storage открывает хранилище сервера, выбранное в конфигурации:
+// Postgres, встроенную базу SQLite или память процесса.
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/fatkulllin/gophkeeper/internal/server/db"
+	"github.com/fatkulllin/gophkeeper/internal/server/metrics"
+	"github.com/fatkulllin/gophkeeper/internal/server/repositories/memory"
+	"github.com/fatkulllin/gophkeeper/internal/server/repositories/postgres"
+	"github.com/fatkulllin/gophkeeper/internal/server/repositories/sqlite"
+	"github.com/fatkulllin/gophkeeper/internal/server/service"
+	"github.com/fatkulllin/gophkeeper/migrations"
+)
+
+// Типы хранилищ.
+const (
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+	Memory   = "memory"
+)
+
+// RecordRepositories — репозиторий записей, который также отдаёт
+// количество записей по типам для метрик.
+type RecordRepositories interface {
+	service.RecordRepositories
+	metrics.RecordCounter
+}
+
+// Storage — набор репозиториев одного хранилища.
+// DB равен nil для хранилища в памяти.
+type Storage struct {
+	DB          *sql.DB
+	Users       service.UserRepositories
+	Records     RecordRepositories
+	Orgs        service.OrgRepositories
+	Audit       service.AuditRepositories
+	Devices     service.DeviceRepositories
+	VaultTokens service.VaultTokenRepositories
+}
+
+// Open подключается к хранилищу kind и применяет миграции.
+// dsn — строка подключения Postgres или путь к файлу SQLite;
+// для хранилища в памяти не используется.
+func Open(kind string, dsn string) (*Storage, error) {
+	switch kind {
+	case Postgres:
+		conn, err := db.NewPostgres(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to database %w", err)
+		}
+		if err := db.Bootstrap(conn, migrations.FS, "postgres"); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to apply migrations %w", err)
+		}
+		return &Storage{
+			DB:          conn,
+			Users:       postgres.NewUserRepo(conn),
+			Records:     postgres.NewRecordRepo(conn),
+			Orgs:        postgres.NewOrgRepo(conn),
+			Audit:       postgres.NewAuditRepo(conn),
+			Devices:     postgres.NewDeviceRepo(conn),
+			VaultTokens: postgres.NewVaultTokenRepo(conn),
+		}, nil
+	case SQLite:
+		conn, err := db.NewSQLite(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database %w", err)
+		}
+		if err := db.Bootstrap(conn, migrations.SQLiteFS, "sqlite3"); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to apply migrations %w", err)
+		}
+		return &Storage{
+			DB:          conn,
+			Users:       sqlite.NewUserRepo(conn),
+			Records:     sqlite.NewRecordRepo(conn),
+			Orgs:        sqlite.NewOrgRepo(conn),
+			Audit:       sqlite.NewAuditRepo(conn),
+			Devices:     sqlite.NewDeviceRepo(conn),
+			VaultTokens: sqlite.NewVaultTokenRepo(conn),
+		}, nil
+	case Memory:
+		store := memory.NewStore()
+		return &Storage{
+			Users:       memory.NewUserRepo(store),
+			Records:     memory.NewRecordRepo(store),
+			Orgs:        memory.NewOrgRepo(store),
+			Audit:       memory.NewAuditRepo(store),
+			Devices:     memory.NewDeviceRepo(store),
+			VaultTokens: memory.NewVaultTokenRepo(store),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage: %s", kind)
+	}
+}
+
+// Close закрывает соединение с базой данных, если оно есть.
+func (s *Storage) Close() error {
+	if s.DB == nil {
+		return nil
+	}
+	return s.DB.Close()
+}
@@ -0,0 +1,80 @@
+// Package tracing настраивает трассировку OpenTelemetry серверного приложения
+// GophKeeper и предоставляет middleware и перехватчики для HTTP и gRPC.
+//
+// Контекст трассировки принимается от клиента в формате W3C Trace Context
+// (заголовок traceparent), поэтому спаны CLI и сервера образуют одну трассу.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Поддерживаемые экспортёры спанов.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+const (
+	serviceName = "gophkeeper-server"
+	tracerName  = "github.com/fatkulllin/gophkeeper/internal/server"
+)
+
+// Init настраивает глобальный провайдер трассировки и W3C-пропагатор.
+// exporter выбирает способ экспорта спанов: none, stdout или otlp
+// (OTLP/HTTP на адрес endpoint в формате host:port).
+//
+// Возвращает функцию, которая отправляет накопленные спаны и останавливает провайдер.
+func Init(ctx context.Context, exporter string, endpoint string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if exporter == ExporterNone || exporter == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	spanExporter, err := newExporter(ctx, exporter, endpoint, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, exporter string, endpoint string, out io.Writer) (sdktrace.SpanExporter, error) {
+	switch exporter {
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(out))
+	case ExporterOTLP:
+		return otlptracehttp.New(ctx, otlptracehttp.WithEndpoint(endpoint), otlptracehttp.WithInsecure())
+	default:
+		return nil, fmt.Errorf("unknown trace exporter: %s", exporter)
+	}
+}
+
+// Start создаёт дочерний спан с именем name.
+// Вызывающий обязан завершить спан через span.End().
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN public_key TEXT,            -- открытый ключ X25519 (base64)
+    ADD COLUMN encrypted_private_key TEXT; -- закрытый ключ X25519, зашифрованный master-key
+CREATE TABLE record_keys (
+    record_id INT NOT NULL REFERENCES records(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    permission TEXT NOT NULL,  -- owner | read | write
+    wrapped_key TEXT NOT NULL, -- ключ записи, зашифрованный открытым ключом пользователя (base64)
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (record_id, user_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS record_keys;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS public_key,
+    DROP COLUMN IF EXISTS encrypted_private_key;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE organizations (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name VARCHAR(255) UNIQUE NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+CREATE TABLE org_members (
+    org_id INT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL, -- owner | admin | member | read-only
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (org_id, user_id)
+);
+CREATE TABLE collections (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    org_id INT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (org_id, name)
+);
+CREATE TABLE collection_keys (
+    collection_id INT NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wrapped_key TEXT NOT NULL, -- ключ коллекции, зашифрованный открытым ключом участника (base64)
+    PRIMARY KEY (collection_id, user_id)
+);
+ALTER TABLE records ADD COLUMN collection_id INT REFERENCES collections(id) ON DELETE CASCADE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE records DROP COLUMN IF EXISTS collection_id;
+DROP TABLE IF EXISTS collection_keys;
+DROP TABLE IF EXISTS collections;
+DROP TABLE IF EXISTS org_members;
+DROP TABLE IF EXISTS organizations;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE audit_events (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id INT,               -- без внешнего ключа: события переживают удаление пользователя
+    login VARCHAR(255) NOT NULL DEFAULT '',
+    action TEXT NOT NULL,      -- user.login | record.read | record.update | ...
+    record_id BIGINT,          -- без внешнего ключа: события переживают удаление записи
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    result TEXT NOT NULL,      -- success | failure | denied
+    created_at TIMESTAMPTZ NOT NULL,
+    prev_hash TEXT NOT NULL,   -- hash предыдущего события цепочки
+    hash TEXT NOT NULL         -- sha256(prev_hash + поля события)
+);
+CREATE INDEX audit_events_user_id_idx ON audit_events (user_id);
+CREATE INDEX audit_events_record_id_idx ON audit_events (record_id);
+
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only();
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN require_device BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE devices (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    serial TEXT NOT NULL UNIQUE,      -- серийный номер сертификата (hex)
+    thumbprint TEXT NOT NULL UNIQUE,  -- base64url(sha256(DER)), x5t#S256
+    created_at TIMESTAMP DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- Список отозванных сертификатов устройств (CRL).
+CREATE TABLE revoked_certificates (
+    serial TEXT PRIMARY KEY,
+    revoked_at TIMESTAMP DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS revoked_certificates;
+DROP TABLE IF EXISTS devices;
+ALTER TABLE users DROP COLUMN IF EXISTS require_device;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE records ADD COLUMN expires_at TIMESTAMP;                      -- срок действия секрета (UTC)
+ALTER TABLE records ADD COLUMN ephemeral BOOLEAN NOT NULL DEFAULT FALSE;  -- удалить после expires_at
+
+CREATE INDEX records_ephemeral_expires_at_idx ON records (expires_at) WHERE ephemeral;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS records_ephemeral_expires_at_idx;
+ALTER TABLE records DROP COLUMN IF EXISTS ephemeral;
+ALTER TABLE records DROP COLUMN IF EXISTS expires_at;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Токены клиентов API Vault KV v2: только чтение секретов владельца.
+CREATE TABLE vault_tokens (
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,  -- hex(sha256(токен)), сам токен не хранится
+    created_at TIMESTAMP DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS vault_tokens;
+-- +goose StatementEnd
@@ -2,7 +2,16 @@ package migrations
 
 import (
 	"embed"
+	"io/fs"
 )
 
 //go:embed *.sql
 var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// SQLiteFS содержит схему встроенной базы SQLite. Схема ведётся отдельно:
+// миграции Postgres используют identity-колонки, plpgsql и ALTER TABLE,
+// которые SQLite не поддерживает.
+var SQLiteFS, _ = fs.Sub(sqliteFS, "sqlite")
@@ -0,0 +1,115 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    login TEXT UNIQUE NOT NULL,
+    password_hash TEXT NOT NULL,
+    encrypted_key TEXT NOT NULL,
+    public_key TEXT,            -- открытый ключ X25519 (base64)
+    encrypted_private_key TEXT, -- закрытый ключ X25519, зашифрованный master-key
+    require_device BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+CREATE TABLE organizations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT UNIQUE NOT NULL,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+CREATE TABLE org_members (
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL, -- owner | admin | member | read-only
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (org_id, user_id)
+);
+CREATE TABLE collections (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    UNIQUE (org_id, name)
+);
+CREATE TABLE collection_keys (
+    collection_id INTEGER NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wrapped_key TEXT NOT NULL, -- ключ коллекции, зашифрованный открытым ключом участника (base64)
+    PRIMARY KEY (collection_id, user_id)
+);
+CREATE TABLE records (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    collection_id INTEGER REFERENCES collections(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,  -- login_password | text | binary | card
+    metadata TEXT,       -- произвольная информация: сайт, описание, теги
+    data BLOB NOT NULL,  -- зашифрованные данные (JSON или бинарь)
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+CREATE TABLE record_keys (
+    record_id INTEGER NOT NULL REFERENCES records(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    permission TEXT NOT NULL,  -- owner | read | write
+    wrapped_key TEXT NOT NULL, -- ключ записи, зашифрованный открытым ключом пользователя (base64)
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (record_id, user_id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE audit_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER,           -- без внешнего ключа: события переживают удаление пользователя
+    login TEXT NOT NULL DEFAULT '',
+    action TEXT NOT NULL,      -- user.login | record.read | record.update | ...
+    record_id INTEGER,         -- без внешнего ключа: события переживают удаление записи
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    result TEXT NOT NULL,      -- success | failure | denied
+    created_at TIMESTAMP NOT NULL,
+    prev_hash TEXT NOT NULL,   -- hash предыдущего события цепочки
+    hash TEXT NOT NULL         -- sha256(prev_hash + поля события)
+);
+CREATE INDEX audit_events_user_id_idx ON audit_events (user_id);
+CREATE INDEX audit_events_record_id_idx ON audit_events (record_id);
+
+CREATE TRIGGER audit_events_no_update BEFORE UPDATE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+CREATE TRIGGER audit_events_no_delete BEFORE DELETE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE devices (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    serial TEXT NOT NULL UNIQUE,      -- серийный номер сертификата (hex)
+    thumbprint TEXT NOT NULL UNIQUE,  -- base64url(sha256(DER)), x5t#S256
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- Список отозванных сертификатов устройств (CRL).
+CREATE TABLE revoked_certificates (
+    serial TEXT PRIMARY KEY,
+    revoked_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS revoked_certificates;
+DROP TABLE IF EXISTS devices;
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS record_keys;
+DROP TABLE IF EXISTS records;
+DROP TABLE IF EXISTS collection_keys;
+DROP TABLE IF EXISTS collections;
+DROP TABLE IF EXISTS org_members;
+DROP TABLE IF EXISTS organizations;
+DROP TABLE IF EXISTS users;
+-- +goose StatementEnd
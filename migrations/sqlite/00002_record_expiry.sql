@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE records ADD COLUMN expires_at TIMESTAMP;
+ALTER TABLE records ADD COLUMN ephemeral BOOLEAN NOT NULL DEFAULT 0;
+
+CREATE INDEX records_ephemeral_expires_at_idx ON records (expires_at) WHERE ephemeral;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS records_ephemeral_expires_at_idx;
+ALTER TABLE records DROP COLUMN ephemeral;
+ALTER TABLE records DROP COLUMN expires_at;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Токены клиентов API Vault KV v2: только чтение секретов владельца.
+CREATE TABLE vault_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,  -- hex(sha256(токен)), сам токен не хранится
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS vault_tokens;
+-- +goose StatementEnd
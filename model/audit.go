@@ -0,0 +1,100 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// AuditAction — тип события журнала аудита.
+type AuditAction string
+
+const (
+	AuditUserRegister     AuditAction = "user.register"
+	AuditUserLogin        AuditAction = "user.login"
+	AuditRecordCreate     AuditAction = "record.create"
+	AuditRecordList       AuditAction = "record.list"
+	AuditRecordRead       AuditAction = "record.read"
+	AuditRecordUpdate     AuditAction = "record.update"
+	AuditRecordDelete     AuditAction = "record.delete"
+	AuditRecordShare      AuditAction = "record.share"
+	AuditRecordUnshare    AuditAction = "record.unshare"
+	AuditRecordExpire     AuditAction = "record.expire"
+	AuditOrgCreate        AuditAction = "org.create"
+	AuditOrgInvite        AuditAction = "org.invite"
+	AuditDeviceEnroll     AuditAction = "device.enroll"
+	AuditDeviceRevoke     AuditAction = "device.revoke"
+	AuditVaultTokenCreate AuditAction = "vault_token.create"
+	AuditVaultTokenRevoke AuditAction = "vault_token.revoke"
+)
+
+// AuditResult — итог операции, зафиксированной в журнале аудита.
+type AuditResult string
+
+const (
+	AuditSuccess AuditResult = "success"
+	AuditFailure AuditResult = "failure"
+	AuditDenied  AuditResult = "denied"
+)
+
+// AuditEvent — запись журнала аудита. События образуют цепочку:
+// Hash каждого события вычисляется от PrevHash и полей события,
+// поэтому изменение или удаление любого события обнаруживается при проверке.
+type AuditEvent struct {
+	ID        int64       `json:"id"`
+	UserID    int         `json:"user_id,omitempty"`
+	Login     string      `json:"login,omitempty"`
+	Action    AuditAction `json:"action"`
+	RecordID  int64       `json:"record_id,omitempty"`
+	IP        string      `json:"ip,omitempty"`
+	UserAgent string      `json:"user_agent,omitempty"`
+	Result    AuditResult `json:"result"`
+	CreatedAt time.Time   `json:"created_at"`
+	PrevHash  string      `json:"prev_hash"`
+	Hash      string      `json:"hash"`
+}
+
+// ChainHash вычисляет hash события, следующего за событием с hash prevHash.
+func (e AuditEvent) ChainHash(prevHash string) string {
+	fields, _ := json.Marshal([]string{
+		prevHash,
+		strconv.Itoa(e.UserID),
+		e.Login,
+		string(e.Action),
+		strconv.FormatInt(e.RecordID, 10),
+		e.IP,
+		e.UserAgent,
+		string(e.Result),
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditFilter — условия выборки событий журнала аудита.
+type AuditFilter struct {
+	Action   AuditAction
+	Result   AuditResult
+	RecordID int64
+	Since    time.Time
+	Until    time.Time
+	Limit    int
+}
+
+// AuditVerification — результат проверки целостности цепочки событий.
+type AuditVerification struct {
+	Valid    bool  `json:"valid"`
+	Events   int   `json:"events"`
+	BrokenAt int64 `json:"broken_at,omitempty"`
+}
+
+// RequestInfo — сведения о клиенте, выполнившем HTTP-запрос.
+// CertThumbprint и CertSerial заполняются, если клиент предъявил сертификат устройства.
+type RequestInfo struct {
+	IP             string
+	UserAgent      string
+	CertThumbprint string
+	CertSerial     string
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+)
+
+var ErrDeviceAuthDisabled = NewError(CodeNotImplemented, "device authentication is disabled")
+var ErrInvalidCSR = NewError(CodeValidation, "invalid certificate signing request")
+var ErrDeviceNotFound = NewError(CodeNotFound, "device not found")
+var ErrDeviceRevoked = NewError(CodeUnauthorized, "device certificate is revoked")
+var ErrDeviceRequired = NewError(CodeForbidden, "enrolled device certificate required")
+
+// Confirmation — claim "cnf" JWT: отпечаток сертификата, к которому привязан токен.
+type Confirmation struct {
+	X5tS256 string `json:"x5t#S256"`
+}
+
+// Device — устройство пользователя с сертификатом, выданным внутренним CA.
+type Device struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"-"`
+	Name       string     `json:"name"`
+	Serial     string     `json:"serial"`
+	Thumbprint string     `json:"thumbprint"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// EnrollInput — запрос на выпуск сертификата устройства. CSR передаётся в PEM.
+type EnrollInput struct {
+	Name string `json:"name" validate:"required"`
+	CSR  string `json:"csr" validate:"required"`
+}
+
+// EnrollResponse — выпущенный сертификат устройства и сертификат CA в PEM.
+type EnrollResponse struct {
+	Device        Device `json:"device"`
+	Certificate   string `json:"certificate"`
+	CACertificate string `json:"ca_certificate"`
+}
+
+// DevicePolicy определяет, обязателен ли для входа сертификат устройства.
+type DevicePolicy struct {
+	RequireDevice bool `json:"require_device"`
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode — машиночитаемый код ошибки API. Сервер возвращает его в теле
+// ответа с ошибкой, клиент по нему выбирает сообщение и код завершения.
+type ErrorCode string
+
+const (
+	CodeNotFound       ErrorCode = "not_found"
+	CodeConflict       ErrorCode = "conflict"
+	CodeValidation     ErrorCode = "validation"
+	CodeForbidden      ErrorCode = "forbidden"
+	CodeUnauthorized   ErrorCode = "unauthorized"
+	CodeNotImplemented ErrorCode = "not_implemented"
+	CodeInternal       ErrorCode = "internal"
+)
+
+// Error — доменная ошибка с кодом. Сообщение безопасно отдавать клиенту;
+// контекст (идентификаторы, причины) добавляется обёрткой через %w и
+// остаётся в логах сервера.
+type Error struct {
+	Code    ErrorCode
+	Message string
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// NewError возвращает доменную ошибку с кодом code.
+func NewError(code ErrorCode, message string) error {
+	return &Error{Code: code, Message: message}
+}
+
+// Errorf возвращает доменную ошибку с кодом code и форматированным сообщением.
+func Errorf(code ErrorCode, format string, args ...any) error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// AsError возвращает доменную ошибку из цепочки err. Для ошибок без кода
+// возвращается внутренняя ошибка, текст которой не раскрывается клиенту.
+func AsError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return &Error{Code: CodeInternal, Message: "internal server error"}
+}
+
+// ErrorResponse — тело ответа API с ошибкой:
+//
+//	{"error": {"code": "not_found", "message": "record not found"}}
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody — код и сообщение ошибки API.
+type ErrorBody struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
@@ -2,15 +2,23 @@ package model
 
 import (
 	"encoding/json"
-	"errors"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Claims — данные пользователя из JWT. OrgID и OrgRole не входят в токен:
+// их заполняет auth.OrgMiddleware для запросов в контексте организации.
+//
+// Cnf привязывает токен к сертификату устройства (RFC 8705): токен принимается
+// только в соединении, где предъявлен сертификат с тем же отпечатком.
 type Claims struct {
 	jwt.RegisteredClaims
 	UserID    int
 	UserLogin string
+	Cnf       *Confirmation `json:"cnf,omitempty"`
+	OrgID     int           `json:"-"`
+	OrgRole   OrgRole       `json:"-"`
 }
 
 type LogLevel struct {
@@ -18,22 +26,38 @@ type LogLevel struct {
 }
 
 type UserCredentials struct {
-	Username     string `json:"username" validate:"required"`
-	Password     string `json:"password" validate:"required"`
-	EncryptedKey string `json:"omitempty"`
+	Username            string `json:"username" validate:"required"`
+	Password            string `json:"password" validate:"required"`
+	EncryptedKey        string `json:"omitempty"`
+	PublicKey           string `json:"-"`
+	EncryptedPrivateKey string `json:"-"`
 }
 
-var ErrUserExists = errors.New("user already exists")
-var ErrIncorrectPassword = errors.New("incorrect password")
-
-// TODO# возращается ошибка 500 когда пользовате не найден
-// var ErrUserNotFound = errors.New("user not found")
+var ErrUserExists = NewError(CodeConflict, "user already exists")
+var ErrIncorrectPassword = NewError(CodeUnauthorized, "incorrect login or password")
+var ErrRecordNotFound = NewError(CodeNotFound, "record not found")
+var ErrInvalidRecordID = NewError(CodeValidation, "invalid record id")
+var ErrNothingToUpdate = NewError(CodeValidation, "no fields to update")
+var ErrReadOnly = NewError(CodeForbidden, "read-only access")
+var ErrNotOwner = NewError(CodeForbidden, "only the owner can manage access")
+var ErrNoWriteAccess = NewError(CodeForbidden, "no write access to collection")
+var ErrInsufficientRole = NewError(CodeForbidden, "insufficient organization role")
+var ErrShareWithOwner = NewError(CodeValidation, "record cannot be shared with its owner")
+var ErrOrgExists = NewError(CodeConflict, "organization already exists")
+var ErrNotOrgMember = NewError(CodeForbidden, "user is not a member of the organization")
+var ErrMemberExists = NewError(CodeConflict, "user is already a member of the organization")
+var ErrNoCollection = NewError(CodeValidation, "organization has no collections")
+var ErrCollectionExists = NewError(CodeConflict, "collection already exists")
+var ErrEphemeralWithoutExpiry = NewError(CodeValidation, "ephemeral record must have expires_at")
+var ErrAmbiguousSecretPath = NewError(CodeValidation, "secret path matches several records")
+var ErrUserNotFound = NewError(CodeNotFound, "user not found")
 
 type User struct {
 	ID           int
 	Login        string
 	PasswordHash string
 	EncryptedKey string
+	PublicKey    string
 }
 
 type RecordType string
@@ -43,34 +67,214 @@ const (
 	TypeText          RecordType = "text"
 	TypeBinary        RecordType = "binary"
 	TypeBankCard      RecordType = "bank_card"
+	TypeSSHKey        RecordType = "ssh_key"
+)
+
+// SharePermission определяет уровень доступа пользователя к записи.
+type SharePermission string
+
+const (
+	PermissionOwner SharePermission = "owner"
+	PermissionRead  SharePermission = "read"
+	PermissionWrite SharePermission = "write"
 )
 
+// Record — запись в хранилище. DataKey содержит ключ записи (или ключ её
+// коллекции), зашифрованный открытым ключом запрашивающего пользователя;
+// пустой DataKey означает, что данные зашифрованы user-key владельца.
+// ExpiresAt — необязательный срок действия секрета; эфемерная (Ephemeral)
+// запись удаляется сервером после наступления этого срока.
 type Record struct {
-	ID       int64      `json:"id"`
-	UserID   int        `json:"user_id"`
-	Type     RecordType `json:"type"`
-	Metadata string     `json:"metadata,omitempty"`
-	Data     []byte     `json:"data,omitempty"`
+	ID           int64           `json:"id"`
+	UserID       int             `json:"user_id"`
+	CollectionID int             `json:"collection_id,omitempty"`
+	OrgID        int             `json:"org_id,omitempty"`
+	Type         RecordType      `json:"type"`
+	Metadata     string          `json:"metadata,omitempty"`
+	Data         []byte          `json:"data,omitempty"`
+	DataKey      string          `json:"data_key,omitempty"`
+	Permission   SharePermission `json:"permission,omitempty"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	ExpiresAt    *time.Time      `json:"expires_at,omitempty"`
+	Ephemeral    bool            `json:"ephemeral,omitempty"`
 }
 
 type RecordResponse struct {
-	ID       int64           `json:"id"`
-	Type     RecordType      `json:"type"`
-	Metadata string          `json:"metadata,omitempty"`
-	Data     json.RawMessage `json:"data,omitempty"`
+	ID           int64           `json:"id"`
+	CollectionID int             `json:"collection_id,omitempty"`
+	OrgID        int             `json:"org_id,omitempty"`
+	Type         RecordType      `json:"type"`
+	Metadata     string          `json:"metadata,omitempty"`
+	Data         json.RawMessage `json:"data,omitempty"`
+	Permission   SharePermission `json:"permission,omitempty"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	ExpiresAt    *time.Time      `json:"expires_at,omitempty"`
+	Ephemeral    bool            `json:"ephemeral,omitempty"`
+}
+
+// LoginPassword — данные записи login_password. TOTP содержит URI
+// otpauth:// или секрет в base32, по которому клиент вычисляет одноразовые
+// коды. PasswordChangedAt — время последней смены пароля; его проставляет
+// сервер при создании записи и при изменении поля password.
+type LoginPassword struct {
+	Login             string     `json:"login,omitempty"`
+	Password          string     `json:"password,omitempty"`
+	TOTP              string     `json:"totp,omitempty"`
+	PasswordChangedAt *time.Time `json:"password_changed_at,omitempty"`
+}
+
+// BankCard — данные записи bank_card. Expiry — срок действия в формате
+// MM/YY, MM/YYYY или YYYY-MM.
+type BankCard struct {
+	Number string `json:"number,omitempty"`
+	Holder string `json:"holder,omitempty"`
+	Expiry string `json:"expiry,omitempty"`
+	CVV    string `json:"cvv,omitempty"`
+}
+
+// SSHKey — данные записи ssh_key. PrivateKey хранится в формате OpenSSH без
+// парольной фразы, PublicKey — в формате authorized_keys, Fingerprint —
+// отпечаток SHA256. Confirm требует подтверждать каждое использование ключа
+// в gophkeeper ssh-agent.
+type SSHKey struct {
+	PrivateKey  string `json:"private_key"`
+	PublicKey   string `json:"public_key,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Confirm     bool   `json:"confirm,omitempty"`
+}
+
+// RecordKey — ключ записи, выданный конкретному пользователю.
+type RecordKey struct {
+	RecordID   int64
+	UserID     int
+	Login      string
+	PublicKey  string
+	Permission SharePermission
+	WrappedKey string
+}
+
+// ShareInput — запрос на предоставление доступа к записи.
+type ShareInput struct {
+	Username   string          `json:"username" validate:"required"`
+	Permission SharePermission `json:"permission" validate:"required,oneof=read write"`
+}
+
+// Share описывает пользователя, которому открыт доступ к записи.
+type Share struct {
+	Username   string          `json:"username"`
+	Permission SharePermission `json:"permission"`
 }
 
 type RecordInput struct {
-	Type     RecordType      `json:"type"`
-	Metadata string          `json:"metadata,omitempty"`
-	Data     json.RawMessage `json:"data"`
+	CollectionID int             `json:"collection_id,omitempty"`
+	Type         RecordType      `json:"type"`
+	Metadata     string          `json:"metadata,omitempty"`
+	Data         json.RawMessage `json:"data"`
+	ExpiresAt    *time.Time      `json:"expires_at,omitempty"`
+	Ephemeral    bool            `json:"ephemeral,omitempty"`
 }
 
+// RecordUpdateInput — изменяемые поля записи; nil-поля не меняются.
+// ClearExpiry снимает срок действия (и признак эфемерности) записи.
 type RecordUpdateInput struct {
-	Metadata *string          `json:"metadata,omitempty"`
-	Data     *json.RawMessage `json:"data,omitempty"`
+	Metadata    *string          `json:"metadata,omitempty"`
+	Data        *json.RawMessage `json:"data,omitempty"`
+	ExpiresAt   *time.Time       `json:"expires_at,omitempty"`
+	Ephemeral   *bool            `json:"ephemeral,omitempty"`
+	ClearExpiry bool             `json:"clear_expiry,omitempty"`
+}
+
+// Empty сообщает, что запрос не изменяет ни одного поля.
+func (in RecordUpdateInput) Empty() bool {
+	return in.Metadata == nil && in.Data == nil && !in.ChangesExpiry()
+}
+
+// RecordExpiry — новый срок действия записи и признак эфемерности;
+// nil ExpiresAt снимает срок.
+type RecordExpiry struct {
+	ExpiresAt *time.Time
+	Ephemeral bool
+}
+
+// ChangesExpiry сообщает, изменяет ли запрос срок действия записи.
+func (in RecordUpdateInput) ChangesExpiry() bool {
+	return in.ExpiresAt != nil || in.Ephemeral != nil || in.ClearExpiry
+}
+
+// RecordFilter — условия выборки списка записей. ExpiringWithin > 0
+// оставляет записи со сроком действия, истекающим не позже чем через
+// ExpiringWithin, включая уже истёкшие.
+type RecordFilter struct {
+	ExpiringWithin time.Duration
 }
 
 type UserKeyRespone struct {
-	UserKey string `json:"userkey"`
+	UserKey    string `json:"userkey"`
+	PrivateKey string `json:"private_key,omitempty"`
+}
+
+// OrgRole — роль участника организации.
+type OrgRole string
+
+const (
+	RoleOwner    OrgRole = "owner"
+	RoleAdmin    OrgRole = "admin"
+	RoleMember   OrgRole = "member"
+	RoleReadOnly OrgRole = "read-only"
+)
+
+// CanManage сообщает, может ли роль приглашать участников и создавать коллекции.
+func (r OrgRole) CanManage() bool {
+	return r == RoleOwner || r == RoleAdmin
+}
+
+// CanWrite сообщает, может ли роль создавать и изменять записи коллекций.
+func (r OrgRole) CanWrite() bool {
+	return r == RoleOwner || r == RoleAdmin || r == RoleMember
+}
+
+type Organization struct {
+	ID   int     `json:"id"`
+	Name string  `json:"name"`
+	Role OrgRole `json:"role,omitempty"`
+}
+
+type OrgInput struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type InviteInput struct {
+	Username string  `json:"username" validate:"required"`
+	Role     OrgRole `json:"role" validate:"required,oneof=owner admin member read-only"`
+}
+
+type OrgMember struct {
+	UserID    int     `json:"-"`
+	Username  string  `json:"username"`
+	Role      OrgRole `json:"role"`
+	PublicKey string  `json:"-"`
+}
+
+// Collection — общее хранилище организации. Записи коллекции шифруются
+// ключом коллекции, который выдаётся каждому участнику организации.
+type Collection struct {
+	ID         int    `json:"id"`
+	OrgID      int    `json:"org_id"`
+	Name       string `json:"name"`
+	WrappedKey string `json:"-"`
+}
+
+type CollectionInput struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// CollectionKey — ключ коллекции, зашифрованный открытым ключом участника,
+// вместе с ролью участника в организации коллекции.
+type CollectionKey struct {
+	CollectionID int
+	OrgID        int
+	UserID       int
+	Role         OrgRole
+	WrappedKey   string
 }
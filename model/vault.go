@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+)
+
+var ErrVaultTokenNotFound = NewError(CodeNotFound, "vault token not found")
+
+// VaultToken — токен клиента API Vault KV v2. Токен даёт только чтение
+// секретов пользователя через /v1 и отзывается независимо от сессии.
+// Сервер хранит только хеш токена, сам токен выдаётся один раз при создании.
+type VaultToken struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"-"`
+	UserLogin string    `json:"-"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// VaultTokenInput — запрос на выпуск токена Vault.
+type VaultTokenInput struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// VaultTokenResponse — выпущенный токен Vault. Token возвращается только здесь.
+type VaultTokenResponse struct {
+	VaultToken
+	Token string `json:"token"`
+}
@@ -0,0 +1,152 @@
+// Пакет breach проверяет пароли по офлайн-базе утёкших паролей в формате
+// Have I Been Pwned (SHA-1). Пароли в базу не передаются и не хранятся в
+// открытом виде: сравниваются только хеши.
+//
+// Поддерживаются два формата:
+//   - каталог файлов диапазонов k-anonymity: файл с именем из первых пяти
+//     символов хеша (например, 5BAA6 или 5BAA6.txt) содержит строки
+//     SUFFIX:COUNT с оставшимися 35 символами хеша — так их выдаёт
+//     https://api.pwnedpasswords.com/range/{prefix};
+//   - один файл со строками HASH:COUNT (полный хеш), например
+//     pwned-passwords-sha1-ordered-by-hash; файл читается последовательно.
+package breach
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// prefixLen — длина префикса хеша, по которому выбирается диапазон.
+const prefixLen = 5
+
+// Source — база утёкших паролей.
+type Source interface {
+	// Counts возвращает число утечек для каждого найденного хеша из hashes.
+	// Отсутствующие в базе хеши в результат не попадают.
+	Counts(hashes []string) (map[string]int, error)
+}
+
+// Hash возвращает SHA-1 пароля в верхнем регистре, как в базе HIBP.
+func Hash(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// Open открывает базу по пути к каталогу диапазонов или к файлу хешей.
+func Open(path string) (Source, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("open breach database: %w", err)
+	}
+	if info.IsDir() {
+		return rangeDir(path), nil
+	}
+	return hashFile(path), nil
+}
+
+// rangeDir — каталог файлов диапазонов k-anonymity.
+type rangeDir string
+
+func (d rangeDir) Counts(hashes []string) (map[string]int, error) {
+	byPrefix := make(map[string]map[string]string)
+	for _, hash := range hashes {
+		prefix, suffix := hash[:prefixLen], hash[prefixLen:]
+		if byPrefix[prefix] == nil {
+			byPrefix[prefix] = make(map[string]string)
+		}
+		byPrefix[prefix][suffix] = hash
+	}
+
+	counts := make(map[string]int)
+	for prefix, suffixes := range byPrefix {
+		f, err := d.open(prefix)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = scan(f, func(suffix string, count int) {
+			if hash, ok := suffixes[suffix]; ok {
+				counts[hash] = count
+			}
+		})
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read range %s: %w", prefix, err)
+		}
+	}
+	return counts, nil
+}
+
+func (d rangeDir) open(prefix string) (*os.File, error) {
+	for _, name := range []string{prefix, prefix + ".txt", strings.ToLower(prefix), strings.ToLower(prefix) + ".txt"} {
+		f, err := os.Open(filepath.Join(string(d), name))
+		if !errors.Is(err, os.ErrNotExist) {
+			return f, err
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+// hashFile — файл полных хешей.
+type hashFile string
+
+func (p hashFile) Counts(hashes []string) (map[string]int, error) {
+	wanted := make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		wanted[hash] = true
+	}
+
+	f, err := os.Open(string(p))
+	if err != nil {
+		return nil, fmt.Errorf("open breach database: %w", err)
+	}
+	defer f.Close()
+
+	counts := make(map[string]int)
+	err = scan(f, func(hash string, count int) {
+		if wanted[hash] {
+			counts[hash] = count
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", p, err)
+	}
+	return counts, nil
+}
+
+// scan читает строки вида HASH:COUNT. Хеш приводится к верхнему регистру;
+// строки без счётчика считаются одной утечкой, с нулевым — пропускаются.
+func scan(r io.Reader, fn func(hash string, count int)) error {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		hash, countText, found := strings.Cut(line, ":")
+		count := 1
+		if found {
+			n, err := strconv.Atoi(strings.TrimSpace(countText))
+			if err != nil {
+				return fmt.Errorf("invalid line %q", line)
+			}
+			count = n
+		}
+		if count == 0 {
+			// Ответы API с заголовком Add-Padding содержат фиктивные строки с нулём.
+			continue
+		}
+		fn(strings.ToUpper(hash), count)
+	}
+	return s.Err()
+}
@@ -0,0 +1,74 @@
+package cryptoutil
+
+import (
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+)
+
+// sealInfo binds derived wrapping keys to their purpose.
+const sealInfo = "gophkeeper-seal-v1"
+
+// GenerateKeyPair returns a new X25519 key pair as raw bytes (public, private).
+func GenerateKeyPair() ([]byte, []byte, error) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate x25519 key: %w", err)
+	}
+	return priv.PublicKey().Bytes(), priv.Bytes(), nil
+}
+
+// Seal encrypts data for the owner of publicKey.
+// An ephemeral X25519 key is agreed with the recipient key, the shared secret
+// is expanded with HKDF-SHA256 and used for AES-GCM.
+// Output layout: ephemeral public key (32 bytes) + nonce + ciphertext.
+func Seal(data, publicKey []byte) ([]byte, error) {
+	recipient, err := ecdh.X25519().NewPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate ephemeral key: %w", err)
+	}
+	key, err := sealKey(ephemeral, recipient, ephemeral.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := Encrypt(data, key)
+	if err != nil {
+		return nil, err
+	}
+	return append(ephemeral.PublicKey().Bytes(), ciphertext...), nil
+}
+
+// Open decrypts data produced by Seal with the matching private key.
+func Open(sealed, privateKey []byte) ([]byte, error) {
+	priv, err := ecdh.X25519().NewPrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	size := len(priv.PublicKey().Bytes())
+	if len(sealed) < size {
+		return nil, fmt.Errorf("sealed data too short")
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(sealed[:size])
+	if err != nil {
+		return nil, fmt.Errorf("parse ephemeral key: %w", err)
+	}
+	key, err := sealKey(priv, ephemeral, sealed[:size])
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(sealed[size:], key)
+}
+
+func sealKey(priv *ecdh.PrivateKey, peer *ecdh.PublicKey, ephemeral []byte) ([]byte, error) {
+	shared, err := priv.ECDH(peer)
+	if err != nil {
+		return nil, fmt.Errorf("x25519 agreement: %w", err)
+	}
+	return hkdf.Key(sha256.New, shared, ephemeral, sealInfo, 32)
+}
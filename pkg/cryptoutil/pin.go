@@ -0,0 +1,22 @@
+package cryptoutil
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+)
+
+// SPKIPin returns the base64-encoded SHA-256 digest of the certificate's
+// SubjectPublicKeyInfo. The pin survives certificate renewal as long as
+// the key pair is kept.
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// CertThumbprint returns the base64url-encoded (unpadded) SHA-256 digest of the
+// DER-encoded certificate, as used by the "x5t#S256" confirmation claim (RFC 8705).
+func CertThumbprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
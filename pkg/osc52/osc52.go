@@ -0,0 +1,73 @@
+// Пакет osc52 помещает текст в буфер обмена терминала escape-последовательностью
+// OSC 52. Последовательность обрабатывает сам эмулятор терминала, поэтому
+// копирование работает и через SSH.
+package osc52
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Multiplexer — терминальный мультиплексор, через который нужно передать
+// последовательность.
+type Multiplexer int
+
+const (
+	None Multiplexer = iota
+	Tmux
+	Screen
+)
+
+// DetectMultiplexer определяет мультиплексор по переменным окружения.
+func DetectMultiplexer() Multiplexer {
+	switch {
+	case os.Getenv("TMUX") != "":
+		return Tmux
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		return Screen
+	default:
+		return None
+	}
+}
+
+// Sequence возвращает последовательность, записывающую text в буфер обмена.
+func Sequence(text string, mux Multiplexer) string {
+	return wrap("\x1b]52;c;"+base64.StdEncoding.EncodeToString([]byte(text))+"\a", mux)
+}
+
+// ClearSequence возвращает последовательность очистки буфера обмена: по
+// спецификации xterm данные, не являющиеся base64, очищают выделение.
+func ClearSequence(mux Multiplexer) string {
+	return wrap("\x1b]52;c;!\a", mux)
+}
+
+// wrap передаёт последовательность сквозь мультиплексор (DCS passthrough).
+func wrap(seq string, mux Multiplexer) string {
+	switch mux {
+	case Tmux:
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case Screen:
+		return "\x1bP" + seq + "\x1b\\"
+	default:
+		return seq
+	}
+}
+
+// Copy записывает text в буфер обмена терминала w.
+func Copy(w io.Writer, text string, mux Multiplexer) error {
+	if _, err := io.WriteString(w, Sequence(text, mux)); err != nil {
+		return fmt.Errorf("write OSC 52 sequence: %w", err)
+	}
+	return nil
+}
+
+// Clear очищает буфер обмена терминала w.
+func Clear(w io.Writer, mux Multiplexer) error {
+	if _, err := io.WriteString(w, ClearSequence(mux)); err != nil {
+		return fmt.Errorf("write OSC 52 sequence: %w", err)
+	}
+	return nil
+}
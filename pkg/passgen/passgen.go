@@ -0,0 +1,196 @@
+// Пакет passgen генерирует пароли и парольные фразы diceware.
+//
+// Случайность берётся только из crypto/rand. Случайный индекс выбирается
+// с отбраковкой (rejection sampling): значения из неполного последнего
+// интервала отбрасываются, поэтому остаток от деления не смещает
+// распределение символов и слов.
+package passgen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Классы символов пароля.
+const (
+	Lower   = "abcdefghijklmnopqrstuvwxyz"
+	Upper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	Digits  = "0123456789"
+	Symbols = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+	// Ambiguous — символы, которые легко спутать при чтении и вводе вручную.
+	Ambiguous = "0O1lI|`'\""
+)
+
+var ErrInvalidLength = errors.New("password length is too short")
+var ErrNoCharacters = errors.New("no characters left to generate password")
+var ErrInvalidWords = errors.New("passphrase must contain at least one word")
+
+// Options — правила генерации пароля. Символ каждого включённого класса
+// встречается в пароле хотя бы один раз.
+type Options struct {
+	Length  int
+	Lower   bool
+	Upper   bool
+	Digits  bool
+	Symbols bool
+	// Exclude — символы, которые не должны встречаться в пароле.
+	Exclude string
+	// ExcludeAmbiguous исключает символы Ambiguous.
+	ExcludeAmbiguous bool
+}
+
+// DefaultOptions — пароль из 20 символов всех классов.
+var DefaultOptions = Options{Length: 20, Lower: true, Upper: true, Digits: true, Symbols: true}
+
+// classes возвращает алфавиты включённых классов после применения исключений.
+// Классы, от которых после исключений ничего не осталось, пропускаются.
+func (o Options) classes() []string {
+	exclude := o.Exclude
+	if o.ExcludeAmbiguous {
+		exclude += Ambiguous
+	}
+	var classes []string
+	for _, class := range []struct {
+		enabled bool
+		chars   string
+	}{{o.Lower, Lower}, {o.Upper, Upper}, {o.Digits, Digits}, {o.Symbols, Symbols}} {
+		if !class.enabled {
+			continue
+		}
+		chars := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(exclude, r) {
+				return -1
+			}
+			return r
+		}, class.chars)
+		if chars != "" {
+			classes = append(classes, chars)
+		}
+	}
+	return classes
+}
+
+// Entropy возвращает оценку энтропии пароля в битах.
+func (o Options) Entropy() float64 {
+	return float64(o.Length) * math.Log2(float64(len(strings.Join(o.classes(), ""))))
+}
+
+// Password генерирует пароль по правилам opts. Пароли, в которых нет символа
+// какого-либо включённого класса, отбрасываются и генерируются заново,
+// поэтому все допустимые пароли равновероятны.
+func Password(opts Options) (string, error) {
+	classes := opts.classes()
+	if len(classes) == 0 {
+		return "", ErrNoCharacters
+	}
+	if opts.Length < len(classes) {
+		return "", fmt.Errorf("%w: %d characters cannot contain all %d character classes", ErrInvalidLength, opts.Length, len(classes))
+	}
+	alphabet := strings.Join(classes, "")
+
+	password := make([]byte, opts.Length)
+	for {
+		for i := range password {
+			n, err := randIndex(len(alphabet))
+			if err != nil {
+				return "", err
+			}
+			password[i] = alphabet[n]
+		}
+		if containsAll(string(password), classes) {
+			return string(password), nil
+		}
+	}
+}
+
+func containsAll(password string, classes []string) bool {
+	for _, class := range classes {
+		if !strings.ContainsAny(password, class) {
+			return false
+		}
+	}
+	return true
+}
+
+// PassphraseOptions — правила генерации парольной фразы.
+type PassphraseOptions struct {
+	Words     int
+	Separator string
+	// Capitalize делает первую букву каждого слова заглавной.
+	Capitalize bool
+	// Number добавляет случайную цифру в конец случайного слова —
+	// для сайтов, требующих цифру в пароле.
+	Number bool
+}
+
+// DefaultPassphraseOptions — шесть слов через дефис (около 77 бит энтропии).
+var DefaultPassphraseOptions = PassphraseOptions{Words: 6, Separator: "-"}
+
+// Entropy возвращает оценку энтропии парольной фразы в битах.
+func (o PassphraseOptions) Entropy() float64 {
+	bits := float64(o.Words) * math.Log2(float64(len(wordlist())))
+	if o.Number && o.Words > 0 {
+		bits += math.Log2(float64(len(Digits) * o.Words))
+	}
+	return bits
+}
+
+// Passphrase генерирует парольную фразу из слов встроенного списка diceware.
+func Passphrase(opts PassphraseOptions) (string, error) {
+	if opts.Words < 1 {
+		return "", ErrInvalidWords
+	}
+	list := wordlist()
+
+	words := make([]string, opts.Words)
+	for i := range words {
+		n, err := randIndex(len(list))
+		if err != nil {
+			return "", err
+		}
+		words[i] = list[n]
+		if opts.Capitalize {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+
+	if opts.Number {
+		word, err := randIndex(len(words))
+		if err != nil {
+			return "", err
+		}
+		digit, err := randIndex(len(Digits))
+		if err != nil {
+			return "", err
+		}
+		words[word] += Digits[digit : digit+1]
+	}
+
+	return strings.Join(words, opts.Separator), nil
+}
+
+// randIndex возвращает равномерно распределённое число из [0, n).
+// Из 2^32 возможных значений принимаются только первые n*⌊2^32/n⌋,
+// остальные отбрасываются и запрашиваются заново.
+func randIndex(n int) (int, error) {
+	if n <= 0 || uint64(n) > math.MaxUint32 {
+		return 0, fmt.Errorf("invalid range: %d", n)
+	}
+	bound := uint64(n)
+	limit := (1 << 32) - (1<<32)%bound
+
+	var buf [4]byte
+	for {
+		if _, err := rand.Read(buf[:]); err != nil {
+			return 0, fmt.Errorf("read random: %w", err)
+		}
+		v := uint64(binary.BigEndian.Uint32(buf[:]))
+		if v < limit {
+			return int(v % bound), nil
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package passgen
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// keyboardRows — ряды клавиатуры для обнаружения последовательностей вроде qwerty.
+var keyboardRows = []string{"`1234567890-=", "qwertyuiop[]\\", "asdfghjkl;'", "zxcvbnm,./"}
+
+// Estimate грубо оценивает энтропию произвольного пароля в битах: длину
+// пароля, умноженную на log2 размера алфавита задействованных классов
+// символов. Повторы, алфавитные и клавиатурные последовательности (aaa, abc,
+// 321, qwerty) добавляют по одному биту на символ, а слово из списка
+// diceware целиком считается одним словом списка.
+func Estimate(password string) float64 {
+	if password == "" {
+		return 0
+	}
+	if isWord(password) {
+		return math.Log2(float64(len(wordlist())))
+	}
+
+	var pool int
+	var hasLower, hasUpper, hasDigit, hasSymbol, hasOther bool
+	for _, r := range password {
+		switch {
+		case strings.ContainsRune(Lower, r):
+			hasLower = true
+		case strings.ContainsRune(Upper, r):
+			hasUpper = true
+		case strings.ContainsRune(Digits, r):
+			hasDigit = true
+		case strings.ContainsRune(Symbols, r):
+			hasSymbol = true
+		default:
+			hasOther = true
+		}
+	}
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{hasLower, len(Lower)}, {hasUpper, len(Upper)}, {hasDigit, len(Digits)}, {hasSymbol, len(Symbols)}, {hasOther, 100}} {
+		if class.present {
+			pool += class.size
+		}
+	}
+	bitsPerChar := math.Log2(float64(pool))
+
+	var bits float64
+	runes := []rune(password)
+	for i, r := range runes {
+		if i > 0 && continues(runes[i-1], r) {
+			bits++
+			continue
+		}
+		bits += bitsPerChar
+	}
+	return bits
+}
+
+// continues сообщает, продолжает ли символ cur повтор или последовательность,
+// начатую символом prev.
+func continues(prev, cur rune) bool {
+	prev, cur = unicode.ToLower(prev), unicode.ToLower(cur)
+	if d := cur - prev; d >= -1 && d <= 1 {
+		return true
+	}
+	for _, row := range keyboardRows {
+		i := strings.IndexRune(row, prev)
+		if i < 0 {
+			continue
+		}
+		if j := strings.IndexRune(row, cur); j >= 0 && (j == i+1 || j == i-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWord сообщает, является ли пароль словом из списка diceware.
+func isWord(password string) bool {
+	password = strings.ToLower(password)
+	for _, word := range wordlist() {
+		if word == password {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package passgen
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+// effLargeWordlist — большой список слов EFF для diceware (7776 слов, 5 бросков кубика).
+// https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases, лицензия CC BY 3.0 US.
+//
+//go:embed eff_large_wordlist.txt
+var effLargeWordlist string
+
+// wordlist возвращает слова встроенного списка в порядке бросков кубика.
+var wordlist = sync.OnceValue(func() []string {
+	lines := strings.Split(strings.TrimSpace(effLargeWordlist), "\n")
+	words := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if _, word, ok := strings.Cut(line, "\t"); ok {
+			words = append(words, word)
+		}
+	}
+	return words
+})
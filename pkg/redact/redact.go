@@ -0,0 +1,125 @@
+// Пакет redact скрывает секретные значения в потоке вывода. Значение может
+// прийти по частям в нескольких вызовах Write, поэтому незавершённое
+// совпадение придерживается до следующей записи или Flush. Перекрывающиеся
+// значения скрываются одной маской.
+package redact
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Mask заменяет найденные значения.
+const Mask = "*****"
+
+// MinLength — минимальная длина скрываемого значения: более короткие
+// значения встречаются в обычном выводе и испортили бы его.
+const MinLength = 4
+
+// Writer пишет в w данные, в которых секретные значения заменены на Mask.
+type Writer struct {
+	mu      sync.Mutex
+	w       io.Writer
+	secrets [][]byte
+	pending []byte
+}
+
+// NewWriter возвращает Writer, скрывающий values. Значения короче MinLength
+// не скрываются.
+func NewWriter(w io.Writer, values []string) *Writer {
+	secrets := make([][]byte, 0, len(values))
+	for _, v := range values {
+		if len(v) >= MinLength {
+			secrets = append(secrets, []byte(v))
+		}
+	}
+	// более длинное значение важнее своего префикса
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	return &Writer{w: w, secrets: secrets}
+}
+
+// Write скрывает значения в p и пишет результат. Возвращает len(p), если
+// запись прошла успешно, даже если часть данных придержана.
+func (r *Writer) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pending = append(r.pending, p...)
+	out, rest := r.redact(r.pending, false)
+	r.pending = append(r.pending[:0], rest...)
+	if len(out) > 0 {
+		if _, err := r.w.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush пишет придержанные данные. Вызывается, когда поток закончился.
+func (r *Writer) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out, _ := r.redact(r.pending, true)
+	r.pending = r.pending[:0]
+	if len(out) == 0 {
+		return nil
+	}
+	_, err := r.w.Write(out)
+	return err
+}
+
+// redact возвращает данные для вывода и остаток, который может оказаться
+// началом секретного значения. При final остаток не придерживается.
+func (r *Writer) redact(data []byte, final bool) (out, rest []byte) {
+	out = make([]byte, 0, len(data))
+	for i := 0; i < len(data); {
+		// остаток может оказаться началом более длинного значения,
+		// даже если короткое значение уже совпало
+		if !final && r.partial(data[i:]) {
+			return out, data[i:]
+		}
+		n := r.match(data[i:])
+		if n == 0 {
+			out = append(out, data[i])
+			i++
+			continue
+		}
+		// значения, которые начинаются внутри найденного и выходят за
+		// его конец, скрываются вместе с ним
+		end := i + n
+		for j := i + 1; j < end; j++ {
+			if !final && r.partial(data[j:]) {
+				return out, data[i:]
+			}
+			if m := r.match(data[j:]); j+m > end {
+				end = j + m
+			}
+		}
+		out = append(out, Mask...)
+		i = end
+	}
+	return out, nil
+}
+
+// match возвращает длину значения, с которого начинается data, или 0.
+func (r *Writer) match(data []byte) int {
+	for _, s := range r.secrets {
+		if bytes.HasPrefix(data, s) {
+			return len(s)
+		}
+	}
+	return 0
+}
+
+// partial сообщает, что data целиком является началом какого-либо значения.
+func (r *Writer) partial(data []byte) bool {
+	for _, s := range r.secrets {
+		if len(data) < len(s) && bytes.HasPrefix(s, data) {
+			return true
+		}
+	}
+	return false
+}
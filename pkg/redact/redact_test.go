@@ -0,0 +1,85 @@
+package redact_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/fatkulllin/gophkeeper/pkg/redact"
+)
+
+func TestWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		secrets []string
+		writes  []string
+		// held — вывод до Flush: незавершённое совпадение придерживается
+		held string
+		want string
+	}{
+		{name: "no secrets", writes: []string{"plain ", "text"}, held: "plain text", want: "plain text"},
+		{name: "whole secret", secrets: []string{"hunter2"}, writes: []string{"pw=hunter2;"}, held: "pw=*****;", want: "pw=*****;"},
+		{name: "every occurrence", secrets: []string{"hunter2"}, writes: []string{"hunter2 hunter2"}, held: "***** *****", want: "***** *****"},
+		{name: "adjacent occurrences", secrets: []string{"abcd"}, writes: []string{"abcdabcd"}, held: "**********", want: "**********"},
+		{name: "short value is kept", secrets: []string{"abc", ""}, writes: []string{"abc"}, held: "abc", want: "abc"},
+		{name: "split across two writes", secrets: []string{"password123"}, writes: []string{"x=pass", "word123\n"}, held: "x=*****\n", want: "x=*****\n"},
+		{name: "split byte by byte", secrets: []string{"s3cr3t"}, writes: []string{"<", "s", "3", "c", "r", "3", "t", ">"}, held: "<*****>", want: "<*****>"},
+		{name: "split across three writes", secrets: []string{"password123"}, writes: []string{"pa", "sswo", "rd123"}, held: "*****", want: "*****"},
+		{name: "false start is released", secrets: []string{"secret"}, writes: []string{"sec", "tor"}, held: "sector", want: "sector"},
+		{name: "false start followed by secret", secrets: []string{"secret"}, writes: []string{"sesec", "ret"}, held: "se*****", want: "se*****"},
+		{name: "longer value wins", secrets: []string{"abcd", "abcdef"}, writes: []string{"abcdef!"}, held: "*****!", want: "*****!"},
+		{name: "prefix split before the longer value", secrets: []string{"abcd", "abcdef"}, writes: []string{"abcd", "ef!"}, held: "*****!", want: "*****!"},
+		{name: "prefix alone", secrets: []string{"abcd", "abcdef"}, writes: []string{"abcd", "xy"}, held: "*****xy", want: "*****xy"},
+		{name: "prefix at end of stream", secrets: []string{"abcd", "abcdef"}, writes: []string{"abcd"}, held: "", want: "*****"},
+		{name: "overlapping values", secrets: []string{"abcd", "cdef"}, writes: []string{"xabcdefx"}, held: "x*****x", want: "x*****x"},
+		{name: "overlapping values split", secrets: []string{"abcd", "cdef"}, writes: []string{"xabcde", "fx"}, held: "x*****x", want: "x*****x"},
+		{name: "overlap not completed", secrets: []string{"abcd", "cdef"}, writes: []string{"xabcde", "x"}, held: "x*****ex", want: "x*****ex"},
+		{name: "flush partial at end of stream", secrets: []string{"secret"}, writes: []string{"token: sec"}, held: "token: ", want: "token: sec"},
+		{name: "flush partial prefix of longer value", secrets: []string{"abcd", "abcdef"}, writes: []string{"abcde"}, held: "", want: "*****e"},
+		{name: "flush nothing", secrets: []string{"secret"}, writes: nil, held: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := redact.NewWriter(&buf, tt.secrets)
+			for _, p := range tt.writes {
+				n, err := w.Write([]byte(p))
+				if err != nil {
+					t.Fatalf("Write(%q): %v", p, err)
+				}
+				if n != len(p) {
+					t.Fatalf("Write(%q) = %d, want %d", p, n, len(p))
+				}
+			}
+			if got := buf.String(); got != tt.held {
+				t.Errorf("before Flush = %q, want %q", got, tt.held)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Flush: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("after Flush = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// failingWriter отклоняет любую запись.
+type failingWriter struct{}
+
+var errWrite = errors.New("write failed")
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errWrite }
+
+func TestWriterErrors(t *testing.T) {
+	w := redact.NewWriter(failingWriter{}, []string{"secret"})
+	if _, err := w.Write([]byte("sec")); err != nil {
+		t.Fatalf("Write of a held back partial value: %v", err)
+	}
+	if err := w.Flush(); !errors.Is(err, errWrite) {
+		t.Errorf("Flush error = %v, want %v", err, errWrite)
+	}
+	if _, err := w.Write([]byte("output")); !errors.Is(err, errWrite) {
+		t.Errorf("Write error = %v, want %v", err, errWrite)
+	}
+}
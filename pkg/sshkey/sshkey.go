@@ -0,0 +1,101 @@
+// Пакет sshkey создаёт и разбирает закрытые ключи SSH в формате OpenSSH.
+package sshkey
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Алгоритмы генерируемых ключей.
+const (
+	Ed25519 = "ed25519"
+	RSA     = "rsa"
+)
+
+// DefaultRSABits — длина ключа RSA по умолчанию.
+const DefaultRSABits = 4096
+
+// minRSABits — минимальная длина ключа RSA, которую принимает OpenSSH.
+const minRSABits = 2048
+
+// ErrPassphraseRequired возвращается для ключа, защищённого парольной фразой,
+// если она не передана.
+var ErrPassphraseRequired = errors.New("private key is protected by a passphrase")
+
+// Generate создаёт ключ алгоритма alg (для RSA длиной bits) и возвращает
+// закрытый ключ в формате OpenSSH без шифрования.
+func Generate(alg string, bits int, comment string) ([]byte, error) {
+	var key any
+	switch alg {
+	case Ed25519:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		key = private
+	case RSA:
+		if bits == 0 {
+			bits = DefaultRSABits
+		}
+		if bits < minRSABits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSABits)
+		}
+		private, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return nil, err
+		}
+		key = private
+	default:
+		return nil, fmt.Errorf("unknown key type %q: use %s or %s", alg, Ed25519, RSA)
+	}
+	return marshal(key, comment)
+}
+
+// Decrypt возвращает закрытый ключ в формате OpenSSH без шифрования.
+// Ключ, защищённый парольной фразой, расшифровывается passphrase; без неё
+// возвращается ErrPassphraseRequired. Принимаются также ключи PEM (PKCS#1,
+// PKCS#8).
+func Decrypt(privateKey, passphrase []byte, comment string) ([]byte, error) {
+	key, err := ssh.ParseRawPrivateKey(privateKey)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if len(passphrase) == 0 {
+			return nil, ErrPassphraseRequired
+		}
+		key, err = ssh.ParseRawPrivateKeyWithPassphrase(privateKey, passphrase)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	return marshal(key, comment)
+}
+
+// Describe возвращает открытый ключ в формате authorized_keys (с
+// комментарием) и отпечаток SHA256 закрытого ключа privateKey.
+func Describe(privateKey []byte, comment string) (publicKey, fingerprint string, err error) {
+	signer, err := ssh.ParsePrivateKey(privateKey)
+	if err != nil {
+		return "", "", fmt.Errorf("parse private key: %w", err)
+	}
+	public := signer.PublicKey()
+	publicKey = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(public)))
+	if comment != "" {
+		publicKey += " " + comment
+	}
+	return publicKey, ssh.FingerprintSHA256(public), nil
+}
+
+func marshal(key any, comment string) ([]byte, error) {
+	block, err := ssh.MarshalPrivateKey(key, comment)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(block), nil
+}
@@ -0,0 +1,171 @@
+// Пакет totp вычисляет одноразовые коды TOTP (RFC 6238) по секрету,
+// заданному URI otpauth:// или строкой base32.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Algorithm — хеш-функция HMAC.
+type Algorithm string
+
+const (
+	SHA1   Algorithm = "SHA1"
+	SHA256 Algorithm = "SHA256"
+	SHA512 Algorithm = "SHA512"
+)
+
+// Значения по умолчанию из спецификации Key Uri Format.
+const (
+	DefaultDigits = 6
+	DefaultPeriod = 30 * time.Second
+)
+
+var ErrInvalidSecret = errors.New("invalid TOTP secret")
+var ErrInvalidURI = errors.New("invalid otpauth URI")
+
+// Key — параметры генерации кодов.
+type Key struct {
+	Secret    []byte
+	Algorithm Algorithm
+	Digits    int
+	Period    time.Duration
+	Issuer    string
+	Account   string
+}
+
+// Parse разбирает URI otpauth://totp/... или секрет в base32.
+// В base32 допускаются строчные буквы, пробелы, дефисы и отсутствие
+// выравнивания '='.
+func Parse(s string) (Key, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(strings.ToLower(s), "otpauth:") {
+		return parseURI(s)
+	}
+	secret, err := decodeSecret(s)
+	if err != nil {
+		return Key{}, err
+	}
+	return Key{Secret: secret, Algorithm: SHA1, Digits: DefaultDigits, Period: DefaultPeriod}, nil
+}
+
+func parseURI(s string) (Key, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return Key{}, fmt.Errorf("%w: %v", ErrInvalidURI, err)
+	}
+	if !strings.EqualFold(u.Host, "totp") {
+		return Key{}, fmt.Errorf("%w: unsupported type %q, only totp is supported", ErrInvalidURI, u.Host)
+	}
+
+	q := u.Query()
+	secret, err := decodeSecret(q.Get("secret"))
+	if err != nil {
+		return Key{}, err
+	}
+	key := Key{Secret: secret, Algorithm: SHA1, Digits: DefaultDigits, Period: DefaultPeriod}
+
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		key.Issuer, key.Account = issuer, strings.TrimSpace(account)
+	} else {
+		key.Account = label
+	}
+	if issuer := q.Get("issuer"); issuer != "" {
+		key.Issuer = issuer
+	}
+
+	if v := q.Get("algorithm"); v != "" {
+		key.Algorithm = Algorithm(strings.ToUpper(v))
+		if _, err := key.Algorithm.hash(); err != nil {
+			return Key{}, err
+		}
+	}
+	if v := q.Get("digits"); v != "" {
+		key.Digits, err = strconv.Atoi(v)
+		if err != nil || key.Digits < 6 || key.Digits > 10 {
+			return Key{}, fmt.Errorf("%w: digits must be between 6 and 10, got %q", ErrInvalidURI, v)
+		}
+	}
+	if v := q.Get("period"); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds <= 0 {
+			return Key{}, fmt.Errorf("%w: period must be a positive number of seconds, got %q", ErrInvalidURI, v)
+		}
+		key.Period = time.Duration(seconds) * time.Second
+	}
+	return key, nil
+}
+
+func decodeSecret(s string) ([]byte, error) {
+	s = strings.ToUpper(strings.NewReplacer(" ", "", "-", "", "=", "").Replace(s))
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty secret", ErrInvalidSecret)
+	}
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSecret, err)
+	}
+	return secret, nil
+}
+
+func (a Algorithm) hash() (func() hash.Hash, error) {
+	switch a {
+	case SHA1:
+		return sha1.New, nil
+	case SHA256:
+		return sha256.New, nil
+	case SHA512:
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidURI, a)
+	}
+}
+
+// Code возвращает код, действующий в момент t, и время до его смены.
+func (k Key) Code(t time.Time) (string, time.Duration, error) {
+	newHash, err := k.Algorithm.hash()
+	if err != nil {
+		return "", 0, err
+	}
+	period := int64(k.Period / time.Second)
+	if period <= 0 || k.Digits <= 0 {
+		return "", 0, fmt.Errorf("%w: period and digits must be positive", ErrInvalidURI)
+	}
+
+	unix := t.Unix()
+	counter := uint64(unix / period)
+	remaining := time.Duration(period-unix%period) * time.Second
+
+	return hotp(newHash, k.Secret, counter, k.Digits), remaining, nil
+}
+
+// hotp вычисляет код HOTP (RFC 4226) для значения счётчика.
+func hotp(newHash func() hash.Hash, secret []byte, counter uint64, digits int) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(newHash, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := uint64(binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff)
+
+	mod := uint64(1)
+	for range digits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}